@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sessions are stored in MySQL so logins survive server restarts.
+// Only a SHA-256 hash of each token is persisted.
+const (
+	sessionDuration      = 24 * time.Hour
+	sessionSweepInterval = time.Hour
+)
+
+// generateToken creates a random token
+func generateToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash stored in place of the token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(authHeader string) (string, bool) {
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// createSession generates a new token for a user and stores its hash
+func createSession(userID int32) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = queries.CreateSession(context.Background(), db.CreateSessionParams{
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(sessionDuration),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// validateToken looks up the unexpired session for a token belonging to an enabled user
+func validateToken(token string) (db.GetActiveSessionRow, bool) {
+	session, err := queries.GetActiveSession(context.Background(), db.GetActiveSessionParams{
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now(),
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("Failed to validate session:", err)
+		}
+		return db.GetActiveSessionRow{}, false
+	}
+	return session, true
+}
+
+// revokeToken deletes the session for a token
+func revokeToken(token string) error {
+	return queries.DeleteSession(context.Background(), hashToken(token))
+}
+
+// startSessionSweeper purges expired sessions in the background until
+// the returned stop is called
+func startSessionSweeper(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			purged, err := queries.DeleteExpiredSessions(context.Background(), time.Now())
+			if err != nil {
+				log.Println("Failed to purge expired sessions:", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Purged %d expired sessions", purged)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// currentSession returns the session stored by authMiddleware
+func currentSession(c *gin.Context) db.GetActiveSessionRow {
+	session, _ := c.Get("session")
+	s, _ := session.(db.GetActiveSessionRow)
+	return s
+}
+
+// authMiddleware checks for valid authentication
+func authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(401, gin.H{"error": "Authorization header required"})
+			c.Abort()
+			return
+		}
+
+		// Expect "Bearer <token>"
+		token, ok := bearerToken(authHeader)
+		if !ok {
+			c.JSON(401, gin.H{"error": "Invalid authorization format"})
+			c.Abort()
+			return
+		}
+
+		session, ok := validateToken(token)
+		if !ok {
+			c.JSON(401, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		c.Set("session", session)
+		c.Next()
+	}
+}
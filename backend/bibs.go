@@ -0,0 +1,496 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BibAssignmentResponse is the runner wearing a bib; exactly one of
+// AthleteID and CompetitorID is set. At a meet, Season marks a season bib
+// the runner has no meet bib in place of.
+type BibAssignmentResponse struct {
+	Bib          int32  `json:"bib"`
+	AthleteID    int32  `json:"athleteId,omitempty"`
+	CompetitorID int32  `json:"competitorId,omitempty"`
+	Name         string `json:"name"`
+	Team         string `json:"team,omitempty"`
+	Grade        int16  `json:"grade,omitempty"`
+	Season       bool   `json:"season,omitempty"`
+}
+
+func newBibAssignmentResponse(b db.GetBibAssignmentsForMeetRow) BibAssignmentResponse {
+	response := BibAssignmentResponse{
+		Bib:          b.Bib,
+		AthleteID:    b.AthleteID.Int32,
+		CompetitorID: b.CompetitorID.Int32,
+		Name:         b.AthleteName.String,
+		Team:         resultTeam(b.AthleteID, b.SchoolName, StatusFinished),
+		Grade:        b.Grade.Int16,
+		Season:       !b.MeetID.Valid,
+	}
+	if b.CompetitorID.Valid {
+		response.Name = b.CompetitorName.String
+	}
+	return response
+}
+
+// bibAssignmentRequest assigns a bib to one of our athletes or to another
+// school's competitor
+type bibAssignmentRequest struct {
+	Bib          int32 `json:"bib" binding:"required"`
+	AthleteID    int32 `json:"athleteId"`
+	CompetitorID int32 `json:"competitorId"`
+}
+
+// checkBibAssignments checks a season's or meet's bib assignments using
+// q, returning a problem for each invalid one. taken holds bibs already
+// worn by other runners, such as season bibs at a meet; runners assigned
+// here are dropped from it, as their new bib replaces the old.
+func checkBibAssignments(q *db.Queries, requests []bibAssignmentRequest, taken []db.GetBibAssignmentsForSeasonRow) []RowErrorResponse {
+	reassigned := make(map[[2]int32]bool)
+	for _, r := range requests {
+		reassigned[[2]int32{r.AthleteID, r.CompetitorID}] = true
+	}
+	others := make(map[int32]string)
+	for _, t := range taken {
+		if !reassigned[[2]int32{t.AthleteID.Int32, t.CompetitorID.Int32}] {
+			others[t.Bib] = t.AthleteName.String + t.CompetitorName.String
+		}
+	}
+
+	rowErrors := []RowErrorResponse{}
+	bibs := make(map[int32]bool)
+	runners := newAssignedRunners()
+	for i, r := range requests {
+		var err error
+		switch {
+		case r.Bib <= 0:
+			err = errors.New("Bib must be a positive number")
+		case bibs[r.Bib]:
+			err = fmt.Errorf("Bib %d is assigned more than once", r.Bib)
+		case others[r.Bib] != "":
+			err = fmt.Errorf("Bib %d is %s's season bib", r.Bib, others[r.Bib])
+		default:
+			err = runners.add(q, r.AthleteID, r.CompetitorID)
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, RowErrorResponse{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		bibs[r.Bib] = true
+	}
+	return rowErrors
+}
+
+// checkSeasonBibsAtMeets checks new season bibs against the meet bibs
+// already assigned in the season, returning a problem for each season bib
+// that another runner wears at a meet where its runner has no meet bib of
+// their own
+func checkSeasonBibsAtMeets(requests []bibAssignmentRequest, meetBibs []db.GetMeetBibAssignmentsForSeasonRow) []RowErrorResponse {
+	type meetBib struct {
+		meetID int32
+		bib    int32
+	}
+	type meetRunner struct {
+		meetID                  int32
+		athleteID, competitorID int32
+	}
+	wearers := make(map[meetBib]db.GetMeetBibAssignmentsForSeasonRow)
+	hasMeetBib := make(map[meetRunner]bool)
+	var meets []db.GetMeetBibAssignmentsForSeasonRow
+	for _, b := range meetBibs {
+		if len(meets) == 0 || meets[len(meets)-1].MeetID != b.MeetID {
+			meets = append(meets, b)
+		}
+		wearers[meetBib{b.MeetID.Int32, b.Bib}] = b
+		hasMeetBib[meetRunner{b.MeetID.Int32, b.AthleteID.Int32, b.CompetitorID.Int32}] = true
+	}
+
+	rowErrors := []RowErrorResponse{}
+	for i, r := range requests {
+		for _, m := range meets {
+			if hasMeetBib[meetRunner{m.MeetID.Int32, r.AthleteID, r.CompetitorID}] {
+				continue
+			}
+			if w, ok := wearers[meetBib{m.MeetID.Int32, r.Bib}]; ok {
+				rowErrors = append(rowErrors, RowErrorResponse{
+					Row:   i + 1,
+					Error: fmt.Sprintf("Bib %d is %s's bib at %s", r.Bib, w.AthleteName.String+w.CompetitorName.String, m.MeetName),
+				})
+				break
+			}
+		}
+	}
+	return rowErrors
+}
+
+// replaceBibAssignments swaps a meet's or season's bibs for requests using
+// q, which should be bound to a transaction
+func replaceBibAssignments(q *db.Queries, meetID, seasonID sql.NullInt32, requests []bibAssignmentRequest) error {
+	var err error
+	if meetID.Valid {
+		err = q.DeleteBibAssignmentsForMeet(context.Background(), meetID)
+	} else {
+		err = q.DeleteBibAssignmentsForSeason(context.Background(), seasonID)
+	}
+	if err != nil {
+		return err
+	}
+	for _, r := range requests {
+		err := q.CreateBibAssignment(context.Background(), db.CreateBibAssignmentParams{
+			MeetID:       meetID,
+			SeasonID:     seasonID,
+			Bib:          r.Bib,
+			AthleteID:    sql.NullInt32{Int32: r.AthleteID, Valid: r.AthleteID != 0},
+			CompetitorID: sql.NullInt32{Int32: r.CompetitorID, Valid: r.CompetitorID != 0},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// meetBibs looks up the runner wearing each bib at a meet using q. A bib
+// worn by two runners, which a season bib reused as another runner's meet
+// bib can cause, is left out and reported in ambiguous.
+func meetBibs(q *db.Queries, meetID int32) (bibs map[int32]db.GetBibAssignmentsForMeetRow, ambiguous map[int32]bool, err error) {
+	rows, err := q.GetBibAssignmentsForMeet(context.Background(), meetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	bibs = make(map[int32]db.GetBibAssignmentsForMeetRow)
+	ambiguous = make(map[int32]bool)
+	for _, r := range rows {
+		if _, ok := bibs[r.Bib]; ok {
+			ambiguous[r.Bib] = true
+		}
+		bibs[r.Bib] = r
+	}
+	for bib := range ambiguous {
+		delete(bibs, bib)
+	}
+	return bibs, ambiguous, nil
+}
+
+// bibResultRequest is a result entered by the bib the runner wore; the
+// other fields are as for resultRequest
+type bibResultRequest struct {
+	Bib      int32          `json:"bib" binding:"required"`
+	RaceID   int32          `json:"raceId"`
+	Division string         `json:"division"`
+	Status   string         `json:"status"`
+	Time     string         `json:"time"`
+	Place    int32          `json:"place"`
+	Splits   []splitRequest `json:"splits" binding:"dive"`
+}
+
+// bibResults resolves the runners of results entered by bib at a meet
+// using q, returning a problem for each unknown bib
+func bibResults(q *db.Queries, meetID int32, requests []bibResultRequest) ([]resultRequest, []RowErrorResponse, error) {
+	bibs, ambiguous, err := meetBibs(q, meetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]resultRequest, len(requests))
+	rowErrors := []RowErrorResponse{}
+	for i, r := range requests {
+		runner, ok := bibs[r.Bib]
+		switch {
+		case ambiguous[r.Bib]:
+			rowErrors = append(rowErrors, RowErrorResponse{Row: i + 1, Error: fmt.Sprintf("Bib %d is assigned to more than one runner", r.Bib)})
+			continue
+		case !ok:
+			rowErrors = append(rowErrors, RowErrorResponse{Row: i + 1, Error: fmt.Sprintf("Bib %d is not assigned", r.Bib)})
+			continue
+		}
+		results[i] = resultRequest{
+			AthleteID:    runner.AthleteID.Int32,
+			CompetitorID: runner.CompetitorID.Int32,
+			RaceID:       r.RaceID,
+			Division:     r.Division,
+			Status:       r.Status,
+			Time:         r.Time,
+			Place:        r.Place,
+			Splits:       r.Splits,
+		}
+	}
+	return results, rowErrors, nil
+}
+
+// bibsTable lays out a meet's bib assignments for export
+func bibsTable(meetName string, bibs []BibAssignmentResponse) spreadsheet.Table {
+	t := spreadsheet.Table{
+		Sheet:  meetName + " Bibs",
+		Header: []string{"Bib", "Name", "Team", "Grade"},
+	}
+	for _, b := range bibs {
+		var grade any
+		if b.Grade != 0 {
+			grade = b.Grade
+		}
+		t.Rows = append(t.Rows, []any{b.Bib, b.Name, b.Team, grade})
+	}
+	return t
+}
+
+// registerBibRoutes adds bib number routes to the statistician group
+func registerBibRoutes(statisticians *gin.RouterGroup) {
+	// Get the bibs worn at a meet, including season bibs, as JSON or as a
+	// CSV or Excel spreadsheet for the meet director
+	statisticians.GET("/meets/:id/bibs", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		format, err := exportFormat(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		rows, err := queries.GetBibAssignmentsForMeet(context.Background(), meet.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]BibAssignmentResponse, len(rows))
+		for i, r := range rows {
+			response[i] = newBibAssignmentResponse(r)
+		}
+
+		if format != FormatJSON {
+			writeSpreadsheet(c, format, meetFileName(meet, "bibs"), bibsTable(meet.Name, response))
+			return
+		}
+		c.JSON(200, response)
+	})
+
+	// Replace the bibs assigned for one meet. They are worn in place of
+	// the runners' season bibs, and may not reuse another runner's.
+	statisticians.PUT("/meets/:id/bibs", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		var req struct {
+			Assignments []bibAssignmentRequest `json:"assignments" binding:"dive"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+
+		var seasonBibs []db.GetBibAssignmentsForSeasonRow
+		if meet.SeasonID.Valid {
+			seasonBibs, err = q.GetBibAssignmentsForSeason(context.Background(), meet.SeasonID)
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if rowErrors := checkBibAssignments(q, req.Assignments, seasonBibs); len(rowErrors) > 0 {
+			c.JSON(400, gin.H{"error": "No bibs were assigned", "errors": rowErrors})
+			return
+		}
+
+		meetID := sql.NullInt32{Int32: meet.ID, Valid: true}
+		if err := replaceBibAssignments(q, meetID, sql.NullInt32{}, req.Assignments); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": fmt.Sprintf("%d bibs assigned", len(req.Assignments))})
+	})
+
+	// Get the bibs assigned for a whole season
+	statisticians.GET("/seasons/:id/bibs", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid season ID"})
+			return
+		}
+
+		rows, err := queries.GetBibAssignmentsForSeason(context.Background(), sql.NullInt32{Int32: int32(id), Valid: true})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]BibAssignmentResponse, len(rows))
+		for i, r := range rows {
+			response[i] = BibAssignmentResponse{
+				Bib:          r.Bib,
+				AthleteID:    r.AthleteID.Int32,
+				CompetitorID: r.CompetitorID.Int32,
+				Name:         r.AthleteName.String + r.CompetitorName.String,
+			}
+		}
+		c.JSON(200, response)
+	})
+
+	// Replace the bibs assigned for a whole season. A runner's meet bib
+	// still replaces their season bib at that meet, and a season bib may
+	// not be one another runner already wears at a meet.
+	statisticians.PUT("/seasons/:id/bibs", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid season ID"})
+			return
+		}
+
+		season, err := queries.GetSeasonByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Season not found"})
+			return
+		}
+
+		var req struct {
+			Assignments []bibAssignmentRequest `json:"assignments" binding:"dive"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+
+		seasonID := sql.NullInt32{Int32: season.ID, Valid: true}
+		meetBibs, err := q.GetMeetBibAssignmentsForSeason(context.Background(), seasonID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		rowErrors := checkBibAssignments(q, req.Assignments, nil)
+		rowErrors = append(rowErrors, checkSeasonBibsAtMeets(req.Assignments, meetBibs)...)
+		if len(rowErrors) > 0 {
+			sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+			c.JSON(400, gin.H{"error": "No bibs were assigned", "errors": rowErrors})
+			return
+		}
+
+		if err := replaceBibAssignments(q, sql.NullInt32{}, seasonID, req.Assignments); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": fmt.Sprintf("%d bibs assigned", len(req.Assignments))})
+	})
+
+	// Create a meet's results by bib number, as recorded at the finish
+	// chute. As with bulk results, nothing is added unless every row is
+	// valid.
+	statisticians.POST("/meets/:id/results/bibs", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		var req struct {
+			Results []bibResultRequest `json:"results" binding:"required,dive"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if len(req.Results) == 0 {
+			c.JSON(400, gin.H{"error": "At least one result is required"})
+			return
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+		changes := snapshotMeet(q, meet.ID)
+
+		results, rowErrors, err := bibResults(q, meet.ID, req.Results)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if len(rowErrors) > 0 {
+			c.JSON(400, gin.H{"error": "No results were added", "errors": rowErrors})
+			return
+		}
+
+		ids, rowErrors, err := addResults(q, meet.ID, results)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if len(rowErrors) > 0 {
+			c.JSON(400, gin.H{"error": "No results were added", "errors": rowErrors})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		changes.publish()
+
+		c.JSON(201, gin.H{"ids": ids, "message": fmt.Sprintf("%d results created", len(ids))})
+	})
+}
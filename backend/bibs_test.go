@@ -0,0 +1,92 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSeasonBibsCollideWithMeetBibs(t *testing.T) {
+	fake := useFakeDB(t)
+	fake.rows["GetSeasonByID"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(1), int64(2024), time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 11, 15, 0, 0, 0, 0, time.UTC), true, time.Now()}}
+	}
+	fake.rows["GetAthleteByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, "Runner", int64(10), "girls", nil, time.Now()}}
+	}
+	// Athlete 1 wears bib 101 at the opener and athlete 3 has bib 102 there;
+	// athlete 2 wears bib 102 at the invitational
+	fake.rows["GetMeetBibAssignmentsForSeason"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{
+			{int64(1), "Season Opener", int64(101), int64(1), nil, "Jane Smith", nil},
+			{int64(1), "Season Opener", int64(102), int64(3), nil, "Kate Brown", nil},
+			{int64(2), "Jones County Invitational", int64(102), int64(2), nil, "Mary Doe", nil},
+		}
+	}
+	r := newRouter()
+	token := fake.login(RoleStatistician)
+
+	tests := []struct {
+		name   string
+		body   string
+		code   int
+		errors []RowErrorResponse
+	}{
+		{
+			name: "free bibs",
+			body: `{"assignments":[{"bib":1,"athleteId":1},{"bib":2,"athleteId":2}]}`,
+			code: 200,
+		},
+		{
+			// A runner's own meet bib is no collision
+			name: "own meet bib",
+			body: `{"assignments":[{"bib":101,"athleteId":1}]}`,
+			code: 200,
+		},
+		{
+			name:   "another runner's meet bib",
+			body:   `{"assignments":[{"bib":1,"athleteId":1},{"bib":101,"athleteId":2}]}`,
+			code:   400,
+			errors: []RowErrorResponse{{Row: 2, Error: "Bib 101 is Jane Smith's bib at Season Opener"}},
+		},
+		{
+			// Athlete 3 wears their meet bib at the opener, so bib 102 only
+			// collides at the invitational
+			name:   "collides at one meet",
+			body:   `{"assignments":[{"bib":102,"athleteId":3}]}`,
+			code:   400,
+			errors: []RowErrorResponse{{Row: 1, Error: "Bib 102 is Mary Doe's bib at Jones County Invitational"}},
+		},
+		{
+			name: "with other problems",
+			body: `{"assignments":[{"bib":101,"athleteId":4},{"bib":-1,"athleteId":5}]}`,
+			code: 400,
+			errors: []RowErrorResponse{
+				{Row: 1, Error: "Bib 101 is Jane Smith's bib at Season Opener"},
+				{Row: 2, Error: "Bib must be a positive number"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveJSON(r, "PUT", "/api/seasons/1/bibs", token, tt.body)
+			if w.Code != tt.code {
+				t.Fatalf("got %d, want %d: %s", w.Code, tt.code, w.Body)
+			}
+			if tt.code != 400 {
+				return
+			}
+			var response struct {
+				Errors []RowErrorResponse `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(response.Errors, tt.errors) {
+				t.Errorf("got errors %+v, want %+v", response.Errors, tt.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/ical"
+
+	"github.com/gin-gonic/gin"
+)
+
+// meetUID identifies a meet's event in every feed it appears in, so
+// subscribed calendars update the event when the meet changes
+func meetUID(id int32) string {
+	return fmt.Sprintf("meet-%d@jones-county-xc", id)
+}
+
+// meetEventDescription describes a meet followed by its race schedule
+func meetEventDescription(meet db.Meet, races []db.Race) string {
+	var lines []string
+	if meet.Description.Valid && meet.Description.String != "" {
+		lines = append(lines, meet.Description.String, "")
+	}
+	for _, r := range races {
+		line := r.Name
+		if r.StartTime.Valid {
+			line = r.StartTime.Time.Format("3:04 PM") + "  " + line
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// buildMeetCalendar makes an event for each meet. With a division, only
+// meets with a race in it are included and only those races are listed.
+func buildMeetCalendar(name string, meets []db.Meet, races []db.Race, division string) ical.Calendar {
+	byMeet := make(map[int32][]db.Race)
+	for _, r := range races {
+		if division == "" || r.Division == division {
+			byMeet[r.MeetID] = append(byMeet[r.MeetID], r)
+		}
+	}
+
+	cal := ical.Calendar{Name: name, Stamp: time.Now()}
+	for _, m := range meets {
+		if division != "" && len(byMeet[m.ID]) == 0 {
+			continue
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         meetUID(m.ID),
+			Summary:     m.Name,
+			Location:    m.Location,
+			Description: meetEventDescription(m, byMeet[m.ID]),
+			Date:        m.MeetDate,
+		})
+	}
+	return cal
+}
+
+// registerCalendarRoutes adds the meet schedule feed to the public group
+func registerCalendarRoutes(api *gin.RouterGroup) {
+	// Get the meet schedule as an iCalendar feed, optionally for one
+	// season and one division
+	api.GET("/meets.ics", func(c *gin.Context) {
+		seasonID, err := seasonFilter(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		division, err := queryFilter(c, "division", validDivision)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		meets, err := queries.GetAllMeets(context.Background(), seasonID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		races, err := queries.GetRacesForSeason(context.Background(), seasonID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		name := "Jones County XC"
+		if division.Valid {
+			name += " " + divisionNames[division.String]
+		}
+
+		var buf bytes.Buffer
+		if err := buildMeetCalendar(name, meets, races, division.String).Write(&buf); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.ics"`, fileSlug(name+" meets")))
+		c.Data(200, "text/calendar; charset=utf-8", buf.Bytes())
+	})
+}
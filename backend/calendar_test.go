@@ -0,0 +1,139 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"jones-county-xc/backend/db"
+)
+
+var (
+	calendarMeets = []db.Meet{
+		{ID: 1, Name: "Season Opener", MeetDate: time.Date(2024, 8, 24, 0, 0, 0, 0, time.UTC), Location: "Jones County High School"},
+		{ID: 2, Name: "Jones County Invitational", MeetDate: time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC), Location: "Gray, GA"},
+	}
+	calendarRaces = []db.Race{
+		{ID: 1, MeetID: 1, Name: "Varsity Girls", Division: DivisionVarsity},
+		{ID: 2, MeetID: 2, Name: "Varsity Boys", Division: DivisionVarsity,
+			StartTime: sql.NullTime{Time: time.Date(2024, 9, 14, 9, 0, 0, 0, time.UTC), Valid: true}},
+		{ID: 3, MeetID: 2, Name: "Middle School", Division: DivisionMiddleSchool},
+	}
+)
+
+func eventUIDs(meets []db.Meet, division string) []string {
+	var uids []string
+	for _, e := range buildMeetCalendar("test", meets, calendarRaces, division).Events {
+		uids = append(uids, e.UID)
+	}
+	return uids
+}
+
+func TestMeetCalendarUIDsAreStable(t *testing.T) {
+	first := eventUIDs(calendarMeets, "")
+	if !reflect.DeepEqual(first, []string{meetUID(1), meetUID(2)}) {
+		t.Fatalf("got UIDs %v", first)
+	}
+
+	// Regenerating after a meet is renamed and moved keeps its UID, as
+	// does filtering the feed
+	changed := append([]db.Meet(nil), calendarMeets...)
+	changed[1].Name = "Jones County Invitational (rescheduled)"
+	changed[1].MeetDate = changed[1].MeetDate.AddDate(0, 0, 7)
+	if again := eventUIDs(changed, ""); !reflect.DeepEqual(again, first) {
+		t.Errorf("UIDs changed from %v to %v", first, again)
+	}
+	if filtered := eventUIDs(calendarMeets, DivisionMiddleSchool); !reflect.DeepEqual(filtered, []string{meetUID(2)}) {
+		t.Errorf("middle school feed has UIDs %v", filtered)
+	}
+}
+
+func TestMeetCalendarDivisionFilter(t *testing.T) {
+	cal := buildMeetCalendar("test", calendarMeets, calendarRaces, DivisionMiddleSchool)
+	if len(cal.Events) != 1 {
+		t.Fatalf("got %d events, want only the meet with a middle school race", len(cal.Events))
+	}
+	if got := cal.Events[0].Description; got != "Middle School" {
+		t.Errorf("description lists %q, want only the middle school race", got)
+	}
+
+	all := buildMeetCalendar("test", calendarMeets, calendarRaces, "")
+	if got := all.Events[1].Description; got != "9:00 AM  Varsity Boys\nMiddle School" {
+		t.Errorf("description is %q, want every race", got)
+	}
+}
+
+func TestMeetCalendarFeedFilters(t *testing.T) {
+	fake := useFakeDB(t)
+	var seasonArgs []any
+	fake.rows["GetSeasonByYear"] = func(args []driver.NamedValue) [][]driver.Value {
+		if args[0].Value != int64(2024) {
+			return nil
+		}
+		return [][]driver.Value{{int64(7), int64(2024), time.Now(), time.Now(), false, time.Now()}}
+	}
+	fake.rows["GetAllMeets"] = func(args []driver.NamedValue) [][]driver.Value {
+		seasonArgs = append(seasonArgs, args[0].Value)
+		var rows [][]driver.Value
+		for _, m := range calendarMeets {
+			rows = append(rows, []driver.Value{int64(m.ID), nil, nil, m.Name, m.MeetDate, m.Location, nil, nil})
+		}
+		return rows
+	}
+	fake.rows["GetRacesForSeason"] = func([]driver.NamedValue) [][]driver.Value {
+		var rows [][]driver.Value
+		for _, r := range calendarRaces {
+			var start driver.Value
+			if r.StartTime.Valid {
+				start = r.StartTime.Time
+			}
+			rows = append(rows, []driver.Value{int64(r.ID), int64(r.MeetID), nil, r.Name, nil, r.Division, start, nil, nil})
+		}
+		return rows
+	}
+	r := newRouter()
+
+	tests := []struct {
+		query  string
+		code   int
+		season any
+		meets  []string
+	}{
+		{"", 200, nil, []string{"Season Opener", "Jones County Invitational"}},
+		{"?season=all", 200, nil, []string{"Season Opener", "Jones County Invitational"}},
+		{"?season=2024", 200, int64(7), []string{"Season Opener", "Jones County Invitational"}},
+		{"?season=2024&division=middle_school", 200, int64(7), []string{"Jones County Invitational"}},
+		{"?season=1999", 400, nil, nil},
+		{"?division=elementary", 400, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			seasonArgs = nil
+			w := serve(r, "GET", "/api/meets.ics"+tt.query, "")
+			if w.Code != tt.code {
+				t.Fatalf("got %d, want %d: %s", w.Code, tt.code, w.Body)
+			}
+			if tt.code != 200 {
+				return
+			}
+			if len(seasonArgs) != 1 || seasonArgs[0] != tt.season {
+				t.Errorf("meets were queried for seasons %v, want %v", seasonArgs, tt.season)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "text/calendar; charset=utf-8" {
+				t.Errorf("content type is %q", ct)
+			}
+			var meets []string
+			for _, line := range strings.Split(w.Body.String(), "\r\n") {
+				if name, ok := strings.CutPrefix(line, "SUMMARY:"); ok {
+					meets = append(meets, name)
+				}
+			}
+			if !reflect.DeepEqual(meets, tt.meets) {
+				t.Errorf("feed has meets %v, want %v", meets, tt.meets)
+			}
+		})
+	}
+}
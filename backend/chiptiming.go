@@ -0,0 +1,657 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/racetime"
+	"jones-county-xc/backend/timing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gunTimeLayout is how gun times are written, to the millisecond
+const gunTimeLayout = "15:04:05.000"
+
+// timingMaxElapsed is the longest after the gun a read is taken to be a
+// finish in that race; races started longer ago than that are over
+const timingMaxElapsed = 3 * time.Hour
+
+// wallClock gives the local time of day labelled as UTC, matching how
+// race times are stored and read back from the database
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// startTimingIngest listens for chip reads from a finish-line reader on
+// addr, parsing lines with pattern and layout and ignoring repeat reads of
+// a chip within dedupe, such as "5s"
+func startTimingIngest(addr, pattern, layout, dedupe string) error {
+	protocol, err := timing.NewProtocol(pattern, layout)
+	if err != nil {
+		return err
+	}
+	window, err := time.ParseDuration(dedupe)
+	if err != nil {
+		return fmt.Errorf("invalid dedupe window %q", dedupe)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	deduper := timing.NewDeduper(window)
+	go func() {
+		err := timing.Serve(ln, func(line string) {
+			read, err := protocol.Parse(line, wallClock(time.Now()))
+			if err != nil {
+				log.Println("Timing:", err)
+				return
+			}
+			if !deduper.First(read) {
+				return
+			}
+			if err := recordRead(read); err != nil {
+				log.Printf("Timing: chip %s at %s: %v", read.Chip, read.Time.Format(gunTimeLayout), err)
+			}
+		})
+		if err != nil {
+			log.Println("Timing ingest stopped:", err)
+		}
+	}()
+	log.Println("Listening for chip reads on", addr)
+	return nil
+}
+
+// recordRead records a chip read as a provisional finish in the race the
+// chip's runner is running, for the runner the chip is assigned to at that
+// meet. Later reads of the same chip in the race only add to its read
+// count, unless they are earlier.
+func recordRead(read timing.Read) error {
+	races, err := queries.GetRacesStartedBetween(context.Background(), db.GetRacesStartedBetweenParams{
+		Since: sql.NullTime{Time: read.Time.Add(-timingMaxElapsed), Valid: true},
+		Until: sql.NullTime{Time: read.Time, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if len(races) == 0 {
+		return errors.New("no race has started")
+	}
+
+	race, assignment, err := readRace(queries, read.Chip, races)
+	if err != nil {
+		return err
+	}
+
+	return queries.RecordTimedFinish(context.Background(), db.RecordTimedFinishParams{
+		RaceID:       race.ID,
+		Chip:         read.Chip,
+		AthleteID:    assignment.AthleteID,
+		CompetitorID: assignment.CompetitorID,
+		ElapsedMs:    int32(read.Time.Sub(race.GunTime.Time).Milliseconds()),
+	})
+}
+
+// readRace picks the race a read of chip belongs to from the races running
+// when it was read, using q: the one at the meet the chip is assigned at
+// that is for its runner's gender. A chip assigned to no one can only be
+// placed when a single race is running. A read that could belong to more
+// than one race, such as during overlapping varsity and JV races, is not
+// recorded rather than being put in the wrong one.
+func readRace(q *db.Queries, chip string, races []db.Race) (db.Race, db.ChipAssignment, error) {
+	type candidate struct {
+		race       db.Race
+		assignment db.ChipAssignment
+	}
+	var candidates []candidate
+	assigned := false
+	genders := make(map[int32]sql.NullString)
+	for _, race := range races {
+		assignment, err := q.GetChipAssignment(context.Background(), db.GetChipAssignmentParams{
+			MeetID: race.MeetID,
+			Chip:   chip,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return db.Race{}, db.ChipAssignment{}, err
+		}
+		assigned = true
+
+		gender, ok := genders[assignment.ID]
+		if !ok {
+			if gender, err = runnerGender(q, assignment); err != nil {
+				return db.Race{}, db.ChipAssignment{}, err
+			}
+			genders[assignment.ID] = gender
+		}
+		if race.Gender.Valid && gender.Valid && race.Gender.String != gender.String {
+			continue
+		}
+		candidates = append(candidates, candidate{race, assignment})
+	}
+
+	switch {
+	case len(candidates) == 1:
+		return candidates[0].race, candidates[0].assignment, nil
+	case len(candidates) > 1:
+		names := make([]string, len(candidates))
+		for i, c := range candidates {
+			names[i] = c.race.Name
+		}
+		return db.Race{}, db.ChipAssignment{}, fmt.Errorf("could be in any of %s", strings.Join(names, ", "))
+	case assigned:
+		return db.Race{}, db.ChipAssignment{}, errors.New("no race for its runner is running")
+	case len(races) == 1:
+		return races[0], db.ChipAssignment{}, nil
+	default:
+		return db.Race{}, db.ChipAssignment{}, fmt.Errorf("not assigned to a runner in any of the %d races running", len(races))
+	}
+}
+
+// runnerGender finds the gender of the runner wearing an assigned chip
+func runnerGender(q *db.Queries, assignment db.ChipAssignment) (sql.NullString, error) {
+	if assignment.AthleteID.Valid {
+		athlete, err := q.GetAthleteByID(context.Background(), assignment.AthleteID.Int32)
+		return athlete.Gender, err
+	}
+	competitor, err := q.GetCompetitorByID(context.Background(), assignment.CompetitorID.Int32)
+	return competitor.Gender, err
+}
+
+// ChipAssignmentResponse is the runner wearing a chip at a meet; exactly
+// one of AthleteID and CompetitorID is set
+type ChipAssignmentResponse struct {
+	Chip         string `json:"chip"`
+	AthleteID    int32  `json:"athleteId,omitempty"`
+	CompetitorID int32  `json:"competitorId,omitempty"`
+	Name         string `json:"name"`
+}
+
+// chipAssignmentRequest assigns a chip to one of our athletes or to
+// another school's competitor
+type chipAssignmentRequest struct {
+	Chip         string `json:"chip" binding:"required"`
+	AthleteID    int32  `json:"athleteId"`
+	CompetitorID int32  `json:"competitorId"`
+}
+
+// assignedRunners tracks the runners given a chip or bib, so that each
+// gets only one
+type assignedRunners struct {
+	athletes    map[int32]bool
+	competitors map[int32]bool
+}
+
+func newAssignedRunners() *assignedRunners {
+	return &assignedRunners{athletes: make(map[int32]bool), competitors: make(map[int32]bool)}
+}
+
+// add checks an assignment's runner using q, which must be exactly one of
+// an athlete or a competitor on file and not assigned already
+func (a *assignedRunners) add(q *db.Queries, athleteID, competitorID int32) error {
+	switch {
+	case (athleteID != 0) == (competitorID != 0):
+		return errors.New("An assignment is for an athlete or a competitor")
+	case a.athletes[athleteID] || a.competitors[competitorID]:
+		return errors.New("The runner is assigned more than once")
+	case athleteID != 0:
+		if _, err := q.GetAthleteByID(context.Background(), athleteID); err != nil {
+			return errors.New("Athlete not found")
+		}
+		a.athletes[athleteID] = true
+	default:
+		if _, err := q.GetCompetitorByID(context.Background(), competitorID); err != nil {
+			return errors.New("Competitor not found")
+		}
+		a.competitors[competitorID] = true
+	}
+	return nil
+}
+
+// checkChipAssignments checks a meet's chip assignments using q, returning
+// a problem for each invalid one. Chips are compared without case, as
+// readers report them in either.
+func checkChipAssignments(q *db.Queries, requests []chipAssignmentRequest) []RowErrorResponse {
+	rowErrors := []RowErrorResponse{}
+	chips := make(map[string]bool)
+	runners := newAssignedRunners()
+	for i, r := range requests {
+		chip := strings.ToUpper(strings.TrimSpace(r.Chip))
+		var err error
+		switch {
+		case chip == "":
+			err = errors.New("A chip is required")
+		case chips[chip]:
+			err = fmt.Errorf("Chip %s is assigned more than once", chip)
+		default:
+			err = runners.add(q, r.AthleteID, r.CompetitorID)
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, RowErrorResponse{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		chips[chip] = true
+	}
+	return rowErrors
+}
+
+// TimedFinishResponse is a provisional result from chip reads. Its runner
+// is empty for a chip with no assignment, and ResultID is set once an
+// official has confirmed it.
+type TimedFinishResponse struct {
+	ID           int32  `json:"id"`
+	Chip         string `json:"chip"`
+	AthleteID    int32  `json:"athleteId,omitempty"`
+	CompetitorID int32  `json:"competitorId,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Time         string `json:"time"`
+	TimeMs       int32  `json:"timeMs"`
+	// Reads counts the reads of the chip, repeats included
+	Reads     int32 `json:"reads"`
+	ResultID  int32 `json:"resultId,omitempty"`
+	Confirmed bool  `json:"confirmed"`
+}
+
+func newTimedFinishResponse(f db.GetTimedFinishesForRaceRow) TimedFinishResponse {
+	name := f.AthleteName.String
+	if f.CompetitorID.Valid {
+		name = f.CompetitorName.String
+	}
+	return TimedFinishResponse{
+		ID:           f.ID,
+		Chip:         f.Chip,
+		AthleteID:    f.AthleteID.Int32,
+		CompetitorID: f.CompetitorID.Int32,
+		Name:         name,
+		Time:         racetime.Format(f.ElapsedMs),
+		TimeMs:       f.ElapsedMs,
+		Reads:        f.ReadCount,
+		ResultID:     f.ResultID.Int32,
+		Confirmed:    f.ResultID.Valid,
+	}
+}
+
+// Reasons a confirmation is refused as a whole
+var (
+	errTimedFinishNotFound = errors.New("Timed finish not found in this race")
+	errNothingToConfirm    = errors.New("No finishes to confirm")
+)
+
+// confirmTimedFinishes makes results of a race's timed finishes using q,
+// which should be bound to a transaction: the finishes with the given IDs,
+// or every one not yet confirmed when ids is empty. Places follow the
+// order of every finish with a runner. Problems are numbered by the
+// finish's row in the race's timing, and nothing is confirmed when there
+// are any.
+func confirmTimedFinishes(q *db.Queries, race db.Race, ids []int32) ([]int32, []RowErrorResponse, error) {
+	finishes, err := q.GetTimedFinishesForRace(context.Background(), race.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := make(map[int32]bool)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var requests []resultRequest
+	var rows []int
+	rowErrors := []RowErrorResponse{}
+	place := int32(0)
+	for i, f := range finishes {
+		chosen := len(ids) == 0 || wanted[f.ID]
+		delete(wanted, f.ID)
+		if !f.AthleteID.Valid && !f.CompetitorID.Valid {
+			if len(ids) > 0 && chosen {
+				rowErrors = append(rowErrors, RowErrorResponse{Row: i + 1, Error: fmt.Sprintf("Chip %s is not assigned to a runner", f.Chip)})
+			}
+			continue
+		}
+		place++
+		if !chosen || f.ResultID.Valid {
+			continue
+		}
+		requests = append(requests, resultRequest{
+			AthleteID:    f.AthleteID.Int32,
+			CompetitorID: f.CompetitorID.Int32,
+			RaceID:       race.ID,
+			Status:       StatusFinished,
+			Time:         racetime.Format(f.ElapsedMs),
+			Place:        place,
+		})
+		rows = append(rows, i)
+	}
+	if len(wanted) > 0 {
+		return nil, nil, errTimedFinishNotFound
+	}
+	if len(rowErrors) > 0 {
+		return nil, rowErrors, nil
+	}
+	if len(requests) == 0 {
+		return nil, nil, errNothingToConfirm
+	}
+
+	resultIDs, resultErrors, err := addResults(q, race.MeetID, requests)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range resultErrors {
+		i := rows[e.Row-1]
+		rowErrors = append(rowErrors, RowErrorResponse{Row: i + 1, Error: fmt.Sprintf("Chip %s: %s", finishes[i].Chip, e.Error)})
+	}
+	if len(rowErrors) > 0 {
+		return nil, rowErrors, nil
+	}
+
+	for n, i := range rows {
+		err := q.ConfirmTimedFinish(context.Background(), db.ConfirmTimedFinishParams{
+			ResultID: sql.NullInt32{Int32: resultIDs[n], Valid: true},
+			ID:       finishes[i].ID,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return resultIDs, nil, nil
+}
+
+// registerTimingRoutes adds chip timing routes to the statistician group
+func registerTimingRoutes(statisticians *gin.RouterGroup) {
+	// Get the chips assigned to runners at a meet
+	statisticians.GET("/meets/:id/chips", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		rows, err := queries.GetChipAssignmentsForMeet(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]ChipAssignmentResponse, len(rows))
+		for i, r := range rows {
+			name := r.AthleteName.String
+			if r.CompetitorID.Valid {
+				name = r.CompetitorName.String
+			}
+			response[i] = ChipAssignmentResponse{
+				Chip:         r.Chip,
+				AthleteID:    r.AthleteID.Int32,
+				CompetitorID: r.CompetitorID.Int32,
+				Name:         name,
+			}
+		}
+		c.JSON(200, response)
+	})
+
+	// Replace the chips assigned to runners at a meet. Reads already
+	// recorded keep the runner they were recorded for.
+	statisticians.PUT("/meets/:id/chips", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		var req struct {
+			Assignments []chipAssignmentRequest `json:"assignments" binding:"dive"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+
+		if rowErrors := checkChipAssignments(q, req.Assignments); len(rowErrors) > 0 {
+			c.JSON(400, gin.H{"error": "No chips were assigned", "errors": rowErrors})
+			return
+		}
+
+		if err := q.DeleteChipAssignmentsForMeet(context.Background(), meet.ID); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		for _, a := range req.Assignments {
+			err := q.CreateChipAssignment(context.Background(), db.CreateChipAssignmentParams{
+				MeetID:       meet.ID,
+				Chip:         strings.ToUpper(strings.TrimSpace(a.Chip)),
+				AthleteID:    sql.NullInt32{Int32: a.AthleteID, Valid: a.AthleteID != 0},
+				CompetitorID: sql.NullInt32{Int32: a.CompetitorID, Valid: a.CompetitorID != 0},
+			})
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": fmt.Sprintf("%d chips assigned", len(req.Assignments))})
+	})
+
+	// Start a chip timed race. Reads that follow go to this race until
+	// another starts. The gun time is now unless given as "15:04:05.000",
+	// which is taken to be today, as reads are dated the day they arrive.
+	statisticians.POST("/races/:id/start", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid race ID"})
+			return
+		}
+
+		race, err := queries.GetRaceByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Race not found"})
+			return
+		}
+
+		var req struct {
+			GunTime string `json:"gunTime"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		now := wallClock(time.Now())
+		gun := now
+		if req.GunTime != "" {
+			t, err := time.Parse("15:04:05", req.GunTime)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "Invalid gun time format. Use HH:MM:SS.sss"})
+				return
+			}
+			gun = time.Date(now.Year(), now.Month(), now.Day(),
+				t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		}
+
+		err = queries.UpdateRaceGunTime(context.Background(), db.UpdateRaceGunTimeParams{
+			GunTime: sql.NullTime{Time: gun.Truncate(time.Millisecond), Valid: true},
+			ID:      race.ID,
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"gunTime": gun.Format(gunTimeLayout), "message": "Race started"})
+	})
+
+	// Get a race's provisional results from chip reads, fastest first
+	statisticians.GET("/races/:id/timing", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid race ID"})
+			return
+		}
+
+		finishes, err := queries.GetTimedFinishesForRace(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]TimedFinishResponse, len(finishes))
+		for i, f := range finishes {
+			response[i] = newTimedFinishResponse(f)
+		}
+		c.JSON(200, response)
+	})
+
+	// Confirm a race's timed finishes as results: those whose IDs are
+	// given, or every finish with a runner that is not yet confirmed
+	statisticians.POST("/races/:id/timing/confirm", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid race ID"})
+			return
+		}
+
+		race, err := queries.GetRaceByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Race not found"})
+			return
+		}
+
+		var req struct {
+			IDs []int32 `json:"ids"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+		changes := snapshotMeet(q, race.MeetID)
+
+		ids, rowErrors, err := confirmTimedFinishes(q, race, req.IDs)
+		if errors.Is(err, errTimedFinishNotFound) || errors.Is(err, errNothingToConfirm) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if len(rowErrors) > 0 {
+			c.JSON(400, gin.H{"error": "No results were confirmed", "errors": rowErrors})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		changes.publish()
+
+		c.JSON(201, gin.H{"ids": ids, "message": fmt.Sprintf("%d results confirmed", len(ids))})
+	})
+
+	// Set the runner for a timed finish, such as one from an unassigned
+	// chip, before it is confirmed
+	statisticians.PUT("/timed-finishes/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid timed finish ID"})
+			return
+		}
+
+		finish, err := queries.GetTimedFinishByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Timed finish not found"})
+			return
+		}
+		if finish.ResultID.Valid {
+			c.JSON(409, gin.H{"error": "Timed finish is already confirmed"})
+			return
+		}
+
+		var req struct {
+			AthleteID    int32 `json:"athleteId"`
+			CompetitorID int32 `json:"competitorId"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if rowErrors := checkChipAssignments(queries, []chipAssignmentRequest{{
+			Chip:         finish.Chip,
+			AthleteID:    req.AthleteID,
+			CompetitorID: req.CompetitorID,
+		}}); len(rowErrors) > 0 {
+			c.JSON(400, gin.H{"error": rowErrors[0].Error})
+			return
+		}
+
+		err = queries.AssignTimedFinish(context.Background(), db.AssignTimedFinishParams{
+			AthleteID:    sql.NullInt32{Int32: req.AthleteID, Valid: req.AthleteID != 0},
+			CompetitorID: sql.NullInt32{Int32: req.CompetitorID, Valid: req.CompetitorID != 0},
+			ID:           finish.ID,
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Timed finish updated"})
+	})
+
+	// Throw out a timed finish, such as a read of a spectator's chip. A
+	// result already confirmed from it is kept.
+	statisticians.DELETE("/timed-finishes/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid timed finish ID"})
+			return
+		}
+
+		if _, err := queries.GetTimedFinishByID(context.Background(), int32(id)); err != nil {
+			c.JSON(404, gin.H{"error": "Timed finish not found"})
+			return
+		}
+
+		if err := queries.DeleteTimedFinish(context.Background(), int32(id)); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Timed finish deleted"})
+	})
+}
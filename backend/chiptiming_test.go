@@ -0,0 +1,290 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/timing"
+)
+
+var timingGun = time.Date(2024, 10, 12, 9, 0, 0, 0, time.UTC)
+
+// startedRaces answers GetRacesStartedBetween from races, given as race
+// rows latest first, and chip assignments from chips, by meet and then chip, with
+// athletes 1-9 girls and 10 and up boys
+func startedRaces(fake *fakeDB, races [][]driver.Value, chips map[int64]map[string]int64) {
+	fake.rows["GetRacesStartedBetween"] = func(args []driver.NamedValue) [][]driver.Value {
+		since, until := args[0].Value.(time.Time), args[1].Value.(time.Time)
+		var started [][]driver.Value
+		for _, race := range races {
+			gun := race[7].(time.Time)
+			if !gun.Before(since) && gun.Before(until) {
+				started = append(started, race)
+			}
+		}
+		return started
+	}
+	fake.rows["GetChipAssignment"] = func(args []driver.NamedValue) [][]driver.Value {
+		meetID, chip := args[0].Value.(int64), args[1].Value.(string)
+		athleteID, ok := chips[meetID][chip]
+		if !ok {
+			return nil
+		}
+		return [][]driver.Value{{meetID*100 + athleteID, meetID, chip, athleteID, nil, time.Now()}}
+	}
+	fake.rows["GetAthleteByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		gender := "girls"
+		if args[0].Value.(int64) >= 10 {
+			gender = "boys"
+		}
+		return [][]driver.Value{{args[0].Value, "Runner", int64(10), gender, nil, time.Now()}}
+	}
+}
+
+// startedRace is a race row started at gun
+func startedRace(id, meetID int64, name, gender, division string, gun time.Time) []driver.Value {
+	return []driver.Value{id, meetID, nil, name, gender, division, nil, gun, time.Now()}
+}
+
+// timingRace starts race 3 of meet 1 at timingGun; chip 058001 is athlete
+// 4's
+func timingRace(fake *fakeDB) {
+	startedRaces(fake,
+		[][]driver.Value{startedRace(3, 1, "Varsity Girls", "girls", DivisionVarsity, timingGun)},
+		map[int64]map[string]int64{1: {"058001": 4}})
+}
+
+// recordedFinish is the race, chip, athlete, competitor and elapsed time
+// of the last finish recorded
+func recordedFinish(fake *fakeDB) []any {
+	args := fake.execArgs["RecordTimedFinish"]
+	if args == nil {
+		return nil
+	}
+	return []any{args[0].Value, args[1].Value, args[2].Value, args[3].Value, args[4].Value}
+}
+
+func TestRecordRead(t *testing.T) {
+	fake := useFakeDB(t)
+	timingRace(fake)
+
+	tests := []struct {
+		chip    string
+		after   time.Duration
+		athlete any
+	}{
+		{"058001", 17*time.Minute + 5300*time.Millisecond, int64(4)},
+		// A chip no one is wearing is still recorded while only one race is
+		// running, for an official to assign
+		{"058999", 18 * time.Minute, nil},
+	}
+	for _, tt := range tests {
+		if err := recordRead(timing.Read{Chip: tt.chip, Time: timingGun.Add(tt.after)}); err != nil {
+			t.Fatalf("chip %s: %v", tt.chip, err)
+		}
+		want := []any{int64(3), tt.chip, tt.athlete, nil, tt.after.Milliseconds()}
+		if got := recordedFinish(fake); !reflect.DeepEqual(got, want) {
+			t.Errorf("chip %s recorded %v, want %v", tt.chip, got, want)
+		}
+	}
+}
+
+func TestRecordReadOverlappingRaces(t *testing.T) {
+	fake := useFakeDB(t)
+	yesterday := timingGun.AddDate(0, 0, -1)
+	// The boys go off at our meet while girls are still finishing, another
+	// meet is running a girls race, and yesterday's race is long over
+	startedRaces(fake,
+		[][]driver.Value{
+			startedRace(1, 5, "Varsity Girls", "girls", DivisionVarsity, yesterday.Add(time.Hour)),
+			startedRace(3, 1, "Varsity Girls", "girls", DivisionVarsity, timingGun),
+			startedRace(4, 1, "Varsity Boys", "boys", DivisionVarsity, timingGun.Add(30*time.Minute)),
+			startedRace(7, 2, "Girls 5K", "girls", DivisionVarsity, timingGun.Add(20*time.Minute)),
+		},
+		map[int64]map[string]int64{
+			1: {"058001": 4, "058002": 12},
+			5: {"058001": 4},
+		})
+
+	tests := []struct {
+		chip string
+		at   time.Time
+		want []any
+	}{
+		// A girl finishing after the boys' gun is still in the girls race
+		{"058001", timingGun.Add(35 * time.Minute), []any{int64(3), "058001", int64(4), nil, (35 * time.Minute).Milliseconds()}},
+		{"058002", timingGun.Add(50 * time.Minute), []any{int64(4), "058002", int64(12), nil, (20 * time.Minute).Milliseconds()}},
+		// With three races running, an unassigned chip could be in any
+		{"058999", timingGun.Add(40 * time.Minute), nil},
+	}
+	for _, tt := range tests {
+		fake.execArgs = make(map[string][]driver.NamedValue)
+		err := recordRead(timing.Read{Chip: tt.chip, Time: tt.at})
+		if (err != nil) != (tt.want == nil) {
+			t.Errorf("chip %s: %v", tt.chip, err)
+		}
+		if got := recordedFinish(fake); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("chip %s recorded %v, want %v", tt.chip, got, tt.want)
+		}
+	}
+}
+
+func TestRecordReadAmbiguous(t *testing.T) {
+	fake := useFakeDB(t)
+	// Varsity and JV girls run at once, and a runner has no division
+	startedRaces(fake,
+		[][]driver.Value{
+			startedRace(5, 1, "JV Girls", "girls", DivisionJV, timingGun.Add(5*time.Minute)),
+			startedRace(3, 1, "Varsity Girls", "girls", DivisionVarsity, timingGun),
+		},
+		map[int64]map[string]int64{1: {"058001": 4}})
+
+	err := recordRead(timing.Read{Chip: "058001", Time: timingGun.Add(25 * time.Minute)})
+	if err == nil || err.Error() != "could be in any of JV Girls, Varsity Girls" {
+		t.Errorf("got %v", err)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+}
+
+func TestRecordReadOutsideRace(t *testing.T) {
+	fake := useFakeDB(t)
+	timingRace(fake)
+
+	for _, at := range []time.Time{
+		timingGun.Add(-time.Minute),
+		timingGun.Add(timingMaxElapsed + time.Second),
+	} {
+		if err := recordRead(timing.Read{Chip: "058001", Time: at}); err == nil {
+			t.Errorf("a read at %s was recorded", at.Format(gunTimeLayout))
+		}
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+}
+
+// timedFinishes answers GetTimedFinishesForRace for race 3, fastest
+// first: an athlete, an unassigned chip, a competitor, an athlete already
+// confirmed as result 20 and another athlete
+func timedFinishes(fake *fakeDB) {
+	fake.rows["GetTimedFinishesForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{
+			{int64(11), "058001", int64(4), nil, int64(1025300), int64(3), nil, "Jane Smith", nil},
+			{int64(12), "058999", nil, nil, int64(1030000), int64(2), nil, nil, nil},
+			{int64(13), "077001", nil, int64(9), int64(1040000), int64(3), nil, nil, "Ann Lee"},
+			{int64(14), "058002", int64(5), nil, int64(1050000), int64(3), int64(20), "Mary Doe", nil},
+			{int64(15), "058003", int64(6), nil, int64(1060000), int64(1), nil, "Kate Brown", nil},
+		}
+	}
+	fake.rows["GetAthleteByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, "Runner", int64(10), "girls", nil, time.Now()}}
+	}
+	fake.rows["GetCompetitorByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, "Competitor", nil, "girls", nil, time.Now()}}
+	}
+	fake.rows["GetRaceByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, int64(1), nil, "Varsity Girls", "girls", DivisionVarsity, nil, timingGun, time.Now()}}
+	}
+	fake.rows["GetRaceDistance"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(5000)}}
+	}
+	fake.rows["CountCompetitorResultsForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(1)}}
+	}
+}
+
+var timingTestRace = db.Race{ID: 3, MeetID: 1}
+
+func TestConfirmAllTimedFinishes(t *testing.T) {
+	fake := useFakeDB(t)
+	timedFinishes(fake)
+
+	ids, rowErrors, err := confirmTimedFinishes(queries, timingTestRace, nil)
+	if err != nil || len(rowErrors) > 0 {
+		t.Fatalf("got %v %v", rowErrors, err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("confirmed %d finishes, want 3", len(ids))
+	}
+
+	// The unassigned chip is left for later and takes no place, and the
+	// finish already confirmed keeps its result but takes fourth place
+	creates, confirms := 0, 0
+	for _, e := range fake.execs {
+		switch e {
+		case "CreateResult":
+			creates++
+		case "ConfirmTimedFinish":
+			confirms++
+		}
+	}
+	if creates != 3 || confirms != 3 {
+		t.Errorf("ran %v", fake.execs)
+	}
+	if place := fake.execArgs["CreateResult"][5].Value; place != int64(4) {
+		t.Errorf("the last finish took place %v, want 4", place)
+	}
+	if id := fake.execArgs["ConfirmTimedFinish"][1].Value; id != int64(15) {
+		t.Errorf("the last finish confirmed was %v, want 15", id)
+	}
+}
+
+func TestConfirmChosenTimedFinish(t *testing.T) {
+	fake := useFakeDB(t)
+	timedFinishes(fake)
+
+	// Places count every finish with a runner, chosen or not
+	ids, rowErrors, err := confirmTimedFinishes(queries, timingTestRace, []int32{13})
+	if err != nil || len(rowErrors) > 0 || len(ids) != 1 {
+		t.Fatalf("got %v %v %v", ids, rowErrors, err)
+	}
+	args := fake.execArgs["CreateResult"]
+	got := []any{args[1].Value, args[4].Value, args[5].Value}
+	if want := []any{int64(9), int64(1040000), int64(2)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("created competitor, time and place %v, want %v", got, want)
+	}
+}
+
+func TestConfirmUnassignedTimedFinish(t *testing.T) {
+	fake := useFakeDB(t)
+	timedFinishes(fake)
+
+	_, rowErrors, err := confirmTimedFinishes(queries, timingTestRace, []int32{11, 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RowErrorResponse{{Row: 2, Error: "Chip 058999 is not assigned to a runner"}}
+	if !reflect.DeepEqual(rowErrors, want) {
+		t.Errorf("got %v, want %v", rowErrors, want)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+}
+
+func TestConfirmTimedFinishesRefused(t *testing.T) {
+	fake := useFakeDB(t)
+	timedFinishes(fake)
+
+	tests := []struct {
+		ids  []int32
+		want error
+	}{
+		{[]int32{11, 99}, errTimedFinishNotFound},
+		{[]int32{14}, errNothingToConfirm},
+	}
+	for _, tt := range tests {
+		if _, _, err := confirmTimedFinishes(queries, timingTestRace, tt.ids); !errors.Is(err, tt.want) {
+			t.Errorf("confirming %v got %v, want %v", tt.ids, err, tt.want)
+		}
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+}
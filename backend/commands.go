@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"jones-county-xc/backend/timing"
+)
+
+// errUsage is returned by a subcommand given bad arguments. When it is
+// returned as it is, the flag set has already shown what was wrong along
+// with the usage.
+var errUsage = errors.New("usage")
+
+// parseCommandFlags parses a subcommand's arguments, turning -h and bad
+// flags into errUsage
+func parseCommandFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// runImportCommand imports a meet's results from a CSV file, or from a
+// Hy-Tek results file with -format hytek, as the import endpoint does:
+//
+//	server import -meet 12 [-format hytek] [-race 40] [-division jv] [-gender girls] [-dry-run] results.csv
+//
+// Without -meet, Hy-Tek results go in the meet the file names, which is
+// added when there is none by that name on that date. The -name, -school, -grade, -time, -place and -status flags map CSV
+// columns by header name or number.
+func runImportCommand(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	meetID := flags.Int("meet", 0, "meet to import results into, required for CSV")
+	format := flags.String("format", "csv", "file format, csv or hytek")
+	raceID := flags.Int("race", 0, "race to put every result in")
+	division := flags.String("division", "", "division of the race for each result, when no race is given")
+	gender := flags.String("gender", "", "gender of competitors added by the import")
+	dryRun := flags.Bool("dry-run", false, "check and match the results without saving them")
+	var columns importColumns
+	flags.StringVar(&columns.Name, "name", "", "name column")
+	flags.StringVar(&columns.School, "school", "", "school column")
+	flags.StringVar(&columns.Grade, "grade", "", "grade column")
+	flags.StringVar(&columns.Time, "time", "", "time column")
+	flags.StringVar(&columns.Place, "place", "", "place column")
+	flags.StringVar(&columns.Status, "status", "", "status column")
+	if err := parseCommandFlags(flags, args); err != nil {
+		return err
+	}
+	if (*meetID == 0 && *format != "hytek") || flags.NArg() != 1 {
+		return fmt.Errorf("%w: server import -meet ID [flags] results.csv", errUsage)
+	}
+	if *gender != "" && !validGender(*gender) {
+		return errors.New("Gender must be boys or girls")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	conn, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := bootstrapHomeSchool(getEnv("HOME_SCHOOL", "Jones County")); err != nil {
+		return fmt.Errorf("Failed to load our school: %w", err)
+	}
+
+	opts := importOptions{
+		MeetID:   int32(*meetID),
+		RaceID:   int32(*raceID),
+		Division: *division,
+		Gender:   *gender,
+		DryRun:   *dryRun,
+	}
+	var rows []importRow
+	switch *format {
+	case "csv":
+		rows, err = readImportRows(f, columns)
+	case "hytek":
+		rows, opts.Meet, err = readHytekRows(f)
+	default:
+		err = errors.New("Format must be csv or hytek")
+	}
+	if err == nil && opts.MeetID == 0 {
+		opts.MeetID, err = findImportMeet(opts.Meet)
+	}
+	if err != nil {
+		return err
+	}
+
+	response, err := importResults(rows, opts)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROW\tNAME\tSCHOOL\tTIME\tMATCH\tMATCHED NAME")
+	for _, r := range response.Rows {
+		match := r.Match
+		if r.Fuzzy {
+			match += " (fuzzy)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", r.Row, r.Name, r.School, r.Time, match, r.MatchedName)
+	}
+	w.Flush()
+
+	for _, e := range response.Errors {
+		fmt.Printf("row %d: %s\n", e.Row, e.Error)
+	}
+	fmt.Printf("%d unmatched rows left out\n", response.Unmatched)
+	switch {
+	case len(response.Errors) > 0:
+		return fmt.Errorf("%d rows failed; nothing was imported", len(response.Errors))
+	case *dryRun:
+		fmt.Println("Dry run; nothing was imported")
+	case opts.MeetID == 0:
+		fmt.Printf("%d results imported into new meet %d, %s\n", len(response.IDs), response.MeetID, opts.Meet.Name)
+	default:
+		fmt.Printf("%d results imported\n", len(response.IDs))
+	}
+	return nil
+}
+
+// runTimingSimCommand stands in for a chip timing reader, sending reads
+// for a race's runners to the timing ingest service:
+//
+//	server timing-sim -race 40 [-addr localhost:4000] [-speed 60] [-repeats 3]
+//
+// The runners are those with chips at the race's meet, or the -chips
+// given, and they finish between -fastest and -slowest after the race's
+// gun time, which must be set by starting the race, or after -gun.
+func runTimingSimCommand(args []string) error {
+	flags := flag.NewFlagSet("timing-sim", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:4000", "address of the timing ingest service")
+	raceID := flags.Int("race", 0, "race to simulate")
+	chipList := flags.String("chips", "", "comma-separated chips, instead of the meet's assignments")
+	gunTime := flags.String("gun", "", "gun time as 15:04:05, instead of the race's")
+	fastest := flags.Duration("fastest", 16*time.Minute, "time of the first finisher")
+	slowest := flags.Duration("slowest", 30*time.Minute, "time of the last finisher")
+	repeats := flags.Int("repeats", 3, "reads of each chip as it crosses the line")
+	speed := flags.Float64("speed", 60, "times faster than real time to send reads, or 0 for all at once")
+	if err := parseCommandFlags(flags, args); err != nil {
+		return err
+	}
+	if *raceID == 0 && (*chipList == "" || *gunTime == "") {
+		return fmt.Errorf("%w: server timing-sim -race ID [flags], or -chips and -gun without a race", errUsage)
+	}
+
+	sim := timing.Simulation{
+		Fastest: *fastest,
+		Slowest: *slowest,
+		Repeats: *repeats,
+		Speed:   *speed,
+	}
+	if *chipList != "" {
+		sim.Chips = strings.Split(*chipList, ",")
+	}
+
+	if *raceID != 0 {
+		conn, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		race, err := queries.GetRaceByID(context.Background(), int32(*raceID))
+		if err != nil {
+			return errors.New("Race not found")
+		}
+		if *gunTime == "" {
+			if !race.GunTime.Valid {
+				return errors.New("The race has not started; start it or give -gun")
+			}
+			sim.Gun = race.GunTime.Time
+		}
+		if sim.Chips == nil {
+			assignments, err := queries.GetChipAssignmentsForMeet(context.Background(), race.MeetID)
+			if err != nil {
+				return err
+			}
+			for _, a := range assignments {
+				sim.Chips = append(sim.Chips, a.Chip)
+			}
+		}
+	}
+	if *gunTime != "" {
+		t, err := time.Parse("15:04:05", *gunTime)
+		if err != nil {
+			return errors.New("Invalid gun time format. Use HH:MM:SS")
+		}
+		today := wallClock(time.Now())
+		sim.Gun = time.Date(today.Year(), today.Month(), today.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	if len(sim.Chips) == 0 {
+		return errors.New("No chips to simulate")
+	}
+
+	conn, err := net.Dial("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	fmt.Printf("Sending reads for %d chips to %s\n", len(sim.Chips), *addr)
+	sent, err := sim.Run(conn)
+	fmt.Printf("%d reads sent\n", sent)
+	return err
+}
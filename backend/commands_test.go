@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCommandUsage checks that bad arguments are reported as usage errors,
+// before the database is needed
+func TestCommandUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func([]string) error
+		args []string
+	}{
+		{"import help", runImportCommand, []string{"-h"}},
+		{"import bad flag", runImportCommand, []string{"-bogus", "results.csv"}},
+		{"import without a meet", runImportCommand, []string{"results.csv"}},
+		{"import without a file", runImportCommand, []string{"-meet", "12"}},
+		{"timing-sim help", runTimingSimCommand, []string{"-help"}},
+		{"timing-sim without a race", runTimingSimCommand, []string{"-chips", "058003"}},
+	}
+	for _, tt := range tests {
+		if err := tt.run(tt.args); !errors.Is(err, errUsage) {
+			t.Errorf("%s: got %v, want a usage error", tt.name, err)
+		}
+	}
+}
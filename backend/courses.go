@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/racetime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// standardDistanceMeters is the 5K distance that an athlete's headline
+// personal record is quoted at
+const standardDistanceMeters = 5000
+
+// Surfaces a course can be run on
+const (
+	SurfaceGrass = "grass"
+	SurfaceTrail = "trail"
+	SurfaceMixed = "mixed"
+	SurfaceRoad  = "road"
+	SurfaceTrack = "track"
+)
+
+// validSurface reports whether surface is one of the known surfaces
+func validSurface(surface string) bool {
+	switch surface {
+	case SurfaceGrass, SurfaceTrail, SurfaceMixed, SurfaceRoad, SurfaceTrack:
+		return true
+	}
+	return false
+}
+
+type CourseResponse struct {
+	ID             int32  `json:"id"`
+	Name           string `json:"name"`
+	Venue          string `json:"venue"`
+	DistanceMeters int32  `json:"distanceMeters"`
+	Surface        string `json:"surface"`
+	Notes          string `json:"notes"`
+}
+
+func newCourseResponse(c db.Course) CourseResponse {
+	return CourseResponse{
+		ID:             c.ID,
+		Name:           c.Name,
+		Venue:          c.Venue,
+		DistanceMeters: c.DistanceMeters,
+		Surface:        c.Surface.String,
+		Notes:          c.Notes.String,
+	}
+}
+
+// formatPace returns the per-mile pace of a time over a distance, both
+// formatted and in milliseconds. Both are empty when the distance is unknown.
+func formatPace(timeMs int32, distanceMeters sql.NullInt32) (string, int32) {
+	if !distanceMeters.Valid {
+		return "", 0
+	}
+	ms := racetime.Pace(timeMs, distanceMeters.Int32)
+	return racetime.Format(ms), ms
+}
+
+// distanceFilter reads the optional "distance" query parameter, a race
+// distance in meters
+func distanceFilter(c *gin.Context) (sql.NullInt32, error) {
+	value := c.Query("distance")
+	if value == "" {
+		return sql.NullInt32{}, nil
+	}
+	meters, err := strconv.Atoi(value)
+	if err != nil || meters <= 0 {
+		return sql.NullInt32{}, fmt.Errorf("Invalid distance %q", value)
+	}
+	return sql.NullInt32{Int32: int32(meters), Valid: true}, nil
+}
+
+// meetCourse checks the course requested for a meet or race, if any
+func meetCourse(courseID int32) (sql.NullInt32, error) {
+	if courseID == 0 {
+		return sql.NullInt32{}, nil
+	}
+	if _, err := queries.GetCourseByID(context.Background(), courseID); err != nil {
+		return sql.NullInt32{}, errors.New("Course not found")
+	}
+	return sql.NullInt32{Int32: courseID, Valid: true}, nil
+}
+
+// registerCourseRoutes adds course routes to the public and coach groups
+func registerCourseRoutes(api, coaches *gin.RouterGroup) {
+	// Get all courses
+	api.GET("/courses", func(c *gin.Context) {
+		courses, err := queries.GetAllCourses(context.Background())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]CourseResponse, len(courses))
+		for i, course := range courses {
+			response[i] = newCourseResponse(course)
+		}
+		c.JSON(200, response)
+	})
+
+	// Get single course by ID
+	api.GET("/courses/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid course ID"})
+			return
+		}
+
+		course, err := queries.GetCourseByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Course not found"})
+			return
+		}
+
+		c.JSON(200, newCourseResponse(course))
+	})
+
+	// Create a new course
+	coaches.POST("/courses", func(c *gin.Context) {
+		var req struct {
+			Name           string `json:"name" binding:"required"`
+			Venue          string `json:"venue" binding:"required"`
+			DistanceMeters int32  `json:"distanceMeters" binding:"required"`
+			Surface        string `json:"surface"`
+			Notes          string `json:"notes"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := validateCourse(req.DistanceMeters, req.Surface); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := queries.CreateCourse(context.Background(), db.CreateCourseParams{
+			Name:           req.Name,
+			Venue:          req.Venue,
+			DistanceMeters: req.DistanceMeters,
+			Surface:        sql.NullString{String: req.Surface, Valid: req.Surface != ""},
+			Notes:          sql.NullString{String: req.Notes, Valid: req.Notes != ""},
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		id, _ := result.LastInsertId()
+		c.JSON(201, gin.H{"id": id, "message": "Course created"})
+	})
+
+	// Update a course. Changing its distance moves results between
+	// distances, so personal records are rebuilt. Splits recorded for the
+	// old distance are kept as they are.
+	coaches.PUT("/courses/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid course ID"})
+			return
+		}
+
+		var req struct {
+			Name           string `json:"name" binding:"required"`
+			Venue          string `json:"venue" binding:"required"`
+			DistanceMeters int32  `json:"distanceMeters" binding:"required"`
+			Surface        string `json:"surface"`
+			Notes          string `json:"notes"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := validateCourse(req.DistanceMeters, req.Surface); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = queries.UpdateCourse(context.Background(), db.UpdateCourseParams{
+			Name:           req.Name,
+			Venue:          req.Venue,
+			DistanceMeters: req.DistanceMeters,
+			Surface:        sql.NullString{String: req.Surface, Valid: req.Surface != ""},
+			Notes:          sql.NullString{String: req.Notes, Valid: req.Notes != ""},
+			ID:             int32(id),
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := rebuildPersonalRecords(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Course updated"})
+	})
+
+	// Delete a course (its meets are kept but no longer have a distance)
+	coaches.DELETE("/courses/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid course ID"})
+			return
+		}
+
+		err = queries.DeleteCourse(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := rebuildPersonalRecords(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Course deleted"})
+	})
+}
+
+// validateCourse checks a course's distance and optional surface
+func validateCourse(distanceMeters int32, surface string) error {
+	if distanceMeters <= 0 {
+		return errors.New("Distance must be a positive number of meters")
+	}
+	if surface != "" && !validSurface(surface) {
+		return errors.New("Surface must be grass, trail, mixed, road or track")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"jones-county-xc/backend/db"
+)
+
+func TestResultPaceByDistance(t *testing.T) {
+	tests := []struct {
+		distance sql.NullInt32
+		pace     string
+		paceMs   int32
+	}{
+		{sql.NullInt32{Int32: 5000, Valid: true}, "5:47.618", 347618},
+		{sql.NullInt32{Int32: 3200, Valid: true}, "9:03.154", 543154},
+		{sql.NullInt32{Int32: 1609, Valid: true}, "18:00.231", 1080231},
+		// Without a course there is no pace
+		{sql.NullInt32{}, "", 0},
+	}
+	for _, tt := range tests {
+		r := newResultResponse(db.GetResultsForMeetRow{
+			Status:         StatusFinished,
+			TimeMs:         sql.NullInt32{Int32: 1080000, Valid: true},
+			DistanceMeters: tt.distance,
+		})
+		if r.Pace != tt.pace || r.PaceMs != tt.paceMs || r.DistanceMeters != tt.distance.Int32 {
+			t.Errorf("18:00 over %v: got %s (%d) over %dm, want %s (%d)", tt.distance, r.Pace, r.PaceMs, r.DistanceMeters, tt.pace, tt.paceMs)
+		}
+	}
+}
+
+func TestTopTimesByDistance(t *testing.T) {
+	fake := useFakeDB(t)
+	var distance any
+	fake.rows["GetTopTimes"] = func(args []driver.NamedValue) [][]driver.Value {
+		distance = args[7].Value
+		return [][]driver.Value{{int64(1), int64(4), int64(1), int64(1), DivisionVarsity, StatusFinished, int64(1080000), int64(1), nil,
+			"Runner", GenderBoys, "Jones County Invitational", time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC), int64(5000)}}
+	}
+	r := newRouter()
+
+	w := serve(r, "GET", "/api/top-times?distance=5000", "")
+	if w.Code != 200 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	if distance != int64(5000) {
+		t.Errorf("filtered by distance %v, want 5000", distance)
+	}
+	var times []TopTimeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &times); err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 1 || times[0].DistanceMeters != 5000 || times[0].Pace != "5:47.618" {
+		t.Errorf("got %+v, want the 5K time with its pace", times)
+	}
+
+	distance = "unset"
+	serve(r, "GET", "/api/top-times", "")
+	if distance != nil {
+		t.Errorf("without a distance filtered by %v", distance)
+	}
+
+	for _, query := range []string{"0", "-5000", "5k", "5000.5"} {
+		if w := serve(r, "GET", "/api/top-times?distance="+query, ""); w.Code != 400 {
+			t.Errorf("distance %q: got %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestCreateCourseValidation(t *testing.T) {
+	fake := useFakeDB(t)
+	r := newRouter()
+	token := fake.login(RoleCoach)
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`{"name":"Jones County HS","venue":"Gray","distanceMeters":5000,"surface":"grass"}`, 201},
+		{`{"name":"Jones County HS","venue":"Gray","distanceMeters":3200}`, 201},
+		{`{"name":"Jones County HS","venue":"Gray","distanceMeters":-5000}`, 400},
+		{`{"name":"Jones County HS","venue":"Gray"}`, 400},
+		{`{"name":"Jones County HS","venue":"Gray","distanceMeters":5000,"surface":"sand"}`, 400},
+	}
+	for _, tt := range tests {
+		if w := serveJSON(r, "POST", "/api/courses", token, tt.body); w.Code != tt.code {
+			t.Errorf("%s: got %d, want %d: %s", tt.body, w.Code, tt.code, w.Body)
+		}
+	}
+}
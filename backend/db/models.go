@@ -10,16 +10,56 @@ import (
 )
 
 type Athlete struct {
+	ID        int32
+	Name      string
+	Grade     int8
+	Gender    sql.NullString
+	Events    sql.NullString
+	CreatedAt sql.NullTime
+}
+
+type BibAssignment struct {
+	ID           int32
+	MeetID       sql.NullInt32
+	SeasonID     sql.NullInt32
+	Bib          int32
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+	CreatedAt    sql.NullTime
+}
+
+type ChipAssignment struct {
+	ID           int32
+	MeetID       int32
+	Chip         string
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+	CreatedAt    sql.NullTime
+}
+
+type Competitor struct {
+	ID        int32
+	Name      string
+	SchoolID  sql.NullInt32
+	Gender    sql.NullString
+	Grade     sql.NullInt16
+	CreatedAt sql.NullTime
+}
+
+type Course struct {
 	ID             int32
 	Name           string
-	Grade          int8
-	PersonalRecord sql.NullString
-	Events         sql.NullString
+	Venue          string
+	DistanceMeters int32
+	Surface        sql.NullString
+	Notes          sql.NullString
 	CreatedAt      sql.NullTime
 }
 
 type Meet struct {
 	ID          int32
+	SeasonID    sql.NullInt32
+	CourseID    sql.NullInt32
 	Name        string
 	MeetDate    time.Time
 	Location    string
@@ -27,11 +67,99 @@ type Meet struct {
 	CreatedAt   sql.NullTime
 }
 
-type Result struct {
+type PersonalRecord struct {
+	ID             int32
+	AthleteID      int32
+	DistanceMeters sql.NullInt32
+	ResultID       int32
+	TimeMs         int32
+	CreatedAt      sql.NullTime
+}
+
+type Race struct {
 	ID        int32
-	AthleteID int32
 	MeetID    int32
-	Time      string
-	Place     sql.NullInt32
+	CourseID  sql.NullInt32
+	Name      string
+	Gender    sql.NullString
+	Division  string
+	StartTime sql.NullTime
+	GunTime   sql.NullTime
 	CreatedAt sql.NullTime
 }
+
+type Result struct {
+	ID           int32
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+	RaceID       int32
+	Status       string
+	TimeMs       sql.NullInt32
+	Place        sql.NullInt32
+	ClientID     sql.NullString
+	Version      int32
+	CreatedAt    sql.NullTime
+}
+
+type ResultSplit struct {
+	ID             int32
+	ResultID       int32
+	DistanceMeters int32
+	ElapsedMs      int32
+	CreatedAt      sql.NullTime
+}
+
+type School struct {
+	ID           int32
+	Name         string
+	Abbreviation sql.NullString
+	IsHome       bool
+	CreatedAt    sql.NullTime
+}
+
+type Season struct {
+	ID        int32
+	Year      int32
+	StartDate time.Time
+	EndDate   time.Time
+	IsActive  bool
+	CreatedAt sql.NullTime
+}
+
+type Session struct {
+	ID        int32
+	TokenHash string
+	UserID    int32
+	ExpiresAt time.Time
+	CreatedAt sql.NullTime
+}
+
+type SyncOperation struct {
+	ID        string
+	UserID    sql.NullInt32
+	Operation string
+	ResultID  int32
+	Version   int32
+	CreatedAt sql.NullTime
+}
+
+type TimedFinish struct {
+	ID           int32
+	RaceID       int32
+	Chip         string
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+	ElapsedMs    int32
+	ReadCount    int32
+	ResultID     sql.NullInt32
+	CreatedAt    sql.NullTime
+}
+
+type User struct {
+	ID           int32
+	Username     string
+	PasswordHash string
+	Role         string
+	Disabled     bool
+	CreatedAt    sql.NullTime
+}
@@ -11,117 +11,2003 @@ import (
 	"time"
 )
 
+const assignTimedFinish = `-- name: AssignTimedFinish :exec
+UPDATE timed_finishes SET athlete_id = ?, competitor_id = ? WHERE id = ?
+`
+
+type AssignTimedFinishParams struct {
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+	ID           int32
+}
+
+func (q *Queries) AssignTimedFinish(ctx context.Context, arg AssignTimedFinishParams) error {
+	_, err := q.db.ExecContext(ctx, assignTimedFinish, arg.AthleteID, arg.CompetitorID, arg.ID)
+	return err
+}
+
+const bumpRerankedResultVersions = `-- name: BumpRerankedResultVersions :exec
+UPDATE results r
+LEFT JOIN (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY time_ms, id) AS finish_place
+    FROM results
+    WHERE race_id = ? AND status IN ('finished', 'unattached')
+) ranked ON r.id = ranked.id
+SET r.version = r.version + 1
+WHERE r.race_id = ? AND NOT (r.place <=> ranked.finish_place)
+`
+
+func (q *Queries) BumpRerankedResultVersions(ctx context.Context, raceID int32) error {
+	_, err := q.db.ExecContext(ctx, bumpRerankedResultVersions, raceID, raceID)
+	return err
+}
+
+const confirmTimedFinish = `-- name: ConfirmTimedFinish :exec
+UPDATE timed_finishes SET result_id = ? WHERE id = ?
+`
+
+type ConfirmTimedFinishParams struct {
+	ResultID sql.NullInt32
+	ID       int32
+}
+
+func (q *Queries) ConfirmTimedFinish(ctx context.Context, arg ConfirmTimedFinishParams) error {
+	_, err := q.db.ExecContext(ctx, confirmTimedFinish, arg.ResultID, arg.ID)
+	return err
+}
+
+const countCompetitorResultsForRace = `-- name: CountCompetitorResultsForRace :one
+SELECT COUNT(*) FROM results WHERE race_id = ? AND competitor_id IS NOT NULL
+`
+
+func (q *Queries) CountCompetitorResultsForRace(ctx context.Context, raceID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompetitorResultsForRace, raceID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAllPersonalRecords = `-- name: CreateAllPersonalRecords :exec
+INSERT INTO personal_records (athlete_id, distance_meters, result_id, time_ms)
+SELECT athlete_id, distance_meters, id, time_ms
+FROM (
+    SELECT r.athlete_id, c.distance_meters, r.id, r.time_ms,
+           ROW_NUMBER() OVER (PARTITION BY r.athlete_id, c.distance_meters ORDER BY r.time_ms, m.meet_date, r.id) AS rank_at_distance
+    FROM results r
+    JOIN races ra ON r.race_id = ra.id
+    JOIN meets m ON ra.meet_id = m.id
+    LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+    WHERE r.athlete_id IS NOT NULL AND r.status IN ('finished', 'unattached')
+) ranked
+WHERE rank_at_distance = 1
+`
+
+func (q *Queries) CreateAllPersonalRecords(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, createAllPersonalRecords)
+	return err
+}
+
 const createAthlete = `-- name: CreateAthlete :execresult
-INSERT INTO athletes (name, grade, personal_record, events)
+INSERT INTO athletes (name, grade, gender, events)
 VALUES (?, ?, ?, ?)
 `
 
 type CreateAthleteParams struct {
-	Name           string
-	Grade          int8
-	PersonalRecord sql.NullString
-	Events         sql.NullString
+	Name   string
+	Grade  int8
+	Gender sql.NullString
+	Events sql.NullString
 }
 
 func (q *Queries) CreateAthlete(ctx context.Context, arg CreateAthleteParams) (sql.Result, error) {
 	return q.db.ExecContext(ctx, createAthlete,
 		arg.Name,
 		arg.Grade,
-		arg.PersonalRecord,
+		arg.Gender,
 		arg.Events,
 	)
 }
 
-const createMeet = `-- name: CreateMeet :execresult
-INSERT INTO meets (name, meet_date, location, description)
-VALUES (?, ?, ?, ?)
+const createBibAssignment = `-- name: CreateBibAssignment :exec
+INSERT INTO bib_assignments (meet_id, season_id, bib, athlete_id, competitor_id)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateBibAssignmentParams struct {
+	MeetID       sql.NullInt32
+	SeasonID     sql.NullInt32
+	Bib          int32
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+}
+
+func (q *Queries) CreateBibAssignment(ctx context.Context, arg CreateBibAssignmentParams) error {
+	_, err := q.db.ExecContext(ctx, createBibAssignment,
+		arg.MeetID,
+		arg.SeasonID,
+		arg.Bib,
+		arg.AthleteID,
+		arg.CompetitorID,
+	)
+	return err
+}
+
+const createChipAssignment = `-- name: CreateChipAssignment :exec
+INSERT INTO chip_assignments (meet_id, chip, athlete_id, competitor_id)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateChipAssignmentParams struct {
+	MeetID       int32
+	Chip         string
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+}
+
+func (q *Queries) CreateChipAssignment(ctx context.Context, arg CreateChipAssignmentParams) error {
+	_, err := q.db.ExecContext(ctx, createChipAssignment,
+		arg.MeetID,
+		arg.Chip,
+		arg.AthleteID,
+		arg.CompetitorID,
+	)
+	return err
+}
+
+const createCompetitor = `-- name: CreateCompetitor :execresult
+INSERT INTO competitors (name, school_id, gender, grade)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateCompetitorParams struct {
+	Name     string
+	SchoolID sql.NullInt32
+	Gender   sql.NullString
+	Grade    sql.NullInt16
+}
+
+func (q *Queries) CreateCompetitor(ctx context.Context, arg CreateCompetitorParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createCompetitor,
+		arg.Name,
+		arg.SchoolID,
+		arg.Gender,
+		arg.Grade,
+	)
+}
+
+const createCourse = `-- name: CreateCourse :execresult
+INSERT INTO courses (name, venue, distance_meters, surface, notes)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateCourseParams struct {
+	Name           string
+	Venue          string
+	DistanceMeters int32
+	Surface        sql.NullString
+	Notes          sql.NullString
+}
+
+func (q *Queries) CreateCourse(ctx context.Context, arg CreateCourseParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createCourse,
+		arg.Name,
+		arg.Venue,
+		arg.DistanceMeters,
+		arg.Surface,
+		arg.Notes,
+	)
+}
+
+const createHomeSchool = `-- name: CreateHomeSchool :exec
+INSERT INTO schools (name, is_home)
+VALUES (?, TRUE)
+ON DUPLICATE KEY UPDATE is_home = TRUE
+`
+
+func (q *Queries) CreateHomeSchool(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, createHomeSchool, name)
+	return err
+}
+
+const createMeet = `-- name: CreateMeet :execresult
+INSERT INTO meets (season_id, course_id, name, meet_date, location, description)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateMeetParams struct {
+	SeasonID    sql.NullInt32
+	CourseID    sql.NullInt32
+	Name        string
+	MeetDate    time.Time
+	Location    string
+	Description sql.NullString
+}
+
+func (q *Queries) CreateMeet(ctx context.Context, arg CreateMeetParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createMeet,
+		arg.SeasonID,
+		arg.CourseID,
+		arg.Name,
+		arg.MeetDate,
+		arg.Location,
+		arg.Description,
+	)
+}
+
+const createPersonalRecordsForAthlete = `-- name: CreatePersonalRecordsForAthlete :exec
+INSERT INTO personal_records (athlete_id, distance_meters, result_id, time_ms)
+SELECT athlete_id, distance_meters, id, time_ms
+FROM (
+    SELECT r.athlete_id, c.distance_meters, r.id, r.time_ms,
+           ROW_NUMBER() OVER (PARTITION BY c.distance_meters ORDER BY r.time_ms, m.meet_date, r.id) AS rank_at_distance
+    FROM results r
+    JOIN races ra ON r.race_id = ra.id
+    JOIN meets m ON ra.meet_id = m.id
+    LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+    WHERE r.athlete_id = ? AND r.status IN ('finished', 'unattached')
+) ranked
+WHERE rank_at_distance = 1
+`
+
+func (q *Queries) CreatePersonalRecordsForAthlete(ctx context.Context, athleteID sql.NullInt32) error {
+	_, err := q.db.ExecContext(ctx, createPersonalRecordsForAthlete, athleteID)
+	return err
+}
+
+const createRace = `-- name: CreateRace :execresult
+INSERT INTO races (meet_id, course_id, name, gender, division, start_time)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateRaceParams struct {
+	MeetID    int32
+	CourseID  sql.NullInt32
+	Name      string
+	Gender    sql.NullString
+	Division  string
+	StartTime sql.NullTime
+}
+
+func (q *Queries) CreateRace(ctx context.Context, arg CreateRaceParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createRace,
+		arg.MeetID,
+		arg.CourseID,
+		arg.Name,
+		arg.Gender,
+		arg.Division,
+		arg.StartTime,
+	)
+}
+
+const createResult = `-- name: CreateResult :execresult
+INSERT INTO results (athlete_id, competitor_id, race_id, status, time_ms, place, client_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateResultParams struct {
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+	RaceID       int32
+	Status       string
+	TimeMs       sql.NullInt32
+	Place        sql.NullInt32
+	ClientID     sql.NullString
+}
+
+func (q *Queries) CreateResult(ctx context.Context, arg CreateResultParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createResult,
+		arg.AthleteID,
+		arg.CompetitorID,
+		arg.RaceID,
+		arg.Status,
+		arg.TimeMs,
+		arg.Place,
+		arg.ClientID,
+	)
+}
+
+const createSchool = `-- name: CreateSchool :execresult
+INSERT INTO schools (name, abbreviation)
+VALUES (?, ?)
+`
+
+type CreateSchoolParams struct {
+	Name         string
+	Abbreviation sql.NullString
+}
+
+func (q *Queries) CreateSchool(ctx context.Context, arg CreateSchoolParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createSchool, arg.Name, arg.Abbreviation)
+}
+
+const createSeason = `-- name: CreateSeason :execresult
+INSERT INTO seasons (year, start_date, end_date)
+VALUES (?, ?, ?)
+`
+
+type CreateSeasonParams struct {
+	Year      int32
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (q *Queries) CreateSeason(ctx context.Context, arg CreateSeasonParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createSeason, arg.Year, arg.StartDate, arg.EndDate)
+}
+
+const createSession = `-- name: CreateSession :exec
+INSERT INTO sessions (token_hash, user_id, expires_at)
+VALUES (?, ?, ?)
+`
+
+type CreateSessionParams struct {
+	TokenHash string
+	UserID    int32
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, createSession, arg.TokenHash, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
+const createSplit = `-- name: CreateSplit :exec
+INSERT INTO result_splits (result_id, distance_meters, elapsed_ms)
+VALUES (?, ?, ?)
+`
+
+type CreateSplitParams struct {
+	ResultID       int32
+	DistanceMeters int32
+	ElapsedMs      int32
+}
+
+func (q *Queries) CreateSplit(ctx context.Context, arg CreateSplitParams) error {
+	_, err := q.db.ExecContext(ctx, createSplit, arg.ResultID, arg.DistanceMeters, arg.ElapsedMs)
+	return err
+}
+
+const createSyncOperation = `-- name: CreateSyncOperation :exec
+INSERT INTO sync_operations (id, user_id, operation, result_id, version)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateSyncOperationParams struct {
+	ID        string
+	UserID    sql.NullInt32
+	Operation string
+	ResultID  int32
+	Version   int32
+}
+
+func (q *Queries) CreateSyncOperation(ctx context.Context, arg CreateSyncOperationParams) error {
+	_, err := q.db.ExecContext(ctx, createSyncOperation,
+		arg.ID,
+		arg.UserID,
+		arg.Operation,
+		arg.ResultID,
+		arg.Version,
+	)
+	return err
+}
+
+const createUser = `-- name: CreateUser :execresult
+INSERT INTO users (username, password_hash, role)
+VALUES (?, ?, ?)
+`
+
+type CreateUserParams struct {
+	Username     string
+	PasswordHash string
+	Role         string
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createUser, arg.Username, arg.PasswordHash, arg.Role)
+}
+
+const deleteAllPersonalRecords = `-- name: DeleteAllPersonalRecords :exec
+DELETE FROM personal_records
+`
+
+func (q *Queries) DeleteAllPersonalRecords(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllPersonalRecords)
+	return err
+}
+
+const deleteAthlete = `-- name: DeleteAthlete :exec
+DELETE FROM athletes WHERE id = ?
+`
+
+func (q *Queries) DeleteAthlete(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAthlete, id)
+	return err
+}
+
+const deleteBibAssignmentsForMeet = `-- name: DeleteBibAssignmentsForMeet :exec
+DELETE FROM bib_assignments WHERE meet_id = ?
+`
+
+func (q *Queries) DeleteBibAssignmentsForMeet(ctx context.Context, meetID sql.NullInt32) error {
+	_, err := q.db.ExecContext(ctx, deleteBibAssignmentsForMeet, meetID)
+	return err
+}
+
+const deleteBibAssignmentsForSeason = `-- name: DeleteBibAssignmentsForSeason :exec
+DELETE FROM bib_assignments WHERE season_id = ?
+`
+
+func (q *Queries) DeleteBibAssignmentsForSeason(ctx context.Context, seasonID sql.NullInt32) error {
+	_, err := q.db.ExecContext(ctx, deleteBibAssignmentsForSeason, seasonID)
+	return err
+}
+
+const deleteChipAssignmentsForMeet = `-- name: DeleteChipAssignmentsForMeet :exec
+DELETE FROM chip_assignments WHERE meet_id = ?
+`
+
+func (q *Queries) DeleteChipAssignmentsForMeet(ctx context.Context, meetID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteChipAssignmentsForMeet, meetID)
+	return err
+}
+
+const deleteCompetitor = `-- name: DeleteCompetitor :exec
+DELETE FROM competitors WHERE id = ?
+`
+
+func (q *Queries) DeleteCompetitor(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteCompetitor, id)
+	return err
+}
+
+const deleteCourse = `-- name: DeleteCourse :exec
+DELETE FROM courses WHERE id = ?
+`
+
+func (q *Queries) DeleteCourse(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteCourse, id)
+	return err
+}
+
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredSessions, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMeet = `-- name: DeleteMeet :exec
+DELETE FROM meets WHERE id = ?
+`
+
+func (q *Queries) DeleteMeet(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteMeet, id)
+	return err
+}
+
+const deletePersonalRecordsForAthlete = `-- name: DeletePersonalRecordsForAthlete :exec
+DELETE FROM personal_records WHERE athlete_id = ?
+`
+
+func (q *Queries) DeletePersonalRecordsForAthlete(ctx context.Context, athleteID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePersonalRecordsForAthlete, athleteID)
+	return err
+}
+
+const deleteRace = `-- name: DeleteRace :exec
+DELETE FROM races WHERE id = ?
+`
+
+func (q *Queries) DeleteRace(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRace, id)
+	return err
+}
+
+const deleteResult = `-- name: DeleteResult :exec
+DELETE FROM results WHERE id = ?
+`
+
+func (q *Queries) DeleteResult(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteResult, id)
+	return err
+}
+
+const deleteResultVersion = `-- name: DeleteResultVersion :execrows
+DELETE FROM results WHERE id = ? AND version = ?
+`
+
+type DeleteResultVersionParams struct {
+	ID      int32
+	Version int32
+}
+
+func (q *Queries) DeleteResultVersion(ctx context.Context, arg DeleteResultVersionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteResultVersion, arg.ID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSchool = `-- name: DeleteSchool :exec
+DELETE FROM schools WHERE id = ?
+`
+
+func (q *Queries) DeleteSchool(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSchool, id)
+	return err
+}
+
+const deleteSeason = `-- name: DeleteSeason :exec
+DELETE FROM seasons WHERE id = ?
+`
+
+func (q *Queries) DeleteSeason(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSeason, id)
+	return err
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions WHERE token_hash = ?
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, tokenHash)
+	return err
+}
+
+const deleteSessionsForUser = `-- name: DeleteSessionsForUser :exec
+DELETE FROM sessions WHERE user_id = ?
+`
+
+func (q *Queries) DeleteSessionsForUser(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionsForUser, userID)
+	return err
+}
+
+const deleteSplitsForResult = `-- name: DeleteSplitsForResult :exec
+DELETE FROM result_splits WHERE result_id = ?
+`
+
+func (q *Queries) DeleteSplitsForResult(ctx context.Context, resultID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSplitsForResult, resultID)
+	return err
+}
+
+const deleteTimedFinish = `-- name: DeleteTimedFinish :exec
+DELETE FROM timed_finishes WHERE id = ?
+`
+
+func (q *Queries) DeleteTimedFinish(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteTimedFinish, id)
+	return err
+}
+
+const getActiveSeason = `-- name: GetActiveSeason :one
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+WHERE is_active = TRUE
+LIMIT 1
+`
+
+func (q *Queries) GetActiveSeason(ctx context.Context) (Season, error) {
+	row := q.db.QueryRowContext(ctx, getActiveSeason)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Year,
+		&i.StartDate,
+		&i.EndDate,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getActiveSession = `-- name: GetActiveSession :one
+SELECT s.id, s.token_hash, s.user_id, s.expires_at, s.created_at,
+       u.username, u.role
+FROM sessions s
+JOIN users u ON s.user_id = u.id
+WHERE s.token_hash = ? AND s.expires_at > ? AND u.disabled = FALSE
+`
+
+type GetActiveSessionParams struct {
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type GetActiveSessionRow struct {
+	ID        int32
+	TokenHash string
+	UserID    int32
+	ExpiresAt time.Time
+	CreatedAt sql.NullTime
+	Username  string
+	Role      string
+}
+
+func (q *Queries) GetActiveSession(ctx context.Context, arg GetActiveSessionParams) (GetActiveSessionRow, error) {
+	row := q.db.QueryRowContext(ctx, getActiveSession, arg.TokenHash, arg.ExpiresAt)
+	var i GetActiveSessionRow
+	err := row.Scan(
+		&i.ID,
+		&i.TokenHash,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.Username,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getAllAthletes = `-- name: GetAllAthletes :many
+SELECT id, name, grade, gender, events, created_at
+FROM athletes
+WHERE (? IS NULL OR gender = ?)
+ORDER BY name
+`
+
+func (q *Queries) GetAllAthletes(ctx context.Context, gender sql.NullString) ([]Athlete, error) {
+	rows, err := q.db.QueryContext(ctx, getAllAthletes, gender, gender)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Athlete
+	for rows.Next() {
+		var i Athlete
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Grade,
+			&i.Gender,
+			&i.Events,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllCompetitors = `-- name: GetAllCompetitors :many
+SELECT id, name, school_id, gender, grade, created_at
+FROM competitors
+WHERE (? IS NULL OR school_id = ?)
+ORDER BY name
+`
+
+func (q *Queries) GetAllCompetitors(ctx context.Context, schoolID sql.NullInt32) ([]Competitor, error) {
+	rows, err := q.db.QueryContext(ctx, getAllCompetitors, schoolID, schoolID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Competitor
+	for rows.Next() {
+		var i Competitor
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.SchoolID,
+			&i.Gender,
+			&i.Grade,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllCourses = `-- name: GetAllCourses :many
+SELECT id, name, venue, distance_meters, surface, notes, created_at
+FROM courses
+ORDER BY name
+`
+
+func (q *Queries) GetAllCourses(ctx context.Context) ([]Course, error) {
+	rows, err := q.db.QueryContext(ctx, getAllCourses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Course
+	for rows.Next() {
+		var i Course
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Venue,
+			&i.DistanceMeters,
+			&i.Surface,
+			&i.Notes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllMeets = `-- name: GetAllMeets :many
+SELECT id, season_id, course_id, name, meet_date, location, description, created_at
+FROM meets
+WHERE (? IS NULL OR season_id = ?)
+ORDER BY meet_date
+`
+
+func (q *Queries) GetAllMeets(ctx context.Context, seasonID sql.NullInt32) ([]Meet, error) {
+	rows, err := q.db.QueryContext(ctx, getAllMeets, seasonID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Meet
+	for rows.Next() {
+		var i Meet
+		if err := rows.Scan(
+			&i.ID,
+			&i.SeasonID,
+			&i.CourseID,
+			&i.Name,
+			&i.MeetDate,
+			&i.Location,
+			&i.Description,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllPersonalRecords = `-- name: GetAllPersonalRecords :many
+SELECT id, athlete_id, distance_meters, result_id, time_ms, created_at
+FROM personal_records
+ORDER BY athlete_id, distance_meters
+`
+
+func (q *Queries) GetAllPersonalRecords(ctx context.Context) ([]PersonalRecord, error) {
+	rows, err := q.db.QueryContext(ctx, getAllPersonalRecords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PersonalRecord
+	for rows.Next() {
+		var i PersonalRecord
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.DistanceMeters,
+			&i.ResultID,
+			&i.TimeMs,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllSchools = `-- name: GetAllSchools :many
+SELECT id, name, abbreviation, is_home, created_at
+FROM schools
+ORDER BY name
+`
+
+func (q *Queries) GetAllSchools(ctx context.Context) ([]School, error) {
+	rows, err := q.db.QueryContext(ctx, getAllSchools)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []School
+	for rows.Next() {
+		var i School
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Abbreviation,
+			&i.IsHome,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllSeasons = `-- name: GetAllSeasons :many
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+ORDER BY year DESC
+`
+
+func (q *Queries) GetAllSeasons(ctx context.Context) ([]Season, error) {
+	rows, err := q.db.QueryContext(ctx, getAllSeasons)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Season
+	for rows.Next() {
+		var i Season
+		if err := rows.Scan(
+			&i.ID,
+			&i.Year,
+			&i.StartDate,
+			&i.EndDate,
+			&i.IsActive,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllUsers = `-- name: GetAllUsers :many
+SELECT id, username, password_hash, role, disabled, created_at
+FROM users
+ORDER BY username
+`
+
+func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getAllUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.Role,
+			&i.Disabled,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAthleteByID = `-- name: GetAthleteByID :one
+SELECT id, name, grade, gender, events, created_at
+FROM athletes
+WHERE id = ?
+`
+
+func (q *Queries) GetAthleteByID(ctx context.Context, id int32) (Athlete, error) {
+	row := q.db.QueryRowContext(ctx, getAthleteByID, id)
+	var i Athlete
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Grade,
+		&i.Gender,
+		&i.Events,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBibAssignmentsForMeet = `-- name: GetBibAssignmentsForMeet :many
+SELECT b.bib, b.meet_id, b.athlete_id, b.competitor_id, a.name AS athlete_name, COALESCE(a.grade, co.grade) AS grade,
+       co.name AS competitor_name, s.name AS school_name
+FROM bib_assignments b
+JOIN meets m ON m.id = ?
+LEFT JOIN athletes a ON b.athlete_id = a.id
+LEFT JOIN competitors co ON b.competitor_id = co.id
+LEFT JOIN schools s ON co.school_id = s.id
+WHERE b.meet_id = m.id
+   OR (b.season_id = m.season_id AND NOT EXISTS (
+       SELECT 1 FROM bib_assignments o
+       WHERE o.meet_id = m.id
+         AND (o.athlete_id = b.athlete_id OR o.competitor_id = b.competitor_id)))
+ORDER BY b.bib, b.meet_id IS NULL
+`
+
+type GetBibAssignmentsForMeetRow struct {
+	Bib            int32
+	MeetID         sql.NullInt32
+	AthleteID      sql.NullInt32
+	CompetitorID   sql.NullInt32
+	AthleteName    sql.NullString
+	Grade          sql.NullInt16
+	CompetitorName sql.NullString
+	SchoolName     sql.NullString
+}
+
+func (q *Queries) GetBibAssignmentsForMeet(ctx context.Context, meetID int32) ([]GetBibAssignmentsForMeetRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBibAssignmentsForMeet, meetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBibAssignmentsForMeetRow
+	for rows.Next() {
+		var i GetBibAssignmentsForMeetRow
+		if err := rows.Scan(
+			&i.Bib,
+			&i.MeetID,
+			&i.AthleteID,
+			&i.CompetitorID,
+			&i.AthleteName,
+			&i.Grade,
+			&i.CompetitorName,
+			&i.SchoolName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBibAssignmentsForSeason = `-- name: GetBibAssignmentsForSeason :many
+SELECT b.bib, b.athlete_id, b.competitor_id, a.name AS athlete_name, co.name AS competitor_name
+FROM bib_assignments b
+LEFT JOIN athletes a ON b.athlete_id = a.id
+LEFT JOIN competitors co ON b.competitor_id = co.id
+WHERE b.season_id = ?
+ORDER BY b.bib
+`
+
+type GetBibAssignmentsForSeasonRow struct {
+	Bib            int32
+	AthleteID      sql.NullInt32
+	CompetitorID   sql.NullInt32
+	AthleteName    sql.NullString
+	CompetitorName sql.NullString
+}
+
+func (q *Queries) GetBibAssignmentsForSeason(ctx context.Context, seasonID sql.NullInt32) ([]GetBibAssignmentsForSeasonRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBibAssignmentsForSeason, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBibAssignmentsForSeasonRow
+	for rows.Next() {
+		var i GetBibAssignmentsForSeasonRow
+		if err := rows.Scan(
+			&i.Bib,
+			&i.AthleteID,
+			&i.CompetitorID,
+			&i.AthleteName,
+			&i.CompetitorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChipAssignment = `-- name: GetChipAssignment :one
+SELECT id, meet_id, chip, athlete_id, competitor_id, created_at
+FROM chip_assignments
+WHERE meet_id = ? AND chip = ?
+`
+
+type GetChipAssignmentParams struct {
+	MeetID int32
+	Chip   string
+}
+
+func (q *Queries) GetChipAssignment(ctx context.Context, arg GetChipAssignmentParams) (ChipAssignment, error) {
+	row := q.db.QueryRowContext(ctx, getChipAssignment, arg.MeetID, arg.Chip)
+	var i ChipAssignment
+	err := row.Scan(
+		&i.ID,
+		&i.MeetID,
+		&i.Chip,
+		&i.AthleteID,
+		&i.CompetitorID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getChipAssignmentsForMeet = `-- name: GetChipAssignmentsForMeet :many
+SELECT ca.id, ca.chip, ca.athlete_id, ca.competitor_id, a.name AS athlete_name, co.name AS competitor_name
+FROM chip_assignments ca
+LEFT JOIN athletes a ON ca.athlete_id = a.id
+LEFT JOIN competitors co ON ca.competitor_id = co.id
+WHERE ca.meet_id = ?
+ORDER BY ca.chip
+`
+
+type GetChipAssignmentsForMeetRow struct {
+	ID             int32
+	Chip           string
+	AthleteID      sql.NullInt32
+	CompetitorID   sql.NullInt32
+	AthleteName    sql.NullString
+	CompetitorName sql.NullString
+}
+
+func (q *Queries) GetChipAssignmentsForMeet(ctx context.Context, meetID int32) ([]GetChipAssignmentsForMeetRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChipAssignmentsForMeet, meetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChipAssignmentsForMeetRow
+	for rows.Next() {
+		var i GetChipAssignmentsForMeetRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Chip,
+			&i.AthleteID,
+			&i.CompetitorID,
+			&i.AthleteName,
+			&i.CompetitorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCompetitorByID = `-- name: GetCompetitorByID :one
+SELECT id, name, school_id, gender, grade, created_at
+FROM competitors
+WHERE id = ?
+`
+
+func (q *Queries) GetCompetitorByID(ctx context.Context, id int32) (Competitor, error) {
+	row := q.db.QueryRowContext(ctx, getCompetitorByID, id)
+	var i Competitor
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SchoolID,
+		&i.Gender,
+		&i.Grade,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCourseByID = `-- name: GetCourseByID :one
+SELECT id, name, venue, distance_meters, surface, notes, created_at
+FROM courses
+WHERE id = ?
+`
+
+func (q *Queries) GetCourseByID(ctx context.Context, id int32) (Course, error) {
+	row := q.db.QueryRowContext(ctx, getCourseByID, id)
+	var i Course
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Venue,
+		&i.DistanceMeters,
+		&i.Surface,
+		&i.Notes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getHomeSchool = `-- name: GetHomeSchool :one
+SELECT id, name, abbreviation, is_home, created_at
+FROM schools
+WHERE is_home
+ORDER BY id
+LIMIT 1
+`
+
+func (q *Queries) GetHomeSchool(ctx context.Context) (School, error) {
+	row := q.db.QueryRowContext(ctx, getHomeSchool)
+	var i School
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Abbreviation,
+		&i.IsHome,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMeetBibAssignmentsForSeason = `-- name: GetMeetBibAssignmentsForSeason :many
+SELECT b.meet_id, m.name AS meet_name, b.bib, b.athlete_id, b.competitor_id, a.name AS athlete_name, co.name AS competitor_name
+FROM bib_assignments b
+JOIN meets m ON m.id = b.meet_id
+LEFT JOIN athletes a ON b.athlete_id = a.id
+LEFT JOIN competitors co ON b.competitor_id = co.id
+WHERE m.season_id = ?
+ORDER BY m.meet_date, m.id, b.bib
+`
+
+type GetMeetBibAssignmentsForSeasonRow struct {
+	MeetID         sql.NullInt32
+	MeetName       string
+	Bib            int32
+	AthleteID      sql.NullInt32
+	CompetitorID   sql.NullInt32
+	AthleteName    sql.NullString
+	CompetitorName sql.NullString
+}
+
+func (q *Queries) GetMeetBibAssignmentsForSeason(ctx context.Context, seasonID sql.NullInt32) ([]GetMeetBibAssignmentsForSeasonRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMeetBibAssignmentsForSeason, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMeetBibAssignmentsForSeasonRow
+	for rows.Next() {
+		var i GetMeetBibAssignmentsForSeasonRow
+		if err := rows.Scan(
+			&i.MeetID,
+			&i.MeetName,
+			&i.Bib,
+			&i.AthleteID,
+			&i.CompetitorID,
+			&i.AthleteName,
+			&i.CompetitorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMeetByID = `-- name: GetMeetByID :one
+SELECT id, season_id, course_id, name, meet_date, location, description, created_at
+FROM meets
+WHERE id = ?
+`
+
+func (q *Queries) GetMeetByID(ctx context.Context, id int32) (Meet, error) {
+	row := q.db.QueryRowContext(ctx, getMeetByID, id)
+	var i Meet
+	err := row.Scan(
+		&i.ID,
+		&i.SeasonID,
+		&i.CourseID,
+		&i.Name,
+		&i.MeetDate,
+		&i.Location,
+		&i.Description,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMeetByNameDate = `-- name: GetMeetByNameDate :one
+SELECT id, season_id, course_id, name, meet_date, location, description, created_at
+FROM meets
+WHERE name = ? AND meet_date = ?
+ORDER BY id
+LIMIT 1
+`
+
+type GetMeetByNameDateParams struct {
+	Name     string
+	MeetDate time.Time
+}
+
+func (q *Queries) GetMeetByNameDate(ctx context.Context, arg GetMeetByNameDateParams) (Meet, error) {
+	row := q.db.QueryRowContext(ctx, getMeetByNameDate, arg.Name, arg.MeetDate)
+	var i Meet
+	err := row.Scan(
+		&i.ID,
+		&i.SeasonID,
+		&i.CourseID,
+		&i.Name,
+		&i.MeetDate,
+		&i.Location,
+		&i.Description,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPersonalRecordsForAthlete = `-- name: GetPersonalRecordsForAthlete :many
+SELECT id, athlete_id, distance_meters, result_id, time_ms, created_at
+FROM personal_records
+WHERE athlete_id = ?
+ORDER BY distance_meters
+`
+
+func (q *Queries) GetPersonalRecordsForAthlete(ctx context.Context, athleteID int32) ([]PersonalRecord, error) {
+	rows, err := q.db.QueryContext(ctx, getPersonalRecordsForAthlete, athleteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PersonalRecord
+	for rows.Next() {
+		var i PersonalRecord
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.DistanceMeters,
+			&i.ResultID,
+			&i.TimeMs,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRaceByID = `-- name: GetRaceByID :one
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE id = ?
+`
+
+func (q *Queries) GetRaceByID(ctx context.Context, id int32) (Race, error) {
+	row := q.db.QueryRowContext(ctx, getRaceByID, id)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.MeetID,
+		&i.CourseID,
+		&i.Name,
+		&i.Gender,
+		&i.Division,
+		&i.StartTime,
+		&i.GunTime,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRaceDistance = `-- name: GetRaceDistance :one
+SELECT c.distance_meters
+FROM races ra
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+WHERE ra.id = ?
+`
+
+func (q *Queries) GetRaceDistance(ctx context.Context, id int32) (sql.NullInt32, error) {
+	row := q.db.QueryRowContext(ctx, getRaceDistance, id)
+	var distanceMeters sql.NullInt32
+	err := row.Scan(&distanceMeters)
+	return distanceMeters, err
+}
+
+const getRaceForMeetDivision = `-- name: GetRaceForMeetDivision :one
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE meet_id = ? AND division = ? AND gender <=> ?
+ORDER BY id
+LIMIT 1
+`
+
+type GetRaceForMeetDivisionParams struct {
+	MeetID   int32
+	Division string
+	Gender   sql.NullString
+}
+
+func (q *Queries) GetRaceForMeetDivision(ctx context.Context, arg GetRaceForMeetDivisionParams) (Race, error) {
+	row := q.db.QueryRowContext(ctx, getRaceForMeetDivision, arg.MeetID, arg.Division, arg.Gender)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.MeetID,
+		&i.CourseID,
+		&i.Name,
+		&i.Gender,
+		&i.Division,
+		&i.StartTime,
+		&i.GunTime,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRaceForMeetName = `-- name: GetRaceForMeetName :one
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE meet_id = ? AND name = ?
+ORDER BY id
+LIMIT 1
+`
+
+type GetRaceForMeetNameParams struct {
+	MeetID int32
+	Name   string
+}
+
+func (q *Queries) GetRaceForMeetName(ctx context.Context, arg GetRaceForMeetNameParams) (Race, error) {
+	row := q.db.QueryRowContext(ctx, getRaceForMeetName, arg.MeetID, arg.Name)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.MeetID,
+		&i.CourseID,
+		&i.Name,
+		&i.Gender,
+		&i.Division,
+		&i.StartTime,
+		&i.GunTime,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRacesForCompetitor = `-- name: GetRacesForCompetitor :many
+SELECT DISTINCT ra.id, ra.meet_id, ra.course_id, ra.name, ra.gender, ra.division, ra.start_time, ra.gun_time, ra.created_at
+FROM races ra
+JOIN results r ON r.race_id = ra.id
+WHERE r.competitor_id = ?
+ORDER BY ra.id
+`
+
+func (q *Queries) GetRacesForCompetitor(ctx context.Context, competitorID sql.NullInt32) ([]Race, error) {
+	rows, err := q.db.QueryContext(ctx, getRacesForCompetitor, competitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Race
+	for rows.Next() {
+		var i Race
+		if err := rows.Scan(
+			&i.ID,
+			&i.MeetID,
+			&i.CourseID,
+			&i.Name,
+			&i.Gender,
+			&i.Division,
+			&i.StartTime,
+			&i.GunTime,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRacesForMeet = `-- name: GetRacesForMeet :many
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE meet_id = ?
+ORDER BY start_time, id
+`
+
+func (q *Queries) GetRacesForMeet(ctx context.Context, meetID int32) ([]Race, error) {
+	rows, err := q.db.QueryContext(ctx, getRacesForMeet, meetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Race
+	for rows.Next() {
+		var i Race
+		if err := rows.Scan(
+			&i.ID,
+			&i.MeetID,
+			&i.CourseID,
+			&i.Name,
+			&i.Gender,
+			&i.Division,
+			&i.StartTime,
+			&i.GunTime,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRacesForSeason = `-- name: GetRacesForSeason :many
+SELECT ra.id, ra.meet_id, ra.course_id, ra.name, ra.gender, ra.division, ra.start_time, ra.gun_time, ra.created_at
+FROM races ra
+JOIN meets m ON m.id = ra.meet_id
+WHERE (? IS NULL OR m.season_id = ?)
+ORDER BY ra.start_time, ra.id
+`
+
+func (q *Queries) GetRacesForSeason(ctx context.Context, seasonID sql.NullInt32) ([]Race, error) {
+	rows, err := q.db.QueryContext(ctx, getRacesForSeason, seasonID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Race
+	for rows.Next() {
+		var i Race
+		if err := rows.Scan(
+			&i.ID,
+			&i.MeetID,
+			&i.CourseID,
+			&i.Name,
+			&i.Gender,
+			&i.Division,
+			&i.StartTime,
+			&i.GunTime,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRacesStartedBetween = `-- name: GetRacesStartedBetween :many
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE gun_time >= ? AND gun_time < ?
+ORDER BY gun_time DESC, id DESC
+`
+
+type GetRacesStartedBetweenParams struct {
+	Since sql.NullTime
+	Until sql.NullTime
+}
+
+func (q *Queries) GetRacesStartedBetween(ctx context.Context, arg GetRacesStartedBetweenParams) ([]Race, error) {
+	rows, err := q.db.QueryContext(ctx, getRacesStartedBetween, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Race
+	for rows.Next() {
+		var i Race
+		if err := rows.Scan(
+			&i.ID,
+			&i.MeetID,
+			&i.CourseID,
+			&i.Name,
+			&i.Gender,
+			&i.Division,
+			&i.StartTime,
+			&i.GunTime,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResultByClientID = `-- name: GetResultByClientID :one
+SELECT id, athlete_id, competitor_id, race_id, status, time_ms, place, client_id, version, created_at
+FROM results
+WHERE client_id = ?
+`
+
+func (q *Queries) GetResultByClientID(ctx context.Context, clientID sql.NullString) (Result, error) {
+	row := q.db.QueryRowContext(ctx, getResultByClientID, clientID)
+	var i Result
+	err := row.Scan(
+		&i.ID,
+		&i.AthleteID,
+		&i.CompetitorID,
+		&i.RaceID,
+		&i.Status,
+		&i.TimeMs,
+		&i.Place,
+		&i.ClientID,
+		&i.Version,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getResultByID = `-- name: GetResultByID :one
+SELECT id, athlete_id, competitor_id, race_id, status, time_ms, place, client_id, version, created_at
+FROM results
+WHERE id = ?
+`
+
+func (q *Queries) GetResultByID(ctx context.Context, id int32) (Result, error) {
+	row := q.db.QueryRowContext(ctx, getResultByID, id)
+	var i Result
+	err := row.Scan(
+		&i.ID,
+		&i.AthleteID,
+		&i.CompetitorID,
+		&i.RaceID,
+		&i.Status,
+		&i.TimeMs,
+		&i.Place,
+		&i.ClientID,
+		&i.Version,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getResultsForAthlete = `-- name: GetResultsForAthlete :many
+SELECT r.id, r.athlete_id, r.race_id, ra.meet_id, ra.division, r.status, r.time_ms, r.place, r.created_at,
+       ra.name as race_name, m.name as meet_name, m.meet_date, m.location as meet_location,
+       m.season_id, s.year as season_year, c.distance_meters
+FROM results r
+JOIN races ra ON r.race_id = ra.id
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN seasons s ON m.season_id = s.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+WHERE r.athlete_id = ?
+ORDER BY m.meet_date, r.id
+`
+
+type GetResultsForAthleteRow struct {
+	ID             int32
+	AthleteID      sql.NullInt32
+	RaceID         int32
+	MeetID         int32
+	Division       string
+	Status         string
+	TimeMs         sql.NullInt32
+	Place          sql.NullInt32
+	CreatedAt      sql.NullTime
+	RaceName       string
+	MeetName       string
+	MeetDate       time.Time
+	MeetLocation   string
+	SeasonID       sql.NullInt32
+	SeasonYear     sql.NullInt32
+	DistanceMeters sql.NullInt32
+}
+
+func (q *Queries) GetResultsForAthlete(ctx context.Context, athleteID sql.NullInt32) ([]GetResultsForAthleteRow, error) {
+	rows, err := q.db.QueryContext(ctx, getResultsForAthlete, athleteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetResultsForAthleteRow
+	for rows.Next() {
+		var i GetResultsForAthleteRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.RaceID,
+			&i.MeetID,
+			&i.Division,
+			&i.Status,
+			&i.TimeMs,
+			&i.Place,
+			&i.CreatedAt,
+			&i.RaceName,
+			&i.MeetName,
+			&i.MeetDate,
+			&i.MeetLocation,
+			&i.SeasonID,
+			&i.SeasonYear,
+			&i.DistanceMeters,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResultsForMeet = `-- name: GetResultsForMeet :many
+SELECT r.id, r.athlete_id, r.competitor_id, r.race_id, ra.meet_id, ra.division, r.status, r.time_ms, r.place, r.client_id, r.version, r.created_at,
+       COALESCE(a.name, co.name, '') as athlete_name, COALESCE(a.gender, co.gender) as athlete_gender,
+       sc.name as school_name, c.distance_meters, pr.result_id as pr_result_id
+FROM results r
+LEFT JOIN athletes a ON r.athlete_id = a.id
+LEFT JOIN competitors co ON r.competitor_id = co.id
+LEFT JOIN schools sc ON co.school_id = sc.id
+JOIN races ra ON r.race_id = ra.id
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+LEFT JOIN personal_records pr ON pr.result_id = r.id
+WHERE ra.meet_id = ?
+  AND (? IS NULL OR a.gender = ? OR co.gender = ?)
+  AND (? IS NULL OR ra.division = ?)
+ORDER BY ra.start_time, ra.id, r.place IS NULL, r.place, r.time_ms
+`
+
+type GetResultsForMeetParams struct {
+	MeetID   int32
+	Gender   sql.NullString
+	Division sql.NullString
+}
+
+type GetResultsForMeetRow struct {
+	ID             int32
+	AthleteID      sql.NullInt32
+	CompetitorID   sql.NullInt32
+	RaceID         int32
+	MeetID         int32
+	Division       string
+	Status         string
+	TimeMs         sql.NullInt32
+	Place          sql.NullInt32
+	ClientID       sql.NullString
+	Version        int32
+	CreatedAt      sql.NullTime
+	AthleteName    string
+	AthleteGender  sql.NullString
+	SchoolName     sql.NullString
+	DistanceMeters sql.NullInt32
+	PrResultID     sql.NullInt32
+}
+
+func (q *Queries) GetResultsForMeet(ctx context.Context, arg GetResultsForMeetParams) ([]GetResultsForMeetRow, error) {
+	rows, err := q.db.QueryContext(ctx, getResultsForMeet,
+		arg.MeetID,
+		arg.Gender,
+		arg.Gender,
+		arg.Gender,
+		arg.Division,
+		arg.Division,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetResultsForMeetRow
+	for rows.Next() {
+		var i GetResultsForMeetRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.CompetitorID,
+			&i.RaceID,
+			&i.MeetID,
+			&i.Division,
+			&i.Status,
+			&i.TimeMs,
+			&i.Place,
+			&i.ClientID,
+			&i.Version,
+			&i.CreatedAt,
+			&i.AthleteName,
+			&i.AthleteGender,
+			&i.SchoolName,
+			&i.DistanceMeters,
+			&i.PrResultID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResultsForRace = `-- name: GetResultsForRace :many
+SELECT r.id, r.athlete_id, r.competitor_id, r.race_id, ra.meet_id, ra.division, r.status, r.time_ms, r.place, r.client_id, r.version, r.created_at,
+       COALESCE(a.name, co.name, '') as athlete_name, COALESCE(a.gender, co.gender) as athlete_gender,
+       sc.name as school_name, c.distance_meters, pr.result_id as pr_result_id
+FROM results r
+LEFT JOIN athletes a ON r.athlete_id = a.id
+LEFT JOIN competitors co ON r.competitor_id = co.id
+LEFT JOIN schools sc ON co.school_id = sc.id
+JOIN races ra ON r.race_id = ra.id
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+LEFT JOIN personal_records pr ON pr.result_id = r.id
+WHERE r.race_id = ?
+ORDER BY r.place IS NULL, r.place, r.time_ms
+`
+
+type GetResultsForRaceRow struct {
+	ID             int32
+	AthleteID      sql.NullInt32
+	CompetitorID   sql.NullInt32
+	RaceID         int32
+	MeetID         int32
+	Division       string
+	Status         string
+	TimeMs         sql.NullInt32
+	Place          sql.NullInt32
+	ClientID       sql.NullString
+	Version        int32
+	CreatedAt      sql.NullTime
+	AthleteName    string
+	AthleteGender  sql.NullString
+	SchoolName     sql.NullString
+	DistanceMeters sql.NullInt32
+	PrResultID     sql.NullInt32
+}
+
+func (q *Queries) GetResultsForRace(ctx context.Context, raceID int32) ([]GetResultsForRaceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getResultsForRace, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetResultsForRaceRow
+	for rows.Next() {
+		var i GetResultsForRaceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AthleteID,
+			&i.CompetitorID,
+			&i.RaceID,
+			&i.MeetID,
+			&i.Division,
+			&i.Status,
+			&i.TimeMs,
+			&i.Place,
+			&i.ClientID,
+			&i.Version,
+			&i.CreatedAt,
+			&i.AthleteName,
+			&i.AthleteGender,
+			&i.SchoolName,
+			&i.DistanceMeters,
+			&i.PrResultID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSchoolByID = `-- name: GetSchoolByID :one
+SELECT id, name, abbreviation, is_home, created_at
+FROM schools
+WHERE id = ?
 `
 
-type CreateMeetParams struct {
-	Name        string
-	MeetDate    time.Time
-	Location    string
-	Description sql.NullString
-}
-
-func (q *Queries) CreateMeet(ctx context.Context, arg CreateMeetParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, createMeet,
-		arg.Name,
-		arg.MeetDate,
-		arg.Location,
-		arg.Description,
+func (q *Queries) GetSchoolByID(ctx context.Context, id int32) (School, error) {
+	row := q.db.QueryRowContext(ctx, getSchoolByID, id)
+	var i School
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Abbreviation,
+		&i.IsHome,
+		&i.CreatedAt,
 	)
+	return i, err
 }
 
-const createResult = `-- name: CreateResult :execresult
-INSERT INTO results (athlete_id, meet_id, time, place)
-VALUES (?, ?, ?, ?)
+const getSeasonByID = `-- name: GetSeasonByID :one
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+WHERE id = ?
 `
 
-type CreateResultParams struct {
-	AthleteID int32
-	MeetID    int32
-	Time      string
-	Place     sql.NullInt32
-}
-
-func (q *Queries) CreateResult(ctx context.Context, arg CreateResultParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, createResult,
-		arg.AthleteID,
-		arg.MeetID,
-		arg.Time,
-		arg.Place,
+func (q *Queries) GetSeasonByID(ctx context.Context, id int32) (Season, error) {
+	row := q.db.QueryRowContext(ctx, getSeasonByID, id)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Year,
+		&i.StartDate,
+		&i.EndDate,
+		&i.IsActive,
+		&i.CreatedAt,
 	)
+	return i, err
 }
 
-const deleteAthlete = `-- name: DeleteAthlete :exec
-DELETE FROM athletes WHERE id = ?
-`
-
-func (q *Queries) DeleteAthlete(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteAthlete, id)
-	return err
-}
-
-const deleteMeet = `-- name: DeleteMeet :exec
-DELETE FROM meets WHERE id = ?
+const getSeasonByYear = `-- name: GetSeasonByYear :one
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+WHERE year = ?
 `
 
-func (q *Queries) DeleteMeet(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteMeet, id)
-	return err
+func (q *Queries) GetSeasonByYear(ctx context.Context, year int32) (Season, error) {
+	row := q.db.QueryRowContext(ctx, getSeasonByYear, year)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Year,
+		&i.StartDate,
+		&i.EndDate,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return i, err
 }
 
-const deleteResult = `-- name: DeleteResult :exec
-DELETE FROM results WHERE id = ?
+const getSeasonForDate = `-- name: GetSeasonForDate :one
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+WHERE start_date <= ? AND end_date >= ?
+ORDER BY start_date DESC
+LIMIT 1
 `
 
-func (q *Queries) DeleteResult(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteResult, id)
-	return err
+func (q *Queries) GetSeasonForDate(ctx context.Context, meetDate time.Time) (Season, error) {
+	row := q.db.QueryRowContext(ctx, getSeasonForDate, meetDate, meetDate)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Year,
+		&i.StartDate,
+		&i.EndDate,
+		&i.IsActive,
+		&i.CreatedAt,
+	)
+	return i, err
 }
 
-const getAllAthletes = `-- name: GetAllAthletes :many
-SELECT id, name, grade, personal_record, events, created_at
-FROM athletes
-ORDER BY name
+const getSplitsForAthlete = `-- name: GetSplitsForAthlete :many
+SELECT s.id, s.result_id, s.distance_meters, s.elapsed_ms, s.created_at
+FROM result_splits s
+JOIN results r ON s.result_id = r.id
+WHERE r.athlete_id = ?
+ORDER BY s.result_id, s.distance_meters
 `
 
-func (q *Queries) GetAllAthletes(ctx context.Context) ([]Athlete, error) {
-	rows, err := q.db.QueryContext(ctx, getAllAthletes)
+func (q *Queries) GetSplitsForAthlete(ctx context.Context, athleteID sql.NullInt32) ([]ResultSplit, error) {
+	rows, err := q.db.QueryContext(ctx, getSplitsForAthlete, athleteID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Athlete
+	var items []ResultSplit
 	for rows.Next() {
-		var i Athlete
+		var i ResultSplit
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Grade,
-			&i.PersonalRecord,
-			&i.Events,
+			&i.ResultID,
+			&i.DistanceMeters,
+			&i.ElapsedMs,
 			&i.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -137,27 +2023,27 @@ func (q *Queries) GetAllAthletes(ctx context.Context) ([]Athlete, error) {
 	return items, nil
 }
 
-const getAllMeets = `-- name: GetAllMeets :many
-SELECT id, name, meet_date, location, description, created_at
-FROM meets
-ORDER BY meet_date
+const getSplitsForResult = `-- name: GetSplitsForResult :many
+SELECT id, result_id, distance_meters, elapsed_ms, created_at
+FROM result_splits
+WHERE result_id = ?
+ORDER BY distance_meters
 `
 
-func (q *Queries) GetAllMeets(ctx context.Context) ([]Meet, error) {
-	rows, err := q.db.QueryContext(ctx, getAllMeets)
+func (q *Queries) GetSplitsForResult(ctx context.Context, resultID int32) ([]ResultSplit, error) {
+	rows, err := q.db.QueryContext(ctx, getSplitsForResult, resultID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Meet
+	var items []ResultSplit
 	for rows.Next() {
-		var i Meet
+		var i ResultSplit
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.MeetDate,
-			&i.Location,
-			&i.Description,
+			&i.ResultID,
+			&i.DistanceMeters,
+			&i.ElapsedMs,
 			&i.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -173,82 +2059,90 @@ func (q *Queries) GetAllMeets(ctx context.Context) ([]Meet, error) {
 	return items, nil
 }
 
-const getAthleteByID = `-- name: GetAthleteByID :one
-SELECT id, name, grade, personal_record, events, created_at
-FROM athletes
+const getSyncOperation = `-- name: GetSyncOperation :one
+SELECT id, user_id, operation, result_id, version, created_at
+FROM sync_operations
 WHERE id = ?
 `
 
-func (q *Queries) GetAthleteByID(ctx context.Context, id int32) (Athlete, error) {
-	row := q.db.QueryRowContext(ctx, getAthleteByID, id)
-	var i Athlete
+func (q *Queries) GetSyncOperation(ctx context.Context, id string) (SyncOperation, error) {
+	row := q.db.QueryRowContext(ctx, getSyncOperation, id)
+	var i SyncOperation
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Grade,
-		&i.PersonalRecord,
-		&i.Events,
+		&i.UserID,
+		&i.Operation,
+		&i.ResultID,
+		&i.Version,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getMeetByID = `-- name: GetMeetByID :one
-SELECT id, name, meet_date, location, description, created_at
-FROM meets
+const getTimedFinishByID = `-- name: GetTimedFinishByID :one
+SELECT id, race_id, chip, athlete_id, competitor_id, elapsed_ms, read_count, result_id, created_at
+FROM timed_finishes
 WHERE id = ?
 `
 
-func (q *Queries) GetMeetByID(ctx context.Context, id int32) (Meet, error) {
-	row := q.db.QueryRowContext(ctx, getMeetByID, id)
-	var i Meet
+func (q *Queries) GetTimedFinishByID(ctx context.Context, id int32) (TimedFinish, error) {
+	row := q.db.QueryRowContext(ctx, getTimedFinishByID, id)
+	var i TimedFinish
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.MeetDate,
-		&i.Location,
-		&i.Description,
+		&i.RaceID,
+		&i.Chip,
+		&i.AthleteID,
+		&i.CompetitorID,
+		&i.ElapsedMs,
+		&i.ReadCount,
+		&i.ResultID,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getResultsForMeet = `-- name: GetResultsForMeet :many
-SELECT r.id, r.athlete_id, r.meet_id, r.time, r.place, r.created_at,
-       a.name as athlete_name
-FROM results r
-JOIN athletes a ON r.athlete_id = a.id
-WHERE r.meet_id = ?
-ORDER BY r.place
+const getTimedFinishesForRace = `-- name: GetTimedFinishesForRace :many
+SELECT tf.id, tf.chip, tf.athlete_id, tf.competitor_id, tf.elapsed_ms, tf.read_count, tf.result_id,
+       a.name AS athlete_name, co.name AS competitor_name
+FROM timed_finishes tf
+LEFT JOIN athletes a ON tf.athlete_id = a.id
+LEFT JOIN competitors co ON tf.competitor_id = co.id
+WHERE tf.race_id = ?
+ORDER BY tf.elapsed_ms, tf.id
 `
 
-type GetResultsForMeetRow struct {
-	ID          int32
-	AthleteID   int32
-	MeetID      int32
-	Time        string
-	Place       sql.NullInt32
-	CreatedAt   sql.NullTime
-	AthleteName string
+type GetTimedFinishesForRaceRow struct {
+	ID             int32
+	Chip           string
+	AthleteID      sql.NullInt32
+	CompetitorID   sql.NullInt32
+	ElapsedMs      int32
+	ReadCount      int32
+	ResultID       sql.NullInt32
+	AthleteName    sql.NullString
+	CompetitorName sql.NullString
 }
 
-func (q *Queries) GetResultsForMeet(ctx context.Context, meetID int32) ([]GetResultsForMeetRow, error) {
-	rows, err := q.db.QueryContext(ctx, getResultsForMeet, meetID)
+func (q *Queries) GetTimedFinishesForRace(ctx context.Context, raceID int32) ([]GetTimedFinishesForRaceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimedFinishesForRace, raceID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetResultsForMeetRow
+	var items []GetTimedFinishesForRaceRow
 	for rows.Next() {
-		var i GetResultsForMeetRow
+		var i GetTimedFinishesForRaceRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.Chip,
 			&i.AthleteID,
-			&i.MeetID,
-			&i.Time,
-			&i.Place,
-			&i.CreatedAt,
+			&i.CompetitorID,
+			&i.ElapsedMs,
+			&i.ReadCount,
+			&i.ResultID,
 			&i.AthleteName,
+			&i.CompetitorName,
 		); err != nil {
 			return nil, err
 		}
@@ -264,29 +2158,58 @@ func (q *Queries) GetResultsForMeet(ctx context.Context, meetID int32) ([]GetRes
 }
 
 const getTopTimes = `-- name: GetTopTimes :many
-SELECT r.id, r.athlete_id, r.meet_id, r.time, r.place, r.created_at,
-       a.name as athlete_name, m.name as meet_name, m.meet_date
+SELECT r.id, r.athlete_id, r.race_id, ra.meet_id, ra.division, r.status, r.time_ms, r.place, r.created_at,
+       a.name as athlete_name, a.gender as athlete_gender, m.name as meet_name, m.meet_date,
+       c.distance_meters
 FROM results r
 JOIN athletes a ON r.athlete_id = a.id
-JOIN meets m ON r.meet_id = m.id
-ORDER BY r.time
+JOIN races ra ON r.race_id = ra.id
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+WHERE (? IS NULL OR a.gender = ?)
+  AND (? IS NULL OR ra.division = ?)
+  AND (? IS NULL OR m.season_id = ?)
+  AND (? IS NULL OR c.distance_meters = ?)
+  AND r.status IN ('finished', 'unattached')
+ORDER BY r.time_ms
 LIMIT 10
 `
 
+type GetTopTimesParams struct {
+	Gender         sql.NullString
+	Division       sql.NullString
+	SeasonID       sql.NullInt32
+	DistanceMeters sql.NullInt32
+}
+
 type GetTopTimesRow struct {
-	ID          int32
-	AthleteID   int32
-	MeetID      int32
-	Time        string
-	Place       sql.NullInt32
-	CreatedAt   sql.NullTime
-	AthleteName string
-	MeetName    string
-	MeetDate    time.Time
+	ID             int32
+	AthleteID      sql.NullInt32
+	RaceID         int32
+	MeetID         int32
+	Division       string
+	Status         string
+	TimeMs         sql.NullInt32
+	Place          sql.NullInt32
+	CreatedAt      sql.NullTime
+	AthleteName    string
+	AthleteGender  sql.NullString
+	MeetName       string
+	MeetDate       time.Time
+	DistanceMeters sql.NullInt32
 }
 
-func (q *Queries) GetTopTimes(ctx context.Context) ([]GetTopTimesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTopTimes)
+func (q *Queries) GetTopTimes(ctx context.Context, arg GetTopTimesParams) ([]GetTopTimesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTopTimes,
+		arg.Gender,
+		arg.Gender,
+		arg.Division,
+		arg.Division,
+		arg.SeasonID,
+		arg.SeasonID,
+		arg.DistanceMeters,
+		arg.DistanceMeters,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -297,13 +2220,18 @@ func (q *Queries) GetTopTimes(ctx context.Context) ([]GetTopTimesRow, error) {
 		if err := rows.Scan(
 			&i.ID,
 			&i.AthleteID,
+			&i.RaceID,
 			&i.MeetID,
-			&i.Time,
+			&i.Division,
+			&i.Status,
+			&i.TimeMs,
 			&i.Place,
 			&i.CreatedAt,
 			&i.AthleteName,
+			&i.AthleteGender,
 			&i.MeetName,
 			&i.MeetDate,
+			&i.DistanceMeters,
 		); err != nil {
 			return nil, err
 		}
@@ -318,38 +2246,191 @@ func (q *Queries) GetTopTimes(ctx context.Context) ([]GetTopTimesRow, error) {
 	return items, nil
 }
 
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, username, password_hash, role, disabled, created_at
+FROM users
+WHERE id = ?
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Role,
+		&i.Disabled,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, username, password_hash, role, disabled, created_at
+FROM users
+WHERE username = ?
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Role,
+		&i.Disabled,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const incrementResultVersion = `-- name: IncrementResultVersion :exec
+UPDATE results SET version = version + 1 WHERE id = ?
+`
+
+func (q *Queries) IncrementResultVersion(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, incrementResultVersion, id)
+	return err
+}
+
+const recordTimedFinish = `-- name: RecordTimedFinish :exec
+INSERT INTO timed_finishes (race_id, chip, athlete_id, competitor_id, elapsed_ms)
+VALUES (?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    read_count = read_count + 1,
+    elapsed_ms = IF(result_id IS NULL, LEAST(elapsed_ms, VALUES(elapsed_ms)), elapsed_ms)
+`
+
+type RecordTimedFinishParams struct {
+	RaceID       int32
+	Chip         string
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+	ElapsedMs    int32
+}
+
+func (q *Queries) RecordTimedFinish(ctx context.Context, arg RecordTimedFinishParams) error {
+	_, err := q.db.ExecContext(ctx, recordTimedFinish,
+		arg.RaceID,
+		arg.Chip,
+		arg.AthleteID,
+		arg.CompetitorID,
+		arg.ElapsedMs,
+	)
+	return err
+}
+
+const setActiveSeason = `-- name: SetActiveSeason :exec
+UPDATE seasons SET is_active = (id = ?)
+`
+
+func (q *Queries) SetActiveSeason(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, setActiveSeason, id)
+	return err
+}
+
+const setUserDisabled = `-- name: SetUserDisabled :exec
+UPDATE users SET disabled = ? WHERE id = ?
+`
+
+type SetUserDisabledParams struct {
+	Disabled bool
+	ID       int32
+}
+
+func (q *Queries) SetUserDisabled(ctx context.Context, arg SetUserDisabledParams) error {
+	_, err := q.db.ExecContext(ctx, setUserDisabled, arg.Disabled, arg.ID)
+	return err
+}
+
 const updateAthlete = `-- name: UpdateAthlete :exec
 UPDATE athletes
-SET name = ?, grade = ?, personal_record = ?, events = ?
+SET name = ?, grade = ?, gender = ?, events = ?
 WHERE id = ?
 `
 
 type UpdateAthleteParams struct {
-	Name           string
-	Grade          int8
-	PersonalRecord sql.NullString
-	Events         sql.NullString
-	ID             int32
+	Name   string
+	Grade  int8
+	Gender sql.NullString
+	Events sql.NullString
+	ID     int32
 }
 
 func (q *Queries) UpdateAthlete(ctx context.Context, arg UpdateAthleteParams) error {
 	_, err := q.db.ExecContext(ctx, updateAthlete,
 		arg.Name,
 		arg.Grade,
-		arg.PersonalRecord,
+		arg.Gender,
 		arg.Events,
 		arg.ID,
 	)
 	return err
 }
 
+const updateCompetitor = `-- name: UpdateCompetitor :exec
+UPDATE competitors
+SET name = ?, school_id = ?, gender = ?, grade = ?
+WHERE id = ?
+`
+
+type UpdateCompetitorParams struct {
+	Name     string
+	SchoolID sql.NullInt32
+	Gender   sql.NullString
+	Grade    sql.NullInt16
+	ID       int32
+}
+
+func (q *Queries) UpdateCompetitor(ctx context.Context, arg UpdateCompetitorParams) error {
+	_, err := q.db.ExecContext(ctx, updateCompetitor,
+		arg.Name,
+		arg.SchoolID,
+		arg.Gender,
+		arg.Grade,
+		arg.ID,
+	)
+	return err
+}
+
+const updateCourse = `-- name: UpdateCourse :exec
+UPDATE courses
+SET name = ?, venue = ?, distance_meters = ?, surface = ?, notes = ?
+WHERE id = ?
+`
+
+type UpdateCourseParams struct {
+	Name           string
+	Venue          string
+	DistanceMeters int32
+	Surface        sql.NullString
+	Notes          sql.NullString
+	ID             int32
+}
+
+func (q *Queries) UpdateCourse(ctx context.Context, arg UpdateCourseParams) error {
+	_, err := q.db.ExecContext(ctx, updateCourse,
+		arg.Name,
+		arg.Venue,
+		arg.DistanceMeters,
+		arg.Surface,
+		arg.Notes,
+		arg.ID,
+	)
+	return err
+}
+
 const updateMeet = `-- name: UpdateMeet :exec
 UPDATE meets
-SET name = ?, meet_date = ?, location = ?, description = ?
+SET season_id = ?, course_id = ?, name = ?, meet_date = ?, location = ?, description = ?
 WHERE id = ?
 `
 
 type UpdateMeetParams struct {
+	SeasonID    sql.NullInt32
+	CourseID    sql.NullInt32
 	Name        string
 	MeetDate    time.Time
 	Location    string
@@ -359,6 +2440,8 @@ type UpdateMeetParams struct {
 
 func (q *Queries) UpdateMeet(ctx context.Context, arg UpdateMeetParams) error {
 	_, err := q.db.ExecContext(ctx, updateMeet,
+		arg.SeasonID,
+		arg.CourseID,
 		arg.Name,
 		arg.MeetDate,
 		arg.Location,
@@ -367,3 +2450,160 @@ func (q *Queries) UpdateMeet(ctx context.Context, arg UpdateMeetParams) error {
 	)
 	return err
 }
+
+const updateRace = `-- name: UpdateRace :exec
+UPDATE races
+SET course_id = ?, name = ?, gender = ?, division = ?, start_time = ?
+WHERE id = ?
+`
+
+type UpdateRaceParams struct {
+	CourseID  sql.NullInt32
+	Name      string
+	Gender    sql.NullString
+	Division  string
+	StartTime sql.NullTime
+	ID        int32
+}
+
+func (q *Queries) UpdateRace(ctx context.Context, arg UpdateRaceParams) error {
+	_, err := q.db.ExecContext(ctx, updateRace,
+		arg.CourseID,
+		arg.Name,
+		arg.Gender,
+		arg.Division,
+		arg.StartTime,
+		arg.ID,
+	)
+	return err
+}
+
+const updateRaceGunTime = `-- name: UpdateRaceGunTime :exec
+UPDATE races SET gun_time = ? WHERE id = ?
+`
+
+type UpdateRaceGunTimeParams struct {
+	GunTime sql.NullTime
+	ID      int32
+}
+
+func (q *Queries) UpdateRaceGunTime(ctx context.Context, arg UpdateRaceGunTimeParams) error {
+	_, err := q.db.ExecContext(ctx, updateRaceGunTime, arg.GunTime, arg.ID)
+	return err
+}
+
+const updateRacePlaces = `-- name: UpdateRacePlaces :exec
+UPDATE results r
+LEFT JOIN (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY time_ms, id) AS finish_place
+    FROM results
+    WHERE race_id = ? AND status IN ('finished', 'unattached')
+) ranked ON r.id = ranked.id
+SET r.place = ranked.finish_place
+WHERE r.race_id = ?
+`
+
+func (q *Queries) UpdateRacePlaces(ctx context.Context, raceID int32) error {
+	_, err := q.db.ExecContext(ctx, updateRacePlaces, raceID, raceID)
+	return err
+}
+
+const updateResultVersion = `-- name: UpdateResultVersion :execrows
+UPDATE results
+SET athlete_id = ?, competitor_id = ?, race_id = ?, status = ?, time_ms = ?, place = ?, version = version + 1
+WHERE id = ? AND version = ?
+`
+
+type UpdateResultVersionParams struct {
+	AthleteID    sql.NullInt32
+	CompetitorID sql.NullInt32
+	RaceID       int32
+	Status       string
+	TimeMs       sql.NullInt32
+	Place        sql.NullInt32
+	ID           int32
+	Version      int32
+}
+
+func (q *Queries) UpdateResultVersion(ctx context.Context, arg UpdateResultVersionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateResultVersion,
+		arg.AthleteID,
+		arg.CompetitorID,
+		arg.RaceID,
+		arg.Status,
+		arg.TimeMs,
+		arg.Place,
+		arg.ID,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateSchool = `-- name: UpdateSchool :exec
+UPDATE schools SET name = ?, abbreviation = ? WHERE id = ?
+`
+
+type UpdateSchoolParams struct {
+	Name         string
+	Abbreviation sql.NullString
+	ID           int32
+}
+
+func (q *Queries) UpdateSchool(ctx context.Context, arg UpdateSchoolParams) error {
+	_, err := q.db.ExecContext(ctx, updateSchool, arg.Name, arg.Abbreviation, arg.ID)
+	return err
+}
+
+const updateSeason = `-- name: UpdateSeason :exec
+UPDATE seasons
+SET year = ?, start_date = ?, end_date = ?
+WHERE id = ?
+`
+
+type UpdateSeasonParams struct {
+	Year      int32
+	StartDate time.Time
+	EndDate   time.Time
+	ID        int32
+}
+
+func (q *Queries) UpdateSeason(ctx context.Context, arg UpdateSeasonParams) error {
+	_, err := q.db.ExecContext(ctx, updateSeason,
+		arg.Year,
+		arg.StartDate,
+		arg.EndDate,
+		arg.ID,
+	)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users SET password_hash = ? WHERE id = ?
+`
+
+type UpdateUserPasswordParams struct {
+	PasswordHash string
+	ID           int32
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.PasswordHash, arg.ID)
+	return err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :exec
+UPDATE users SET role = ? WHERE id = ?
+`
+
+type UpdateUserRoleParams struct {
+	Role string
+	ID   int32
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserRole, arg.Role, arg.ID)
+	return err
+}
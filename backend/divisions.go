@@ -0,0 +1,49 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Genders an athlete can race as
+const (
+	GenderBoys  = "boys"
+	GenderGirls = "girls"
+)
+
+// Divisions a result can be run in
+const (
+	DivisionVarsity      = "varsity"
+	DivisionJV           = "jv"
+	DivisionMiddleSchool = "middle_school"
+	DivisionOpen         = "open"
+)
+
+// validGender reports whether gender is one of the known genders
+func validGender(gender string) bool {
+	return gender == GenderBoys || gender == GenderGirls
+}
+
+// validDivision reports whether division is one of the known divisions
+func validDivision(division string) bool {
+	switch division {
+	case DivisionVarsity, DivisionJV, DivisionMiddleSchool, DivisionOpen:
+		return true
+	}
+	return false
+}
+
+// queryFilter reads an optional filter from the query string. A missing
+// parameter is returned as NULL so the query matches every row.
+func queryFilter(c *gin.Context, name string, valid func(string) bool) (sql.NullString, error) {
+	value := c.Query(name)
+	if value == "" {
+		return sql.NullString{}, nil
+	}
+	if !valid(value) {
+		return sql.NullString{}, fmt.Errorf("Invalid %s %q", name, value)
+	}
+	return sql.NullString{String: value, Valid: true}, nil
+}
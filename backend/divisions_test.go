@@ -0,0 +1,84 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// topTimeRow is a finish on the top-times board by an athlete of gender
+// in a race of division
+func topTimeRow(id int64, gender, division string, timeMs int64) []driver.Value {
+	return []driver.Value{id, id, int64(1), int64(1), division, StatusFinished, timeMs, int64(1), nil,
+		"Runner", gender, "Jones County Invitational", time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC), int64(5000)}
+}
+
+// filteredTopTimes serves GetTopTimes from rows as the query filters them,
+// by the gender and division it is given or every row for NULL
+func filteredTopTimes(fake *fakeDB, rows [][]driver.Value) {
+	fake.rows["GetTopTimes"] = func(args []driver.NamedValue) [][]driver.Value {
+		var matched [][]driver.Value
+		for _, row := range rows {
+			if (args[0].Value == nil || args[1].Value == row[10]) && (args[2].Value == nil || args[3].Value == row[4]) {
+				matched = append(matched, row)
+			}
+		}
+		return matched
+	}
+}
+
+func TestTopTimesFilters(t *testing.T) {
+	fake := useFakeDB(t)
+	filteredTopTimes(fake, [][]driver.Value{
+		topTimeRow(1, GenderBoys, DivisionVarsity, 960000),
+		topTimeRow(2, GenderGirls, DivisionVarsity, 1100000),
+		topTimeRow(3, GenderBoys, DivisionJV, 1050000),
+		topTimeRow(4, GenderGirls, DivisionJV, 1200000),
+	})
+	r := newRouter()
+
+	tests := []struct {
+		query string
+		want  []int32
+	}{
+		{"", []int32{1, 2, 3, 4}},
+		{"?gender=girls", []int32{2, 4}},
+		{"?division=jv", []int32{3, 4}},
+		{"?gender=boys&division=varsity", []int32{1}},
+		{"?gender=girls&division=middle_school", []int32{}},
+	}
+	for _, tt := range tests {
+		w := serve(r, "GET", "/api/top-times"+tt.query, "")
+		if w.Code != 200 {
+			t.Errorf("%q: got %d: %s", tt.query, w.Code, w.Body)
+			continue
+		}
+		var times []TopTimeResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &times); err != nil {
+			t.Fatal(err)
+		}
+		ids := []int32{}
+		for _, time := range times {
+			ids = append(ids, time.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+
+	// The board shows whose times they are
+	w := serve(r, "GET", "/api/top-times?gender=girls&division=jv", "")
+	var times []TopTimeResponse
+	json.Unmarshal(w.Body.Bytes(), &times)
+	if len(times) != 1 || times[0].Gender != GenderGirls || times[0].Division != DivisionJV {
+		t.Errorf("got %+v, want the girls' JV time", times)
+	}
+
+	for _, query := range []string{"?gender=men", "?gender=Girls", "?division=freshman"} {
+		if w := serve(r, "GET", "/api/top-times"+query, ""); w.Code != 400 {
+			t.Errorf("%q: got %d, want 400", query, w.Code)
+		}
+	}
+}
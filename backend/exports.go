@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export formats, chosen with the "format" query parameter on list
+// endpoints. JSON is the default.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// exportFormat reads the optional "format" query parameter
+func exportFormat(c *gin.Context) (string, error) {
+	format := c.DefaultQuery("format", FormatJSON)
+	switch format {
+	case FormatJSON, FormatCSV, FormatXLSX:
+		return format, nil
+	}
+	return "", fmt.Errorf("Invalid format %q", format)
+}
+
+// writeSpreadsheet sends a table as a CSV or Excel download, naming the
+// file after name
+func writeSpreadsheet(c *gin.Context, format, name string, t spreadsheet.Table) {
+	var buf bytes.Buffer
+	var contentType string
+	var err error
+	switch format {
+	case FormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = spreadsheet.WriteXLSX(&buf, t)
+	default:
+		contentType = "text/csv; charset=utf-8"
+		err = spreadsheet.WriteCSV(&buf, t)
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, fileSlug(name), format))
+	c.Data(200, contentType, buf.Bytes())
+}
+
+// fileSlug turns a name such as "Jones County Invitational Results" into
+// one safe for a file, "jones-county-invitational-results"
+func fileSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(slug), "-")
+}
+
+// meetFileName names a file of a meet's things, such as its "results",
+// after the meet, or after its ID when nothing is left of the name
+func meetFileName(meet db.Meet, what string) string {
+	name := fileSlug(meet.Name)
+	if name == "" {
+		name = fmt.Sprintf("meet-%d", meet.ID)
+	}
+	return name + "-" + fileSlug(what)
+}
+
+// blankZero leaves a cell empty for a zero value, such as a missing place
+func blankZero(n int32) any {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+// rosterTable lays out athletes for export
+func rosterTable(athletes []AthleteResponse) spreadsheet.Table {
+	t := spreadsheet.Table{
+		Sheet:  "Roster",
+		Header: []string{"Name", "Grade", "Gender", "Personal Record", "Events"},
+	}
+	for _, a := range athletes {
+		t.Rows = append(t.Rows, []any{a.Name, a.Grade, a.Gender, a.PersonalRecord, a.Events})
+	}
+	return t
+}
+
+// resultsTable lays out a meet's results for export, naming each result's
+// race from raceNames
+func resultsTable(meetName string, results []ResultResponse, raceNames map[int32]string) spreadsheet.Table {
+	t := spreadsheet.Table{
+		Sheet:  meetName,
+		Header: []string{"Race", "Place", "Name", "Team", "Gender", "Division", "Status", "Time", "Distance (m)", "Pace (per mile)", "PR"},
+	}
+	for _, r := range results {
+		pr := ""
+		if r.IsPersonalRecord {
+			pr = "PR"
+		}
+		t.Rows = append(t.Rows, []any{
+			raceNames[r.RaceID], blankZero(r.Place), r.AthleteName, r.Team, r.Gender, r.Division,
+			r.Status, r.Time, blankZero(r.DistanceMeters), r.Pace, pr,
+		})
+	}
+	return t
+}
+
+// topTimesTable lays out the top times for export, ranked in order
+func topTimesTable(times []TopTimeResponse) spreadsheet.Table {
+	t := spreadsheet.Table{
+		Sheet:  "Top Times",
+		Header: []string{"Rank", "Name", "Gender", "Division", "Time", "Distance (m)", "Pace (per mile)", "Meet", "Date", "Place"},
+	}
+	for i, tt := range times {
+		t.Rows = append(t.Rows, []any{
+			i + 1, tt.AthleteName, tt.Gender, tt.Division, tt.Time, blankZero(tt.DistanceMeters),
+			tt.Pace, tt.MeetName, tt.MeetDate, blankZero(tt.Place),
+		})
+	}
+	return t
+}
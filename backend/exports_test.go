@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"jones-county-xc/backend/db"
+)
+
+func TestMeetFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Jones County Invitational", "jones-county-invitational-results"},
+		{"  Region 4-AAA: Day 2!", "region-4-aaa-day-2-results"},
+		// Nothing is left of a name without ASCII letters or digits
+		{"日本", "meet-12-results"},
+		{"¡¿!?", "meet-12-results"},
+		{"", "meet-12-results"},
+	}
+	for _, tt := range tests {
+		if got := meetFileName(db.Meet{ID: 12, Name: tt.name}, "results"); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/racetime"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AthleteHistoryResponse struct {
+	Athlete AthleteResponse `json:"athlete"`
+	// PersonalRecord is the 5K record, or the record of unknown distance
+	// when the athlete has no 5K results
+	PersonalRecord  *MarkResponse           `json:"personalRecord"`
+	PersonalRecords []MarkResponse          `json:"personalRecords"`
+	SeasonBests     []SeasonBestResponse    `json:"seasonBests"`
+	Results         []AthleteResultResponse `json:"results"`
+}
+
+// MarkResponse identifies the result that set a best time
+type MarkResponse struct {
+	ResultID       int32  `json:"resultId"`
+	MeetID         int32  `json:"meetId"`
+	MeetName       string `json:"meetName"`
+	MeetDate       string `json:"meetDate"`
+	DistanceMeters int32  `json:"distanceMeters,omitempty"`
+	Time           string `json:"time"`
+	TimeMs         int32  `json:"timeMs"`
+}
+
+// SeasonBestResponse is the best time in a season at one distance
+type SeasonBestResponse struct {
+	SeasonID       int32        `json:"seasonId"`
+	SeasonYear     int32        `json:"seasonYear"`
+	DistanceMeters int32        `json:"distanceMeters,omitempty"`
+	Best           MarkResponse `json:"best"`
+}
+
+type AthleteResultResponse struct {
+	ID           int32  `json:"id"`
+	RaceID       int32  `json:"raceId"`
+	RaceName     string `json:"raceName"`
+	MeetID       int32  `json:"meetId"`
+	MeetName     string `json:"meetName"`
+	MeetDate     string `json:"meetDate"`
+	MeetLocation string `json:"meetLocation"`
+	SeasonID     int32  `json:"seasonId,omitempty"`
+	SeasonYear   int32  `json:"seasonYear,omitempty"`
+	Division     string `json:"division"`
+	Status       string `json:"status"`
+	Time         string `json:"time"`
+	TimeMs       int32  `json:"timeMs"`
+	Place        int32  `json:"place"`
+	// DistanceMeters and the per-mile pace are omitted when the meet has
+	// no course
+	DistanceMeters int32  `json:"distanceMeters,omitempty"`
+	Pace           string `json:"pace,omitempty"`
+	PaceMs         int32  `json:"paceMs,omitempty"`
+	// IsPersonalRecord is true when the result beat every earlier result
+	// at its distance, so the flagged results trace the athlete's PR
+	// progression
+	IsPersonalRecord bool `json:"isPersonalRecord"`
+	IsSeasonBest     bool `json:"isSeasonBest"`
+	// ImprovementMs is how much faster than the previous race at the same
+	// distance this was (negative when slower); omitted for the first
+	ImprovementMs *int32 `json:"improvementMs,omitempty"`
+	// Splits carry each segment's pace; SplitType and SplitDifferenceMs
+	// compare the last segment with the first, as in SplitsResponse
+	Splits            []SplitResponse `json:"splits"`
+	SplitType         string          `json:"splitType,omitempty"`
+	SplitDifferenceMs int32           `json:"splitDifferenceMs,omitempty"`
+}
+
+func newMarkResponse(r db.GetResultsForAthleteRow) MarkResponse {
+	return MarkResponse{
+		ResultID:       r.ID,
+		MeetID:         r.MeetID,
+		MeetName:       r.MeetName,
+		MeetDate:       r.MeetDate.Format("2006-01-02"),
+		DistanceMeters: r.DistanceMeters.Int32,
+		Time:           racetime.Format(r.TimeMs.Int32),
+		TimeMs:         r.TimeMs.Int32,
+	}
+}
+
+// seasonDistance keys season bests, which are kept per distance
+type seasonDistance struct {
+	seasonID       int32
+	distanceMeters int32
+}
+
+// buildAthleteHistory computes PR progression, season bests and race-to-race
+// improvement at each distance from an athlete's results, which must be in
+// date order. Results at meets without a course count as one distance, and
+// races the athlete did not finish are listed but otherwise skipped.
+// The personal records themselves are the athlete's stored records, which
+// the progression at each distance ends at. Splits are keyed by result ID.
+func buildAthleteHistory(rows []db.GetResultsForAthleteRow, records []db.PersonalRecord, splits map[int32][]db.ResultSplit) (*MarkResponse, []MarkResponse, []SeasonBestResponse, []AthleteResultResponse) {
+	results := make([]AthleteResultResponse, len(rows))
+
+	seasonBests := []SeasonBestResponse{}
+	rowIndex := make(map[int32]int)              // result ID -> index in rows
+	prTime := make(map[int32]int32)              // distance -> best time so far
+	lastTime := make(map[int32]int32)            // distance -> time of the previous race
+	bestIndex := make(map[seasonDistance]int)    // season and distance -> index in seasonBests
+	bestResult := make(map[seasonDistance]int32) // season and distance -> result ID of season best
+	for i, r := range rows {
+		rowIndex[r.ID] = i
+		var place int32
+		if r.Place.Valid {
+			place = r.Place.Int32
+		}
+		var pace string
+		var paceMs int32
+		if r.TimeMs.Valid {
+			pace, paceMs = formatPace(r.TimeMs.Int32, r.DistanceMeters)
+		}
+		results[i] = AthleteResultResponse{
+			ID:             r.ID,
+			RaceID:         r.RaceID,
+			RaceName:       r.RaceName,
+			MeetID:         r.MeetID,
+			MeetName:       r.MeetName,
+			MeetDate:       r.MeetDate.Format("2006-01-02"),
+			MeetLocation:   r.MeetLocation,
+			SeasonID:       r.SeasonID.Int32,
+			SeasonYear:     r.SeasonYear.Int32,
+			Division:       r.Division,
+			Status:         r.Status,
+			Time:           formatResultTime(r.Status, r.TimeMs),
+			TimeMs:         r.TimeMs.Int32,
+			Place:          place,
+			DistanceMeters: r.DistanceMeters.Int32,
+			Pace:           pace,
+			PaceMs:         paceMs,
+		}
+		analysis := analyzeSplits(r.ID, splits[r.ID], r.TimeMs, r.DistanceMeters)
+		results[i].Splits = analysis.Splits
+		results[i].SplitType = analysis.SplitType
+		results[i].SplitDifferenceMs = analysis.SplitDifferenceMs
+
+		if !isFinisher(r.Status) || !r.TimeMs.Valid {
+			continue
+		}
+		timeMs := r.TimeMs.Int32
+
+		distance := r.DistanceMeters.Int32
+		if previous, ok := lastTime[distance]; ok {
+			improvement := previous - timeMs
+			results[i].ImprovementMs = &improvement
+		}
+		lastTime[distance] = timeMs
+
+		// A time equal to the record does not take it
+		if best, ok := prTime[distance]; !ok || timeMs < best {
+			prTime[distance] = timeMs
+			results[i].IsPersonalRecord = true
+		}
+
+		if !r.SeasonID.Valid {
+			continue
+		}
+		key := seasonDistance{r.SeasonID.Int32, distance}
+		j, ok := bestIndex[key]
+		if !ok {
+			bestIndex[key] = len(seasonBests)
+			seasonBests = append(seasonBests, SeasonBestResponse{
+				SeasonID:       r.SeasonID.Int32,
+				SeasonYear:     r.SeasonYear.Int32,
+				DistanceMeters: distance,
+				Best:           newMarkResponse(r),
+			})
+			bestResult[key] = r.ID
+		} else if timeMs < seasonBests[j].Best.TimeMs {
+			seasonBests[j].Best = newMarkResponse(r)
+			bestResult[key] = r.ID
+		}
+	}
+
+	for i := range results {
+		key := seasonDistance{results[i].SeasonID, results[i].DistanceMeters}
+		if results[i].SeasonID != 0 && bestResult[key] == results[i].ID {
+			results[i].IsSeasonBest = true
+		}
+	}
+
+	personalRecords := []MarkResponse{}
+	for _, record := range records {
+		if i, ok := rowIndex[record.ResultID]; ok {
+			personalRecords = append(personalRecords, newMarkResponse(rows[i]))
+		}
+	}
+	var pr *MarkResponse
+	if headline, ok := headlinePersonalRecord(records); ok {
+		for i := range personalRecords {
+			if personalRecords[i].ResultID == headline.ResultID {
+				pr = &personalRecords[i]
+			}
+		}
+	}
+	return pr, personalRecords, seasonBests, results
+}
+
+// registerHistoryRoutes adds athlete result history routes to the public group
+func registerHistoryRoutes(api *gin.RouterGroup) {
+	// Get an athlete's results with PR and season best analysis. The PR and
+	// season bests cover every season; the season parameter only narrows
+	// the list of results returned.
+	api.GET("/athletes/:id/results", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid athlete ID"})
+			return
+		}
+
+		athlete, err := queries.GetAthleteByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Athlete not found"})
+			return
+		}
+
+		seasonID, err := seasonFilter(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		records, err := queries.GetPersonalRecordsForAthlete(context.Background(), athlete.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		rows, err := queries.GetResultsForAthlete(context.Background(), sql.NullInt32{Int32: athlete.ID, Valid: true})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		splits, err := queries.GetSplitsForAthlete(context.Background(), sql.NullInt32{Int32: athlete.ID, Valid: true})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		pr, personalRecords, seasonBests, results := buildAthleteHistory(rows, records, splitsByResult(splits))
+
+		filtered := make([]AthleteResultResponse, 0, len(results))
+		for _, r := range results {
+			if !seasonID.Valid || r.SeasonID == seasonID.Int32 {
+				filtered = append(filtered, r)
+			}
+		}
+
+		c.JSON(200, AthleteHistoryResponse{
+			Athlete:         newAthleteResponse(athlete, records),
+			PersonalRecord:  pr,
+			PersonalRecords: personalRecords,
+			SeasonBests:     seasonBests,
+			Results:         filtered,
+		})
+	})
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"jones-county-xc/backend/db"
+)
+
+// historyRow is an athlete's result at a meet on day of September 2024,
+// in season 1, at distance meters or of unknown distance for 0
+func historyRow(id int32, day int, distance int32, status string, timeMs int32) db.GetResultsForAthleteRow {
+	return db.GetResultsForAthleteRow{
+		ID:             id,
+		AthleteID:      sql.NullInt32{Int32: 4, Valid: true},
+		RaceID:         id,
+		MeetID:         id,
+		Status:         status,
+		TimeMs:         sql.NullInt32{Int32: timeMs, Valid: timeMs > 0},
+		MeetName:       "Meet",
+		MeetDate:       time.Date(2024, 9, day, 0, 0, 0, 0, time.UTC),
+		SeasonID:       sql.NullInt32{Int32: 1, Valid: true},
+		SeasonYear:     sql.NullInt32{Int32: 2024, Valid: true},
+		DistanceMeters: sql.NullInt32{Int32: distance, Valid: distance > 0},
+	}
+}
+
+// storedRecord is a personal record as stored, at distance meters or of
+// unknown distance for 0
+func storedRecord(resultID, distance, timeMs int32) db.PersonalRecord {
+	return db.PersonalRecord{
+		AthleteID:      4,
+		DistanceMeters: sql.NullInt32{Int32: distance, Valid: distance > 0},
+		ResultID:       resultID,
+		TimeMs:         timeMs,
+	}
+}
+
+func TestBuildAthleteHistoryPersonalRecords(t *testing.T) {
+	// Result IDs are out of date order: results entered late, such as
+	// imported ones, have higher IDs than those of later meets
+	rows := []db.GetResultsForAthleteRow{
+		historyRow(30, 1, 5000, StatusFinished, 1200000),
+		historyRow(12, 8, 5000, StatusFinished, 1150000),
+		// A disqualified or unfinished runner's time is no record
+		historyRow(13, 10, 5000, StatusDQ, 1100000),
+		historyRow(14, 12, 5000, StatusDNF, 0),
+		// Equalling the record does not take it
+		historyRow(11, 15, 5000, StatusFinished, 1150000),
+		// Each distance has a record of its own
+		historyRow(15, 20, 3200, StatusFinished, 700000),
+		historyRow(16, 22, 0, StatusUnattached, 1300000),
+		historyRow(17, 29, 5000, StatusFinished, 1160000),
+	}
+	records := []db.PersonalRecord{
+		storedRecord(16, 0, 1300000),
+		storedRecord(15, 3200, 700000),
+		storedRecord(12, 5000, 1150000),
+	}
+	pr, personalRecords, seasonBests, results := buildAthleteHistory(rows, records, nil)
+
+	var flagged []int32
+	for _, r := range results {
+		if r.IsPersonalRecord {
+			flagged = append(flagged, r.ID)
+		}
+	}
+	if want := []int32{30, 12, 15, 16}; !reflect.DeepEqual(flagged, want) {
+		t.Errorf("flagged %v as records, want %v", flagged, want)
+	}
+
+	var ids []int32
+	for _, m := range personalRecords {
+		ids = append(ids, m.ResultID)
+	}
+	if want := []int32{16, 15, 12}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got records %v, want %v", ids, want)
+	}
+	if pr == nil || pr.ResultID != 12 || pr.MeetDate != "2024-09-08" || pr.DistanceMeters != 5000 {
+		t.Errorf("got headline record %+v, want result 12", pr)
+	}
+
+	// The progression at each distance ends at the stored record
+	last := make(map[int32]int32)
+	for _, r := range results {
+		if r.IsPersonalRecord {
+			last[r.DistanceMeters] = r.ID
+		}
+	}
+	for _, record := range records {
+		if got := last[record.DistanceMeters.Int32]; got != record.ResultID {
+			t.Errorf("the progression at %dm ends at %d, not the record %d", record.DistanceMeters.Int32, got, record.ResultID)
+		}
+	}
+
+	bests := make(map[int32]int32)
+	for _, b := range seasonBests {
+		bests[b.DistanceMeters] = b.Best.ResultID
+	}
+	if want := map[int32]int32{5000: 12, 3200: 15, 0: 16}; !reflect.DeepEqual(bests, want) {
+		t.Errorf("got season bests %v, want %v", bests, want)
+	}
+}
+
+func TestBuildAthleteHistoryUnknownDistanceHeadline(t *testing.T) {
+	rows := []db.GetResultsForAthleteRow{
+		historyRow(1, 1, 3200, StatusFinished, 700000),
+		historyRow(2, 8, 0, StatusFinished, 1200000),
+	}
+	records := []db.PersonalRecord{storedRecord(2, 0, 1200000), storedRecord(1, 3200, 700000)}
+	pr, _, _, _ := buildAthleteHistory(rows, records, nil)
+	if pr == nil || pr.ResultID != 2 {
+		t.Errorf("got headline record %+v, want the record of unknown distance", pr)
+	}
+
+	if pr, personalRecords, _, _ := buildAthleteHistory(nil, nil, nil); pr != nil || len(personalRecords) != 0 {
+		t.Errorf("an athlete without results got %+v and %v", pr, personalRecords)
+	}
+}
+
+// TestPersonalRecordQueries checks that the stored records break ties the
+// way the progression does, by the earliest meet, and count only finishes
+// at each distance
+func TestPersonalRecordQueries(t *testing.T) {
+	fake := useFakeDB(t)
+	if err := recomputePersonalRecords(queries, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := rebuildPersonalRecords(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, partition := range map[string]string{
+		"CreatePersonalRecordsForAthlete": "PARTITION BY c.distance_meters ORDER BY r.time_ms, m.meet_date, r.id",
+		"CreateAllPersonalRecords":        "PARTITION BY r.athlete_id, c.distance_meters ORDER BY r.time_ms, m.meet_date, r.id",
+	} {
+		query := fake.execSQL[name]
+		if !strings.Contains(query, partition) {
+			t.Errorf("%s does not rank by %q", name, partition)
+		}
+		if !strings.Contains(query, "r.status IN ('finished', 'unattached')") {
+			t.Errorf("%s counts results other than finishes", name)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Package hytek reads cross country results published by Hy-Tek Meet
+// Manager, either as the semicolon-delimited results export or as the
+// printed results text file.
+//
+// The export has one line per finisher. When its first line names the
+// columns they are found by name; otherwise they are taken to be event,
+// place, last name, first name, grade, school and time, in that order.
+//
+// The printed results list each race under a title such as "Event 2  Girls
+// 5000 Meter Run CC Varsity", as a table with place, name, year, school and
+// finals columns. Team scores following a race are skipped.
+//
+// Races are named as in the file; their gender and division are worked out
+// from the name where they can be. Sample files in both formats are kept
+// in testdata.
+package hytek
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/racetime"
+)
+
+// Finisher statuses; they match the statuses of results
+const (
+	StatusFinished = "finished"
+	StatusDNF      = "dnf"
+	StatusDNS      = "dns"
+	StatusDQ       = "dq"
+)
+
+// Results are the races of one meet
+type Results struct {
+	// Meet and Date are empty when the file does not give them
+	Meet  string
+	Date  time.Time
+	Races []Race
+}
+
+// Race is one event and its finishers in finishing order
+type Race struct {
+	Name string
+	// Gender is boys or girls, and Division is varsity, jv, middle_school
+	// or open; either is empty when the name does not say
+	Gender    string
+	Division  string
+	Finishers []Finisher
+}
+
+// Finisher is one runner's result
+type Finisher struct {
+	// Place is 0 for runners without one, such as those who did not finish
+	Place int
+	// Name is given first name first, as "Jane Smith"
+	Name   string
+	Grade  int
+	School string
+	Status string
+	// Time is the time as printed, such as "19:45.20", and is empty for
+	// runners without one
+	Time   string
+	TimeMs int32
+}
+
+// Parse reads results in either format, telling them apart by whether the
+// first line is semicolon-delimited
+func Parse(r io.Reader) (*Results, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	first, _, _ := bytes.Cut(bytes.TrimSpace(data), []byte("\n"))
+	if bytes.Count(first, []byte(";")) >= 3 {
+		return ParseSemicolon(bytes.NewReader(data))
+	}
+	return ParseText(bytes.NewReader(data))
+}
+
+// semicolonColumns are the header names recognized in the export
+var semicolonColumns = map[string][]string{
+	"event":  {"event", "event name", "race"},
+	"place":  {"place", "pl", "overall place"},
+	"last":   {"last", "last name"},
+	"first":  {"first", "first name"},
+	"name":   {"name", "athlete"},
+	"grade":  {"grade", "yr", "year", "age"},
+	"school": {"school", "team", "team name"},
+	"time":   {"time", "finals", "mark", "final time"},
+}
+
+// semicolonOrder is the column order of an export without a header
+var semicolonOrder = []string{"event", "place", "last", "first", "grade", "school", "time"}
+
+// ParseSemicolon reads the semicolon-delimited results export
+func ParseSemicolon(r io.Reader) (*Results, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("hytek: no results")
+	}
+
+	index := make(map[string]int)
+	if header := records[0]; isSemicolonHeader(header) {
+		for i, h := range header {
+			h = strings.ToLower(strings.TrimSpace(h))
+			for field, names := range semicolonColumns {
+				for _, name := range names {
+					if _, ok := index[field]; !ok && h == name {
+						index[field] = i
+					}
+				}
+			}
+		}
+		records = records[1:]
+	} else {
+		for i, field := range semicolonOrder {
+			index[field] = i
+		}
+	}
+	if _, ok := index["time"]; !ok {
+		return nil, errors.New("hytek: no time column")
+	}
+	_, hasName := index["name"]
+	_, hasLast := index["last"]
+	if !hasName && !hasLast {
+		return nil, errors.New("hytek: no name column")
+	}
+
+	results := &Results{}
+	races := make(map[string]int)
+	for n, record := range records {
+		cell := func(field string) string {
+			if i, ok := index[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		name := cell("name")
+		if name == "" {
+			name = strings.TrimSpace(cell("first") + " " + cell("last"))
+		}
+		f, err := newFinisher(cell("place"), name, cell("grade"), cell("school"), cell("time"))
+		if err != nil {
+			return nil, fmt.Errorf("hytek: line %d: %w", n+2, err)
+		}
+
+		event := cell("event")
+		i, ok := races[event]
+		if !ok {
+			i = len(results.Races)
+			races[event] = i
+			results.Races = append(results.Races, newRace(event))
+		}
+		results.Races[i].Finishers = append(results.Races[i].Finishers, f)
+	}
+	return results, nil
+}
+
+// isSemicolonHeader reports whether an export's first line names columns
+// rather than holding a finisher
+func isSemicolonHeader(record []string) bool {
+	for _, h := range record {
+		h = strings.ToLower(strings.TrimSpace(h))
+		for _, names := range semicolonColumns {
+			for _, name := range names {
+				if h == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+var (
+	// raceTitle matches a race's title line, with or without an event number
+	raceTitle = regexp.MustCompile(`(?i)^\s*(?:event\s+\d+\s+|results\s+-\s+)?((?:girls|boys|women|men|mixed)\b.*?)\s*$`)
+	// tableHeader matches the line naming a results table's columns
+	tableHeader = regexp.MustCompile(`(?i)\bname\b.*\b(school|team)\b.*\b(finals|time)\b`)
+	// teamScores marks the start of a race's team scores
+	teamScores = regexp.MustCompile(`(?i)^\s*team\s+scores`)
+	// finisherWithYear and finisherNoYear match a line of a results
+	// table, with and without a year column; names and schools without a
+	// year between them are separated by two or more spaces
+	finisherWithYear = regexp.MustCompile(`^\s*(\d+|--|\*\*)\s+(\S.*?)\s+(\d{1,2}|FR|SO|JR|SR)\s+(\S.*?)\s+(` + markPattern + `)(?:\s+\d+)*\s*$`)
+	finisherNoYear   = regexp.MustCompile(`^\s*(\d+|--|\*\*)\s+(\S.*?)\s{2,}(\S.*?)\s+(` + markPattern + `)(?:\s+\d+)*\s*$`)
+	// meetTitle splits a meet's title from a trailing date
+	meetTitle = regexp.MustCompile(`^\s*(.*?)\s+-\s+(\d{1,2}/\d{1,2}/\d{4})\s*$`)
+)
+
+// markPattern matches a printed time or status
+const markPattern = `\d{1,2}:\d{2}(?::\d{2})?(?:\.\d{1,3})?|DNF|DNS|DQ|SCR|NT`
+
+// ParseText reads the printed results text file
+func ParseText(r io.Reader) (*Results, error) {
+	results := &Results{}
+	var race *Race
+	inTable := false
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "==="):
+			continue
+		case pageHeader(trimmed):
+			continue
+		case teamScores.MatchString(line):
+			inTable = false
+			continue
+		case tableHeader.MatchString(line):
+			inTable = race != nil
+			continue
+		}
+
+		if m := raceTitle.FindStringSubmatch(line); m != nil && !finisherWithYear.MatchString(line) {
+			results.Races = append(results.Races, newRace(m[1]))
+			race = &results.Races[len(results.Races)-1]
+			inTable = false
+			continue
+		}
+
+		if !inTable {
+			if race == nil && results.Meet == "" {
+				results.Meet = trimmed
+				if m := meetTitle.FindStringSubmatch(trimmed); m != nil {
+					results.Meet = m[1]
+					results.Date, _ = time.Parse("1/2/2006", m[2])
+				}
+			}
+			continue
+		}
+
+		var place, name, grade, school, mark string
+		if m := finisherWithYear.FindStringSubmatch(line); m != nil {
+			place, name, grade, school, mark = m[1], m[2], m[3], m[4], m[5]
+		} else if m := finisherNoYear.FindStringSubmatch(line); m != nil {
+			place, name, school, mark = m[1], m[2], m[3], m[4]
+		} else {
+			continue
+		}
+		f, err := newFinisher(place, name, grade, school, mark)
+		if err != nil {
+			return nil, fmt.Errorf("hytek: line %d: %w", n, err)
+		}
+		race.Finishers = append(race.Finishers, f)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(results.Races) == 0 {
+		return nil, errors.New("hytek: no races found")
+	}
+	return results, nil
+}
+
+// pageHeader reports whether a line is part of the header Meet Manager
+// prints at the top of each page
+func pageHeader(line string) bool {
+	lower := strings.ToLower(line)
+	return strings.Contains(lower, "hy-tek") || strings.Contains(lower, "meet manager") || lower == "results"
+}
+
+// newRace names a race and works out its gender and division
+func newRace(name string) Race {
+	race := Race{Name: name}
+	lower := " " + strings.ToLower(name) + " "
+	switch {
+	case strings.Contains(lower, " girls") || strings.Contains(lower, " women"):
+		race.Gender = "girls"
+	case strings.Contains(lower, " boys") || strings.Contains(lower, " men"):
+		race.Gender = "boys"
+	}
+	switch {
+	case strings.Contains(lower, "junior varsity") || strings.Contains(lower, " jv "):
+		race.Division = "jv"
+	case strings.Contains(lower, "middle school") || strings.Contains(lower, " ms "):
+		race.Division = "middle_school"
+	case strings.Contains(lower, "varsity"):
+		race.Division = "varsity"
+	case strings.Contains(lower, " open "):
+		race.Division = "open"
+	}
+	return race
+}
+
+// newFinisher converts the fields of a finisher as printed
+func newFinisher(place, name, grade, school, mark string) (Finisher, error) {
+	f := Finisher{Name: firstLast(name), School: strings.TrimSpace(school), Status: StatusFinished}
+	if n, err := strconv.Atoi(strings.TrimSpace(place)); err == nil {
+		f.Place = n
+	}
+	f.Grade = parseGrade(grade)
+
+	mark = strings.ToUpper(strings.TrimSpace(mark))
+	switch mark {
+	case "DNF":
+		f.Status = StatusDNF
+	case "DNS", "SCR", "NT", "":
+		f.Status = StatusDNS
+	case "DQ":
+		f.Status = StatusDQ
+	default:
+		ms, err := racetime.Parse(mark)
+		if err != nil {
+			return Finisher{}, err
+		}
+		f.Time, f.TimeMs = mark, ms
+	}
+	if f.Status != StatusFinished {
+		f.Place = 0
+	}
+	return f, nil
+}
+
+// firstLast turns "Smith, Jane" into "Jane Smith"
+func firstLast(name string) string {
+	if last, first, ok := strings.Cut(name, ","); ok {
+		name = strings.TrimSpace(first) + " " + strings.TrimSpace(last)
+	}
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// parseGrade reads a year column, given as a grade or as FR, SO, JR or SR
+func parseGrade(grade string) int {
+	switch strings.ToUpper(strings.TrimSpace(grade)) {
+	case "FR":
+		return 9
+	case "SO":
+		return 10
+	case "JR":
+		return 11
+	case "SR":
+		return 12
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(grade))
+	return n
+}
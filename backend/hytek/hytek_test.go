@@ -0,0 +1,149 @@
+package hytek
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file string
+		want Results
+	}{
+		{
+			file: "testdata/export.txt",
+			want: Results{
+				Races: []Race{
+					{
+						Name:     "Girls 5000 Meter Run CC Varsity",
+						Gender:   "girls",
+						Division: "varsity",
+						Finishers: []Finisher{
+							{Place: 1, Name: "Jane Smith", Grade: 11, School: "Jones County", Status: StatusFinished, Time: "19:45.20", TimeMs: 1185200},
+							{Place: 2, Name: "Mary Doe", Grade: 10, School: "Mary Persons", Status: StatusFinished, Time: "20:01.30", TimeMs: 1201300},
+							{Place: 3, Name: "Sarah O'Neal", Grade: 12, School: "Jones County", Status: StatusFinished, Time: "20:15.05", TimeMs: 1215050},
+							{Name: "Ellie Green", Grade: 10, School: "Mary Persons", Status: StatusDNF},
+						},
+					},
+					{
+						Name:     "Boys 5000 Meter Run CC Junior Varsity",
+						Gender:   "boys",
+						Division: "jv",
+						Finishers: []Finisher{
+							{Place: 1, Name: "Tyler Jackson", Grade: 10, School: "Jones County", Status: StatusFinished, Time: "17:58.40", TimeMs: 1078400},
+							{Place: 2, Name: "Luis Martin", Grade: 11, School: "Mary Persons", Status: StatusFinished, Time: "18:12.90", TimeMs: 1092900},
+							{Name: "Owen Hall", Grade: 12, School: "Mary Persons", Status: StatusDNS},
+						},
+					},
+				},
+			},
+		},
+		{
+			file: "testdata/printed.txt",
+			want: Results{
+				Meet: "Jones County Invitational",
+				Date: time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC),
+				Races: []Race{
+					{
+						Name:     "Girls 5000 Meter Run CC Varsity",
+						Gender:   "girls",
+						Division: "varsity",
+						Finishers: []Finisher{
+							{Place: 1, Name: "Jane Smith", Grade: 11, School: "Jones County", Status: StatusFinished, Time: "19:45.20", TimeMs: 1185200},
+							{Place: 2, Name: "Mary Doe", Grade: 10, School: "Mary Persons", Status: StatusFinished, Time: "20:01.30", TimeMs: 1201300},
+							{Place: 3, Name: "Sarah O'Neal", Grade: 12, School: "Jones County", Status: StatusFinished, Time: "20:15.05", TimeMs: 1215050},
+							{Place: 4, Name: "Ana Lee", Grade: 9, School: "Mary Persons", Status: StatusFinished, Time: "20:40.10", TimeMs: 1240100},
+							{Place: 5, Name: "Kate Brown", Grade: 10, School: "Jones County", Status: StatusFinished, Time: "21:02.00", TimeMs: 1262000},
+							{Name: "Ellie Green", Grade: 10, School: "Mary Persons", Status: StatusDNF},
+							{Name: "Lily White", Grade: 11, School: "Jones County", Status: StatusDQ},
+						},
+					},
+					{
+						Name:     "Boys 5000 Meter Run CC Junior Varsity",
+						Gender:   "boys",
+						Division: "jv",
+						Finishers: []Finisher{
+							{Place: 1, Name: "Tyler Jackson", Grade: 10, School: "Jones County", Status: StatusFinished, Time: "17:58.40", TimeMs: 1078400},
+							{Place: 2, Name: "Luis Martin", Grade: 11, School: "Mary Persons", Status: StatusFinished, Time: "18:12.90", TimeMs: 1092900},
+							{Place: 3, Name: "Caleb Reed", Grade: 9, School: "Jones County", Status: StatusFinished, Time: "1:02:10.50", TimeMs: 3730500},
+							{Name: "Owen Hall", Grade: 12, School: "Mary Persons", Status: StatusDNS},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			got, err := Parse(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Meet != tt.want.Meet || !got.Date.Equal(tt.want.Date) {
+				t.Errorf("got meet %q on %v, want %q on %v", got.Meet, got.Date, tt.want.Meet, tt.want.Date)
+			}
+			if len(got.Races) != len(tt.want.Races) {
+				t.Fatalf("got %d races, want %d", len(got.Races), len(tt.want.Races))
+			}
+			for i, race := range got.Races {
+				want := tt.want.Races[i]
+				if race.Name != want.Name || race.Gender != want.Gender || race.Division != want.Division {
+					t.Errorf("race %d is %q (%s, %s), want %q (%s, %s)", i, race.Name, race.Gender, race.Division, want.Name, want.Gender, want.Division)
+				}
+				if len(race.Finishers) != len(want.Finishers) {
+					t.Errorf("%s has %d finishers, want %d", race.Name, len(race.Finishers), len(want.Finishers))
+					continue
+				}
+				for j, f := range race.Finishers {
+					if !reflect.DeepEqual(f, want.Finishers[j]) {
+						t.Errorf("%s finisher %d is %+v, want %+v", race.Name, j+1, f, want.Finishers[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseHeaderlessExport(t *testing.T) {
+	export := "Girls 3200 Meter Run Middle School;1;Smith;Jane;8;Jones County;13:05.4\n"
+	got, err := Parse(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Race{{
+		Name:      "Girls 3200 Meter Run Middle School",
+		Gender:    "girls",
+		Division:  "middle_school",
+		Finishers: []Finisher{{Place: 1, Name: "Jane Smith", Grade: 8, School: "Jones County", Status: StatusFinished, Time: "13:05.4", TimeMs: 785400}},
+	}}
+	if !reflect.DeepEqual(got.Races, want) {
+		t.Errorf("got %+v, want %+v", got.Races, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"no races", "Jones County Invitational\nnothing to see here\n"},
+		{"bad time", "Event;Place;Last Name;First Name;Grade;Team;Time\nGirls Varsity;1;Smith;Jane;11;Jones County;19:4x\n"},
+		{"no time column", "Event;Place;Last Name;First Name;Grade;Team\nGirls Varsity;1;Smith;Jane;11;Jones County\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input)); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package ical writes calendars in the iCalendar format of RFC 5545, which
+// phone and desktop calendar apps can subscribe to.
+//
+// Only what a schedule of all-day events needs is written. Lines end in
+// CRLF and are folded at 75 octets, and text values are escaped as the
+// RFC requires.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLine is the longest a content line may be in octets, not counting
+// its CRLF
+const maxLine = 75
+
+// Calendar is a named list of events
+type Calendar struct {
+	Name string
+	// Stamp is when the calendar was generated; every event is stamped
+	// with it
+	Stamp  time.Time
+	Events []Event
+}
+
+// Event is an all-day event. Its UID must stay the same while the event
+// changes, so calendar apps replace it rather than adding a copy.
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Date        time.Time
+}
+
+// Write writes the calendar as an iCalendar file
+func (cal Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Jones County XC//Meet Schedule//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", Escape(cal.Name))
+	}
+
+	for _, e := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", Escape(e.UID))
+		line("DTSTAMP", cal.Stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE", e.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE", e.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", Escape(e.Summary))
+		if e.Location != "" {
+			line("LOCATION", Escape(e.Location))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION", Escape(e.Description))
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// Escape escapes a text value, backslashing the characters that separate
+// values and writing newlines as \n
+func Escape(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeLine writes a content line, folding it onto continuation lines
+// that start with a space so none is longer than maxLine octets. Lines are
+// only folded between characters, never within one.
+func writeLine(w *bufio.Writer, s string) {
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLine - 1 // the leading space counts
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
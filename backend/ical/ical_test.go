@@ -0,0 +1,95 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWriteLineEndingsAndFolding(t *testing.T) {
+	cal := Calendar{
+		Name:  "Jones County XC",
+		Stamp: time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC),
+		Events: []Event{{
+			UID:         "meet-1@jones-county-xc",
+			Summary:     strings.Repeat("Invitational ", 12),
+			Location:    strings.Repeat("é", 80),
+			Description: "short",
+			Date:        time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	var buf bytes.Buffer
+	if err := cal.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("calendar does not end with a CRLF-terminated END line")
+	}
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Errorf("calendar has a bare LF")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	var unfolded []string
+	for _, line := range lines {
+		if len(line) > maxLine {
+			t.Errorf("line is %d octets: %q", len(line), line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+
+	want := map[string]bool{
+		"SUMMARY:" + strings.Repeat("Invitational ", 12): true,
+		"LOCATION:" + strings.Repeat("é", 80):            true,
+		"DTSTART;VALUE=DATE:20240914":                    true,
+		"DTEND;VALUE=DATE:20240915":                      true,
+		"DTSTAMP:20240901T120000Z":                       true,
+	}
+	for _, line := range unfolded {
+		delete(want, line)
+	}
+	for line := range want {
+		t.Errorf("unfolded calendar has no line %q", line)
+	}
+}
+
+func TestWriteLineKeepsCharactersWhole(t *testing.T) {
+	var buf bytes.Buffer
+	cal := Calendar{Events: []Event{{Summary: strings.Repeat("日本", 40)}}}
+	if err := cal.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a character: %q", line)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Gray, GA", `Gray\, GA`},
+		{"Boys; Girls", `Boys\; Girls`},
+		{`C:\results`, `C:\\results`},
+		{"first\nsecond", `first\nsecond`},
+		{"first\r\nsecond", `first\nsecond`},
+		{"first\rsecond", `first\nsecond`},
+		{`a\,b`, `a\\\,b`},
+		{"plain: text", "plain: text"},
+	}
+	for _, tt := range tests {
+		if got := Escape(tt.in); got != tt.want {
+			t.Errorf("Escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,711 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/hytek"
+	"jones-county-xc/backend/namematch"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Results from timing companies are imported from CSV, or from Hy-Tek Meet
+// Manager's results files, which name the meet and each race. Hy-Tek
+// results go in the meet's race of the same name, added with the gender
+// and division its name gives when it is new, and the meet itself can be
+// found or added by its name and date. Rows for our team, or with no
+// school, are matched to our athletes by name; rows that match no athlete
+// are reported and left out. Rows for other schools are matched
+// to their competitors, adding the school and competitor when they are
+// new. The results then go through the same checks as POST /results, all
+// in one transaction, and a dry run rolls it back once they are checked.
+
+// Ways a row can be matched
+const (
+	MatchAthlete       = "athlete"
+	MatchCompetitor    = "competitor"
+	MatchNewCompetitor = "new_competitor"
+	MatchUnmatched     = "unmatched"
+)
+
+// importColumns maps result fields to CSV columns, each given as a header
+// name or a column number counting from 1. Empty fields use the usual
+// header names, such as "Name" or "Athlete" for the name.
+type importColumns struct {
+	Name   string
+	School string
+	Grade  string
+	Time   string
+	Place  string
+	Status string
+}
+
+// defaultHeaders are the header names tried for unmapped columns
+var defaultHeaders = map[string][]string{
+	"name":   {"name", "athlete", "runner"},
+	"school": {"school", "team"},
+	"grade":  {"grade", "yr", "year"},
+	"time":   {"time", "final time", "finish time"},
+	"place":  {"place", "pl", "overall place"},
+	"status": {"status"},
+}
+
+// importMeet is the meet a results file names, with an empty Name or zero
+// Date when the file does not give them
+type importMeet struct {
+	Name string
+	Date time.Time
+}
+
+// importOptions say where imported results go and whether to keep them
+type importOptions struct {
+	// MeetID is the meet results go in; when it is 0, Meet is added for
+	// them
+	MeetID int32
+	Meet   importMeet
+	// RaceID puts every result in one race; otherwise they go in the race
+	// their row names, or the race for Division and each runner's gender
+	RaceID   int32
+	Division string
+	// Gender is given to competitors added by the import, and picks the
+	// race in place of each runner's gender
+	Gender string
+	DryRun bool
+}
+
+// importRow is one imported result, with Row counting data rows from 1.
+// Division and Gender, when set, override the import's options. Race is
+// the name of the row's race in a Hy-Tek file, such as "Boys 5K Varsity
+// Seeded", and puts the row in the meet's race of that name.
+type importRow struct {
+	Row      int
+	Race     string
+	Name     string
+	School   string
+	Grade    string
+	Time     string
+	Place    string
+	Status   string
+	Division string
+	Gender   string
+}
+
+type ImportRowResponse struct {
+	Row    int    `json:"row"`
+	Name   string `json:"name"`
+	School string `json:"school,omitempty"`
+	Time   string `json:"time"`
+	// Match is athlete, competitor, new_competitor or unmatched; Fuzzy is
+	// true when the name on file differs from the one in the file
+	Match        string `json:"match"`
+	Fuzzy        bool   `json:"fuzzy,omitempty"`
+	MatchedName  string `json:"matchedName,omitempty"`
+	AthleteID    int32  `json:"athleteId,omitempty"`
+	CompetitorID int32  `json:"competitorId,omitempty"`
+}
+
+type ImportResponse struct {
+	DryRun bool `json:"dryRun"`
+	// MeetID is the meet the results go in, or 0 for a meet named in the
+	// file that a dry run or failed import would add
+	MeetID    int32               `json:"meetId"`
+	Rows      []ImportRowResponse `json:"rows"`
+	Unmatched int                 `json:"unmatched"`
+	// Errors are rows that failed the result checks; when there are any,
+	// nothing is imported
+	Errors []RowErrorResponse `json:"errors"`
+	IDs    []int32            `json:"ids"`
+}
+
+// readImportRows reads CSV results with a header row, finding each field's
+// column from columns
+func readImportRows(r io.Reader, columns importColumns) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("The CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	for field, mapping := range map[string]string{
+		"name":   columns.Name,
+		"school": columns.School,
+		"grade":  columns.Grade,
+		"time":   columns.Time,
+		"place":  columns.Place,
+		"status": columns.Status,
+	} {
+		i, err := columnIndex(header, field, mapping)
+		if err != nil {
+			return nil, err
+		}
+		index[field] = i
+	}
+	if index["name"] < 0 || index["time"] < 0 {
+		return nil, errors.New("Name and time columns are required")
+	}
+
+	var rows []importRow
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		cell := func(field string) string {
+			if i := index[field]; i >= 0 && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := importRow{
+			Row:    n,
+			Name:   cell("name"),
+			School: cell("school"),
+			Grade:  cell("grade"),
+			Time:   cell("time"),
+			Place:  cell("place"),
+			Status: cell("status"),
+		}
+		if row.Name == "" && row.Time == "" {
+			continue // blank line
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("The CSV file has no results")
+	}
+	return rows, nil
+}
+
+// columnIndex finds a field's column from its mapping, or from the default
+// header names when it has none. It returns -1 for an unmapped field with
+// no default header.
+func columnIndex(header []string, field, mapping string) (int, error) {
+	if mapping == "" {
+		for i, h := range header {
+			for _, name := range defaultHeaders[field] {
+				if strings.EqualFold(strings.TrimSpace(h), name) {
+					return i, nil
+				}
+			}
+		}
+		return -1, nil
+	}
+
+	if n, err := strconv.Atoi(mapping); err == nil {
+		if n < 1 || n > len(header) {
+			return 0, fmt.Errorf("The %s column %d is not in the file", field, n)
+		}
+		return n - 1, nil
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), mapping) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("The %s column %q is not in the file", field, mapping)
+}
+
+// readHytekRows reads a Hy-Tek results file into import rows, numbering
+// finishers through the file from 1, and the meet it names
+func readHytekRows(r io.Reader) ([]importRow, importMeet, error) {
+	results, err := hytek.Parse(r)
+	if err != nil {
+		return nil, importMeet{}, err
+	}
+
+	var rows []importRow
+	for _, race := range results.Races {
+		for _, f := range race.Finishers {
+			row := importRow{
+				Row:      len(rows) + 1,
+				Race:     race.Name,
+				Name:     f.Name,
+				School:   f.School,
+				Time:     f.Time,
+				Status:   f.Status,
+				Division: race.Division,
+				Gender:   race.Gender,
+			}
+			if f.Grade > 0 {
+				row.Grade = strconv.Itoa(f.Grade)
+			}
+			if f.Place > 0 {
+				row.Place = strconv.Itoa(f.Place)
+			}
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, importMeet{}, errors.New("The results file has no finishers")
+	}
+	return rows, importMeet{Name: results.Meet, Date: results.Date}, nil
+}
+
+// errNoImportMeet refuses a file that does not say which meet it is for
+var errNoImportMeet = errors.New("The results file does not give the meet's name and date")
+
+// findImportMeet finds the meet a results file names on its date, and
+// returns 0 when there is none for the import to add
+func findImportMeet(meet importMeet) (int32, error) {
+	if meet.Name == "" || meet.Date.IsZero() {
+		return 0, errNoImportMeet
+	}
+	found, err := queries.GetMeetByNameDate(context.Background(), db.GetMeetByNameDateParams{
+		Name:     meet.Name,
+		MeetDate: meet.Date,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return found.ID, err
+}
+
+// importer matches import rows against the runners and schools on file
+type importer struct {
+	q           *db.Queries
+	athletes    []db.Athlete
+	schools     []db.School
+	competitors []db.Competitor
+	// races are the meet's races by name, as found or added
+	races map[string]int32
+}
+
+// isOurs reports whether a row is for our own team: its school is left
+// blank, or is found to be our school as any other school is found
+func (im *importer) isOurs(row importRow) bool {
+	if row.School == "" {
+		return true
+	}
+	s, ok := im.findSchool(row.School)
+	return ok && s.IsHome
+}
+
+// matchAthlete finds the athlete a row is for among those of the row's
+// gender, if it has one, and those whose gender is not on file
+func (im *importer) matchAthlete(row importRow, response *ImportRowResponse) {
+	var names []string
+	var candidates []db.Athlete
+	for _, a := range im.athletes {
+		if row.Gender == "" || !a.Gender.Valid || a.Gender.String == row.Gender {
+			names = append(names, a.Name)
+			candidates = append(candidates, a)
+		}
+	}
+	i, score, ok := namematch.Best(row.Name, names)
+	if !ok {
+		response.Match = MatchUnmatched
+		return
+	}
+	response.Match = MatchAthlete
+	response.AthleteID = candidates[i].ID
+	response.MatchedName = candidates[i].Name
+	response.Fuzzy = score < 1
+}
+
+// matchCompetitor finds the competitor a row is for, adding them and their
+// school when they are new
+func (im *importer) matchCompetitor(row importRow, response *ImportRowResponse) error {
+	schoolID, err := im.school(row.School)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	var candidates []db.Competitor
+	for _, comp := range im.competitors {
+		if comp.SchoolID.Valid && comp.SchoolID.Int32 == schoolID {
+			names = append(names, comp.Name)
+			candidates = append(candidates, comp)
+		}
+	}
+	if i, score, ok := namematch.Best(row.Name, names); ok {
+		response.Match = MatchCompetitor
+		response.CompetitorID = candidates[i].ID
+		response.MatchedName = candidates[i].Name
+		response.Fuzzy = score < 1
+		return nil
+	}
+
+	params := db.CreateCompetitorParams{
+		Name:     row.Name,
+		SchoolID: sql.NullInt32{Int32: schoolID, Valid: true},
+		Gender:   sql.NullString{String: row.Gender, Valid: row.Gender != ""},
+	}
+	if grade, err := strconv.Atoi(row.Grade); err == nil && grade > 0 {
+		params.Grade = sql.NullInt16{Int16: int16(grade), Valid: true}
+	}
+	result, err := im.q.CreateCompetitor(context.Background(), params)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	im.competitors = append(im.competitors, db.Competitor{
+		ID:       int32(id),
+		Name:     params.Name,
+		SchoolID: params.SchoolID,
+		Gender:   params.Gender,
+		Grade:    params.Grade,
+	})
+	response.Match = MatchNewCompetitor
+	response.CompetitorID = int32(id)
+	return nil
+}
+
+// findSchool finds a school by abbreviation or name
+func (im *importer) findSchool(name string) (db.School, bool) {
+	for _, s := range im.schools {
+		if s.Abbreviation.Valid && strings.EqualFold(s.Abbreviation.String, name) {
+			return s, true
+		}
+	}
+	names := make([]string, len(im.schools))
+	for i, s := range im.schools {
+		names[i] = s.Name
+	}
+	if i, _, ok := namematch.Best(name, names); ok {
+		return im.schools[i], true
+	}
+	return db.School{}, false
+}
+
+// school finds a school by name or abbreviation, adding it when it is new
+func (im *importer) school(name string) (int32, error) {
+	if s, ok := im.findSchool(name); ok {
+		return s.ID, nil
+	}
+
+	result, err := im.q.CreateSchool(context.Background(), db.CreateSchoolParams{Name: name})
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	im.schools = append(im.schools, db.School{ID: int32(id), Name: name})
+	return int32(id), nil
+}
+
+// addMeet adds the meet a results file names, in the season for its date
+func (im *importer) addMeet(meet importMeet) (int32, error) {
+	seasonID, err := meetSeason(0, meet.Date)
+	if err != nil {
+		return 0, err
+	}
+	result, err := im.q.CreateMeet(context.Background(), db.CreateMeetParams{
+		SeasonID: seasonID,
+		Name:     meet.Name,
+		MeetDate: meet.Date,
+	})
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int32(id), err
+}
+
+// race finds the meet's race named for a row, adding it with the row's
+// division and gender when it is new
+func (im *importer) race(meetID int32, row importRow) (int32, error) {
+	if id, ok := im.races[row.Race]; ok {
+		return id, nil
+	}
+
+	race, err := im.q.GetRaceForMeetName(context.Background(), db.GetRaceForMeetNameParams{
+		MeetID: meetID,
+		Name:   row.Race,
+	})
+	id := race.ID
+	if errors.Is(err, sql.ErrNoRows) {
+		var result sql.Result
+		result, err = im.q.CreateRace(context.Background(), db.CreateRaceParams{
+			MeetID:   meetID,
+			Name:     row.Race,
+			Gender:   sql.NullString{String: row.Gender, Valid: row.Gender != ""},
+			Division: row.Division,
+		})
+		if err != nil {
+			return 0, err
+		}
+		var lastID int64
+		lastID, err = result.LastInsertId()
+		id = int32(lastID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	im.races[row.Race] = id
+	return id, nil
+}
+
+// importResults matches and adds imported results in a transaction, which
+// is rolled back for a dry run or when any row fails the result checks
+func importResults(rows []importRow, opts importOptions) (ImportResponse, error) {
+	response := ImportResponse{
+		DryRun: opts.DryRun,
+		Rows:   make([]ImportRowResponse, len(rows)),
+		Errors: []RowErrorResponse{},
+		IDs:    []int32{},
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		return response, err
+	}
+	defer tx.Rollback()
+
+	im := &importer{q: queries.WithTx(tx), races: make(map[string]int32)}
+	if im.athletes, err = im.q.GetAllAthletes(context.Background(), sql.NullString{}); err != nil {
+		return response, err
+	}
+	if im.schools, err = im.q.GetAllSchools(context.Background()); err != nil {
+		return response, err
+	}
+	if im.competitors, err = im.q.GetAllCompetitors(context.Background(), sql.NullInt32{}); err != nil {
+		return response, err
+	}
+
+	response.MeetID = opts.MeetID
+	if response.MeetID == 0 {
+		if response.MeetID, err = im.addMeet(opts.Meet); err != nil {
+			return response, err
+		}
+	}
+
+	var requests []resultRequest
+	var requestRows []int
+	for i, row := range rows {
+		r := &response.Rows[i]
+		*r = ImportRowResponse{Row: row.Row, Name: row.Name, School: row.School, Time: row.Time}
+		if row.Division == "" {
+			row.Division = opts.Division
+		}
+		if row.Gender == "" {
+			row.Gender = opts.Gender
+		}
+
+		if im.isOurs(row) {
+			im.matchAthlete(row, r)
+		} else if err := im.matchCompetitor(row, r); err != nil {
+			return response, err
+		}
+		if r.Match == MatchUnmatched {
+			response.Unmatched++
+			continue
+		}
+
+		var place int
+		if row.Place != "" {
+			if place, err = strconv.Atoi(row.Place); err != nil || place < 1 {
+				response.Errors = append(response.Errors, RowErrorResponse{Row: row.Row, Error: fmt.Sprintf("Invalid place %q", row.Place)})
+				continue
+			}
+		}
+		raceID := opts.RaceID
+		if raceID == 0 && row.Race != "" {
+			if row.Division == "" {
+				row.Division = DivisionVarsity
+			}
+			if !validDivision(row.Division) {
+				response.Errors = append(response.Errors, RowErrorResponse{Row: row.Row, Error: "Division must be varsity, jv, middle_school or open"})
+				continue
+			}
+			if raceID, err = im.race(response.MeetID, row); err != nil {
+				return response, err
+			}
+		}
+		requests = append(requests, resultRequest{
+			AthleteID:    r.AthleteID,
+			CompetitorID: r.CompetitorID,
+			RaceID:       raceID,
+			Division:     row.Division,
+			Status:       strings.ToLower(row.Status),
+			Time:         row.Time,
+			Place:        int32(place),
+			gender:       row.Gender,
+		})
+		requestRows = append(requestRows, row.Row)
+	}
+	if len(response.Errors) == 0 {
+		ids, rowErrors, err := addResults(im.q, response.MeetID, requests)
+		if err != nil {
+			return response, err
+		}
+		response.IDs = ids
+		for _, e := range rowErrors {
+			e.Row = requestRows[e.Row-1]
+			response.Errors = append(response.Errors, e)
+		}
+	}
+	if len(response.Errors) > 0 || opts.DryRun {
+		// Meets and competitors added by the import are rolled back with
+		// the results
+		if opts.MeetID == 0 {
+			response.MeetID = 0
+		}
+		for i := range response.Rows {
+			if response.Rows[i].Match == MatchNewCompetitor {
+				response.Rows[i].CompetitorID = 0
+			}
+		}
+		response.IDs = []int32{}
+		return response, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// registerImportRoutes adds result import routes to the statistician group
+func registerImportRoutes(statisticians *gin.RouterGroup) {
+	// Import a meet's results from CSV, or from a Hy-Tek results file with
+	// format=hytek, sent as the "file" field of a form or as the request
+	// body. Query parameters map the CSV's name, school, grade, time, place
+	// and status columns and set raceId, division, gender and dryRun.
+	statisticians.POST("/meets/:id/results/import", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+		serveImport(c, meet.ID)
+	})
+
+	// Import a Hy-Tek results file into the meet it names, found by its
+	// name and date or added when there is none, as above
+	statisticians.POST("/results/import", func(c *gin.Context) {
+		if c.DefaultQuery("format", "hytek") != "hytek" {
+			c.JSON(400, gin.H{"error": "Only Hy-Tek results name their meet; import CSV results into a meet"})
+			return
+		}
+		serveImport(c, 0)
+	})
+}
+
+// serveImport imports the results in a request into a meet, or with
+// meetID 0 into the meet the Hy-Tek results file names
+func serveImport(c *gin.Context, meetID int32) {
+	format := c.DefaultQuery("format", "csv")
+	if meetID == 0 {
+		format = "hytek"
+	}
+
+	opts := importOptions{
+		MeetID:   meetID,
+		Division: c.Query("division"),
+		Gender:   c.Query("gender"),
+		DryRun:   c.Query("dryRun") == "true",
+	}
+	if value := c.Query("raceId"); value != "" {
+		if meetID == 0 {
+			c.JSON(400, gin.H{"error": "A race can only be given with its meet"})
+			return
+		}
+		raceID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid race ID"})
+			return
+		}
+		opts.RaceID = int32(raceID)
+	}
+	if opts.Gender != "" && !validGender(opts.Gender) {
+		c.JSON(400, gin.H{"error": "Gender must be boys or girls"})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	var rows []importRow
+	var err error
+	switch format {
+	case "csv":
+		rows, err = readImportRows(body, importColumns{
+			Name:   c.Query("name"),
+			School: c.Query("school"),
+			Grade:  c.Query("grade"),
+			Time:   c.Query("time"),
+			Place:  c.Query("place"),
+			Status: c.Query("status"),
+		})
+	case "hytek":
+		rows, opts.Meet, err = readHytekRows(body)
+	default:
+		err = errors.New("Format must be csv or hytek")
+	}
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if opts.MeetID == 0 {
+		opts.MeetID, err = findImportMeet(opts.Meet)
+		if errors.Is(err, errNoImportMeet) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// A meet the import adds has no one following it yet
+	var changes *meetSnapshot
+	if !opts.DryRun && opts.MeetID != 0 {
+		changes = snapshotMeet(queries, opts.MeetID)
+	}
+
+	response, err := importResults(rows, opts)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch {
+	case len(response.Errors) > 0:
+		c.JSON(400, response)
+	case opts.DryRun:
+		c.JSON(200, response)
+	default:
+		changes.publish()
+		c.JSON(201, response)
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"database/sql/driver"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hytekImport answers the queries of a Hy-Tek import into meetID with
+// the athletes in printed.txt on file, and returns the IDs of the races
+// the results went in, in file order
+func hytekImport(fake *fakeDB, meetID int64) *[]any {
+	athletes := [][]driver.Value{}
+	for i, name := range []string{"Jane Smith", "Sarah O'Neal", "Kate Brown", "Lily White", "Tyler Jackson", "Caleb Reed"} {
+		athletes = append(athletes, []driver.Value{int64(i + 1), name, int64(11), nil, nil, time.Now()})
+	}
+	fake.rows["GetAllAthletes"] = func([]driver.NamedValue) [][]driver.Value { return athletes }
+	fake.rows["GetAthleteByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{athletes[args[0].Value.(int64)-1]}
+	}
+	fake.rows["GetAllSchools"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{
+			{int64(testHomeSchool.ID), testHomeSchool.Name, testHomeSchool.Abbreviation.String, true, time.Now()},
+			{int64(2), "Mary Persons", nil, false, time.Now()},
+		}
+	}
+	fake.rows["GetCompetitorByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, "Competitor", nil, nil, nil, time.Now()}}
+	}
+	fake.rows["CountCompetitorResultsForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(0)}}
+	}
+	fake.rows["GetRaceDistance"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(5000)}}
+	}
+
+	var races []any
+	fake.rows["GetRaceByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		races = append(races, args[0].Value)
+		return [][]driver.Value{{args[0].Value, meetID, nil, "Race", nil, DivisionVarsity, nil, nil, time.Now()}}
+	}
+	return &races
+}
+
+// TestImportHytekRaces checks that each Hy-Tek race's results go in the
+// meet's race of the same name, even for runners whose gender is not on
+// file
+func TestImportHytekRaces(t *testing.T) {
+	fake := useFakeDB(t)
+	races := hytekImport(fake, 1)
+	fake.rows["GetRaceForMeetName"] = func(args []driver.NamedValue) [][]driver.Value {
+		id := map[any]int64{
+			"Girls 5000 Meter Run CC Varsity":       3,
+			"Boys 5000 Meter Run CC Junior Varsity": 4,
+		}[args[1].Value]
+		return [][]driver.Value{{id, args[0].Value, nil, args[1].Value, nil, DivisionVarsity, nil, nil, time.Now()}}
+	}
+
+	f, err := os.Open("hytek/testdata/printed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, meet, err := readHytekRows(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (importMeet{"Jones County Invitational", time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC)}); meet != want {
+		t.Errorf("read meet %+v, want %+v", meet, want)
+	}
+
+	response, err := importResults(rows, importOptions{MeetID: 1, Division: DivisionVarsity, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Unmatched != 0 || len(response.Errors) != 0 {
+		t.Fatalf("got %d unmatched and errors %v", response.Unmatched, response.Errors)
+	}
+	athletes := 0
+	for _, r := range response.Rows {
+		if r.Match == MatchAthlete {
+			athletes++
+		}
+	}
+	if athletes != 6 {
+		t.Errorf("matched %d rows to our athletes, want 6", athletes)
+	}
+
+	var want []any
+	for i := 0; i < 7; i++ {
+		want = append(want, int64(3))
+	}
+	for i := 0; i < 4; i++ {
+		want = append(want, int64(4))
+	}
+	if !reflect.DeepEqual(*races, want) {
+		t.Errorf("results went in races %v, want %v", *races, want)
+	}
+	for _, e := range fake.execs {
+		if e == "CreateRace" || e == "CreateMeet" {
+			t.Errorf("ran %v", fake.execs)
+			break
+		}
+	}
+}
+
+// TestImportHytekNewMeet checks that a file's meet is added when it is
+// new, and that races of the same gender and division stay apart
+func TestImportHytekNewMeet(t *testing.T) {
+	fake := useFakeDB(t)
+	races := hytekImport(fake, 1)
+	var lookups []any
+	fake.rows["GetRaceForMeetName"] = func(args []driver.NamedValue) [][]driver.Value {
+		lookups = append(lookups, args[0].Value, args[1].Value)
+		return nil
+	}
+
+	file := "Event;Place;Last Name;First Name;Grade;Team;Time\n" +
+		"Boys 5K Varsity;1;Jackson;Tyler;10;Jones County;17:58.40\n" +
+		"Boys 5K Varsity Seeded;1;Reed;Caleb;9;Jones County;17:10.00\n"
+	rows, _, err := readHytekRows(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	meet := importMeet{"Jones County Invitational", time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC)}
+	response, err := importResults(rows, importOptions{Meet: meet})
+	if err != nil || len(response.Errors) > 0 {
+		t.Fatalf("got %v %v", response.Errors, err)
+	}
+
+	args := fake.execArgs["CreateMeet"]
+	if args == nil || args[2].Value != meet.Name || !args[3].Value.(time.Time).Equal(meet.Date) {
+		t.Fatalf("added meet %v, want %+v", args, meet)
+	}
+	// The meet is the first row added, so its ID is 1
+	if response.MeetID != 1 {
+		t.Errorf("imported into meet %d, want 1", response.MeetID)
+	}
+	want := []any{int64(1), "Boys 5K Varsity", int64(1), "Boys 5K Varsity Seeded"}
+	if !reflect.DeepEqual(lookups, want) {
+		t.Errorf("looked up races %v, want %v", lookups, want)
+	}
+	if len(*races) != 2 || (*races)[0] == (*races)[1] {
+		t.Errorf("results went in races %v, want two", *races)
+	}
+	if args := fake.execArgs["CreateRace"]; args[2].Value != "Boys 5K Varsity Seeded" || args[3].Value != "boys" || args[4].Value != DivisionVarsity {
+		t.Errorf("added race %v", args)
+	}
+}
+
+// TestImportHytekMeetByName checks that a file imported without a meet
+// goes in the meet of its name on its date
+func TestImportHytekMeetByName(t *testing.T) {
+	fake := useFakeDB(t)
+	hytekImport(fake, 7)
+	var found []any
+	fake.rows["GetMeetByNameDate"] = func(args []driver.NamedValue) [][]driver.Value {
+		found = append(found, args[0].Value, args[1].Value)
+		return [][]driver.Value{{int64(7), nil, nil, args[0].Value, args[1].Value, "Gray", nil, time.Now()}}
+	}
+	fake.rows["GetRaceForMeetName"] = func(args []driver.NamedValue) [][]driver.Value {
+		id := int64(3)
+		if strings.HasPrefix(args[1].Value.(string), "Boys") {
+			id = 4
+		}
+		return [][]driver.Value{{id, args[0].Value, nil, args[1].Value, nil, DivisionVarsity, nil, nil, time.Now()}}
+	}
+
+	file, err := os.ReadFile("hytek/testdata/printed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := serveJSON(newRouter(), "POST", "/api/results/import?dryRun=true", fake.login(RoleStatistician), string(file))
+	if w.Code != 200 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	want := []any{"Jones County Invitational", time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("looked up meet %v, want %v", found, want)
+	}
+	if !strings.Contains(w.Body.String(), `"meetId":7`) {
+		t.Errorf("got %s", w.Body)
+	}
+	for _, e := range fake.execs {
+		if e == "CreateMeet" {
+			t.Errorf("ran %v", fake.execs)
+		}
+	}
+
+	// A file that does not name its meet needs one given
+	csv := "Event;Place;Last Name;First Name;Grade;Team;Time\nBoys 5K Varsity;1;Jackson;Tyler;10;Jones County;17:58.40\n"
+	if w := serveJSON(newRouter(), "POST", "/api/results/import", fake.login(RoleStatistician), csv); w.Code != 400 {
+		t.Errorf("importing a file without its meet got %d", w.Code)
+	}
+}
+
+// TestImportOurSchool checks that rows are ours when their school is left
+// blank or is found to be our school, and not when another school's name
+// starts with ours
+func TestImportOurSchool(t *testing.T) {
+	fake := useFakeDB(t)
+	hytekImport(fake, 1)
+
+	rows := []importRow{
+		{Row: 1, Name: "Jane Smith", School: "Jones County", Time: "19:45"},
+		{Row: 2, Name: "Sarah O'Neal", School: "JC", Time: "19:50"},
+		{Row: 3, Name: "Kate Brown", School: "jones  county", Time: "19:55"},
+		{Row: 4, Name: "Lily White", Time: "20:00"},
+		{Row: 5, Name: "Ann Lee", School: "Jones County Middle School", Time: "20:05"},
+		{Row: 6, Name: "Mary Doe", School: "Mary Persons", Time: "20:10"},
+	}
+	response, err := importResults(rows, importOptions{RaceID: 3, MeetID: 1, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var matches []string
+	for _, r := range response.Rows {
+		matches = append(matches, r.Match)
+	}
+	want := []string{MatchAthlete, MatchAthlete, MatchAthlete, MatchAthlete, MatchNewCompetitor, MatchNewCompetitor}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("got matches %v, want %v", matches, want)
+	}
+	if args := fake.execArgs["CreateSchool"]; args == nil || args[0].Value != "Jones County Middle School" {
+		t.Errorf("added school %v, want Jones County Middle School", args)
+	}
+}
+
+func TestReadImportRowsDefaultHeaders(t *testing.T) {
+	csv := "Place,Athlete,Team,Yr,Final Time\n" +
+		"1,\"Smith, Jane\",Jones County,11,17:05.3\n" +
+		"\n" +
+		"2, Kate Brown ,Macon,10, 17:40\n" +
+		"3,Ann Lee\n"
+	rows, err := readImportRows(strings.NewReader(csv), importColumns{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The blank line is skipped without using up a row number, and a short
+	// row leaves its missing cells empty
+	want := []importRow{
+		{Row: 1, Name: "Smith, Jane", School: "Jones County", Grade: "11", Time: "17:05.3", Place: "1"},
+		{Row: 2, Name: "Kate Brown", School: "Macon", Grade: "10", Time: "17:40", Place: "2"},
+		{Row: 3, Name: "Ann Lee", Place: "3"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %+v, want %+v", rows, want)
+	}
+}
+
+func TestReadImportRowsMapping(t *testing.T) {
+	csv := "Bib,Runner Name,Club,Chip Time,Gun Time,Result\n" +
+		"101,Jane Smith,Jones County,17:05.3,17:06.0,finished\n" +
+		"102,Kate Brown,Macon,,,DNF\n"
+
+	want := []importRow{
+		{Row: 1, Name: "Jane Smith", School: "Jones County", Time: "17:05.3", Status: "finished"},
+		{Row: 2, Name: "Kate Brown", School: "Macon", Status: "DNF"},
+	}
+	for _, columns := range []importColumns{
+		// By header name, without regard to case
+		{Name: "runner name", School: "CLUB", Time: "Chip Time", Status: "Result"},
+		// By column number, counting from 1
+		{Name: "2", School: "3", Time: "4", Status: "6"},
+	} {
+		rows, err := readImportRows(strings.NewReader(csv), columns)
+		if err != nil {
+			t.Errorf("%+v: %v", columns, err)
+			continue
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("%+v: got %+v, want %+v", columns, rows, want)
+		}
+	}
+}
+
+func TestReadImportRowsErrors(t *testing.T) {
+	tests := []struct {
+		csv     string
+		columns importColumns
+		want    string
+	}{
+		{"", importColumns{}, "The CSV file is empty"},
+		{"Name,Time\n\n", importColumns{}, "The CSV file has no results"},
+		{"Name,School\nJane Smith,Macon\n", importColumns{}, "Name and time columns are required"},
+		{"Name,Time\nJane Smith,17:05\n", importColumns{Time: "3"}, "The time column 3 is not in the file"},
+		{"Name,Time\nJane Smith,17:05\n", importColumns{Place: "0"}, "The place column 0 is not in the file"},
+		{"Name,Time\nJane Smith,17:05\n", importColumns{School: "Team"}, `The school column "Team" is not in the file`},
+	}
+	for _, tt := range tests {
+		_, err := readImportRows(strings.NewReader(tt.csv), tt.columns)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("reading %q with %+v got %v, want %q", tt.csv, tt.columns, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Live result event types. Each event's data is the result as
+// GET /meets/:id/results returns it; a deleted result is sent as it was.
+const (
+	EventResultCreated = "result.created"
+	EventResultUpdated = "result.updated"
+	EventResultDeleted = "result.deleted"
+	// EventReset tells a resuming stream that events it missed are no
+	// longer kept, so it should fetch the meet's results again
+	EventReset = "reset"
+)
+
+const (
+	// liveHistory is how many recent events are kept for streams that
+	// reconnect with Last-Event-ID
+	liveHistory = 1000
+	// liveBuffer is how many events a stream may fall behind before it is
+	// dropped; the client reconnects and resumes from the history
+	liveBuffer = 64
+	// liveHeartbeat is how often an idle stream is sent a comment, so
+	// proxies and browsers keep the connection open
+	liveHeartbeat = 15 * time.Second
+	// liveRetry is how long browsers wait before reconnecting, in ms
+	liveRetry = 3000
+)
+
+// liveEvent is a change to one of a meet's results
+type liveEvent struct {
+	ID     int64
+	MeetID int32
+	Type   string
+	Data   []byte
+}
+
+// liveHub fans result events out to the streams watching each meet. It
+// numbers events in order and keeps each meet's latest so a stream can
+// resume.
+type liveHub struct {
+	mu sync.Mutex
+	// lastID starts from the time the hub is made, so event IDs from before
+	// a restart are older than any it hands out and get a reset
+	lastID int64
+	meets  map[int32]*liveMeet
+}
+
+// liveMeet is what the hub keeps for one meet
+type liveMeet struct {
+	// publishing serializes loading and diffing the meet's results, so
+	// each change is compared with the results the one before it sent
+	// and concurrent changes are not sent twice
+	publishing sync.Mutex
+	// results are the meet's results as last sent, or nil when unknown.
+	// They are guarded by publishing.
+	results map[int32]ResultResponse
+
+	// The rest is guarded by the hub's mu. history holds the meet's latest
+	// events, and trimmed is the ID of the last one dropped from it, or
+	// the hub's first ID when none have been.
+	history []liveEvent
+	trimmed int64
+	streams map[chan liveEvent]bool
+}
+
+// live is the server's hub, fed by the result handlers
+var live = newLiveHub()
+
+func newLiveHub() *liveHub {
+	return &liveHub{
+		lastID: time.Now().UnixMilli(),
+		meets:  make(map[int32]*liveMeet),
+	}
+}
+
+// meet returns what the hub keeps for a meet, adding it if need be. The
+// caller holds h.mu.
+func (h *liveHub) meet(meetID int32) *liveMeet {
+	m := h.meets[meetID]
+	if m == nil {
+		m = &liveMeet{trimmed: h.lastID, streams: make(map[chan liveEvent]bool)}
+		h.meets[meetID] = m
+	}
+	return m
+}
+
+// liveMeet returns what the hub keeps for a meet
+func (h *liveHub) liveMeet(meetID int32) *liveMeet {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.meet(meetID)
+}
+
+// publish numbers an event and sends it to every stream watching its
+// meet. A stream too far behind to take it is closed.
+func (h *liveHub) publish(meetID int32, eventType string, data []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m := h.meet(meetID)
+	h.lastID++
+	e := liveEvent{ID: h.lastID, MeetID: meetID, Type: eventType, Data: data}
+	m.history = append(m.history, e)
+	if len(m.history) > liveHistory {
+		dropped := len(m.history) - liveHistory
+		m.trimmed = m.history[dropped-1].ID
+		m.history = m.history[dropped:]
+	}
+
+	for ch := range m.streams {
+		select {
+		case ch <- e:
+		default:
+			delete(m.streams, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe starts a stream of a meet's events. With a lastID above 0 it
+// also returns the meet's events after that one, or a reset event if
+// some of them are no longer kept or lastID is from before a restart.
+func (h *liveHub) subscribe(meetID int32, lastID int64) (ch chan liveEvent, missed []liveEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m := h.meet(meetID)
+	if lastID > 0 {
+		if lastID > h.lastID || lastID < m.trimmed {
+			missed = []liveEvent{{ID: h.lastID, MeetID: meetID, Type: EventReset, Data: []byte("{}")}}
+		} else {
+			for _, e := range m.history {
+				if e.ID > lastID {
+					missed = append(missed, e)
+				}
+			}
+		}
+	}
+
+	ch = make(chan liveEvent, liveBuffer)
+	m.streams[ch] = true
+	return ch, missed
+}
+
+// unsubscribe ends a stream, unless publish already dropped it
+func (h *liveHub) unsubscribe(meetID int32, ch chan liveEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m := h.meet(meetID)
+	if m.streams[ch] {
+		delete(m.streams, ch)
+		close(ch)
+	}
+}
+
+// meetSnapshot holds a meet's results from before a change. Once the
+// change is saved, publish compares them with the results afterward and
+// sends an event for each result that was created, updated or deleted,
+// including results whose places or PRs changed as a side effect.
+type meetSnapshot struct {
+	meetID int32
+	// results is nil when the hub already knows the meet's results, which
+	// publish then compares with instead
+	results map[int32]ResultResponse
+}
+
+// snapshotMeet records a meet's results before a change using q, which
+// may be bound to the change's transaction. Results the hub already sent
+// are not loaded again. If they cannot be loaded the change is not
+// published, but is otherwise unaffected.
+func snapshotMeet(q *db.Queries, meetID int32) *meetSnapshot {
+	m := live.liveMeet(meetID)
+	m.publishing.Lock()
+	known := m.results != nil
+	m.publishing.Unlock()
+	if known {
+		return &meetSnapshot{meetID: meetID}
+	}
+
+	results, err := liveResults(q, meetID)
+	if err != nil {
+		log.Printf("Failed to load results for live meet %d: %v", meetID, err)
+		return nil
+	}
+	return &meetSnapshot{meetID: meetID, results: results}
+}
+
+// publish sends the events for the changes made since the results last
+// sent, or since the snapshot when none have been, once they are
+// committed. Changes to the meet are published one at a time, so one
+// made meanwhile is sent by whichever publishes first and only once.
+func (s *meetSnapshot) publish() {
+	if s == nil {
+		return
+	}
+	m := live.liveMeet(s.meetID)
+	m.publishing.Lock()
+	defer m.publishing.Unlock()
+
+	before := m.results
+	if before == nil {
+		before = s.results
+	}
+	after, err := liveResults(queries, s.meetID)
+	m.results = after
+	if err != nil {
+		log.Printf("Failed to load results for live meet %d: %v", s.meetID, err)
+	}
+	if err != nil || before == nil {
+		// The change cannot be worked out, so streams fetch the results
+		// again
+		live.publish(s.meetID, EventReset, []byte("{}"))
+		return
+	}
+
+	ids := make([]int32, 0, len(after)+len(before))
+	for id := range after {
+		ids = append(ids, id)
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		previous, existed := before[id]
+		result, exists := after[id]
+		eventType := EventResultUpdated
+		switch {
+		case !existed:
+			eventType = EventResultCreated
+		case !exists:
+			eventType, result = EventResultDeleted, previous
+		case previous == result:
+			continue
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			log.Printf("Failed to encode live result %d: %v", id, err)
+			continue
+		}
+		live.publish(s.meetID, eventType, data)
+	}
+}
+
+// liveResults loads a meet's results by ID
+func liveResults(q *db.Queries, meetID int32) (map[int32]ResultResponse, error) {
+	rows, err := q.GetResultsForMeet(context.Background(), db.GetResultsForMeetParams{
+		MeetID: meetID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[int32]ResultResponse, len(rows))
+	for _, r := range rows {
+		results[r.ID] = newResultResponse(r)
+	}
+	return results, nil
+}
+
+// lastEventID reads where a stream resumes from: the Last-Event-ID header
+// browsers send when reconnecting, or a lastEventId query parameter for
+// the first connection
+func lastEventID(c *gin.Context) int64 {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("lastEventId")
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// writeLiveEvent writes an event in the text/event-stream format
+func writeLiveEvent(c *gin.Context, e liveEvent) {
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+}
+
+// registerLiveRoutes adds live result streams to the public group
+func registerLiveRoutes(api *gin.RouterGroup) {
+	// Stream a meet's result changes as server-sent events. Each event has
+	// an ID; reconnecting with Last-Event-ID replays the events missed.
+	api.GET("/meets/:id/live", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		ch, missed := live.subscribe(meet.ID, lastEventID(c))
+		defer live.unsubscribe(meet.ID, ch)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no") // stop nginx holding events back
+		c.Status(200)
+
+		fmt.Fprintf(c.Writer, "retry: %d\n\n", liveRetry)
+		for _, e := range missed {
+			writeLiveEvent(c, e)
+		}
+		c.Writer.Flush()
+
+		heartbeat := time.NewTicker(liveHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case e, ok := <-ch:
+				if !ok {
+					return // fell behind; the client resumes on reconnect
+				}
+				writeLiveEvent(c, e)
+			case <-heartbeat.C:
+				fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			}
+			c.Writer.Flush()
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useLiveHub gives the test a new hub and a server with a meet to watch
+func useLiveHub(t *testing.T) (*httptest.Server, *fakeDB) {
+	fake := useFakeDB(t)
+	fake.rows["GetMeetByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, nil, nil, "Jones County Invitational", time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC), "Gray", nil, time.Now()}}
+	}
+
+	old := live
+	live = newLiveHub()
+	t.Cleanup(func() { live = old })
+
+	srv := httptest.NewServer(newRouter())
+	t.Cleanup(srv.Close)
+	return srv, fake
+}
+
+// sseEvent is an event as read from a stream
+type sseEvent struct {
+	id        int64
+	eventType string
+	data      string
+}
+
+// liveStream is an open connection to a meet's stream
+type liveStream struct {
+	events chan sseEvent
+	retry  string
+}
+
+// openLiveStream connects to a meet's stream, resuming from lastID unless
+// it is empty, and reads its events in the background
+func openLiveStream(t *testing.T, srv *httptest.Server, meetID int, lastID string) *liveStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/meets/%d/live", srv.URL, meetID), nil)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		resp.Body.Close()
+	})
+	if resp.StatusCode != 200 {
+		t.Fatalf("got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type is %q", ct)
+	}
+
+	s := &liveStream{events: make(chan sseEvent, 100)}
+	r := bufio.NewReader(resp.Body)
+	// The stream opens with the retry interval
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.retry = strings.TrimSpace(line)
+	r.ReadString('\n')
+
+	go func() {
+		defer close(s.events)
+		var e sseEvent
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			name, value, _ := strings.Cut(line, ": ")
+			switch name {
+			case "id":
+				e.id, _ = strconv.ParseInt(value, 10, 64)
+			case "event":
+				e.eventType = value
+			case "data":
+				e.data = value
+			case "":
+				if e.eventType != "" {
+					s.events <- e
+				}
+				e = sseEvent{}
+			}
+		}
+	}()
+	return s
+}
+
+// next waits for the stream's next event
+func (s *liveStream) next(t *testing.T) sseEvent {
+	t.Helper()
+	select {
+	case e, ok := <-s.events:
+		if !ok {
+			t.Fatal("stream ended")
+		}
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+	}
+	return sseEvent{}
+}
+
+// none checks that the stream has sent nothing more
+func (s *liveStream) none(t *testing.T) {
+	t.Helper()
+	select {
+	case e := <-s.events:
+		t.Errorf("got unexpected event %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// waitForStreams waits until n streams are watching a meet, so events
+// published next reach them
+func waitForStreams(t *testing.T, meetID int32, n int) {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		live.mu.Lock()
+		count := len(live.meet(meetID).streams)
+		live.mu.Unlock()
+		if count == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("%d streams never opened on meet %d", n, meetID)
+}
+
+func TestLiveStream(t *testing.T) {
+	srv, _ := useLiveHub(t)
+	s := openLiveStream(t, srv, 1, "")
+	if s.retry != "retry: "+strconv.Itoa(liveRetry) {
+		t.Errorf("stream opened with %q", s.retry)
+	}
+	waitForStreams(t, 1, 1)
+
+	live.publish(1, EventResultCreated, []byte(`{"id":10}`))
+	live.publish(2, EventResultCreated, []byte(`{"id":20}`))
+	live.publish(1, EventResultUpdated, []byte(`{"id":10,"version":2}`))
+
+	first, second := s.next(t), s.next(t)
+	if first.eventType != EventResultCreated || first.data != `{"id":10}` {
+		t.Errorf("first event is %+v", first)
+	}
+	if second.eventType != EventResultUpdated || second.data != `{"id":10,"version":2}` {
+		t.Errorf("second event is %+v", second)
+	}
+	// The other meet's event is skipped but still numbered
+	if second.id != first.id+2 {
+		t.Errorf("events are numbered %d and %d", first.id, second.id)
+	}
+	s.none(t)
+}
+
+func TestLiveStreamReplay(t *testing.T) {
+	srv, _ := useLiveHub(t)
+	live.publish(1, EventResultCreated, []byte(`{"id":1}`))
+	seen := live.lastID
+	live.publish(2, EventResultCreated, []byte(`{"id":2}`))
+	live.publish(1, EventResultUpdated, []byte(`{"id":1,"version":2}`))
+	live.publish(1, EventResultDeleted, []byte(`{"id":1,"version":2}`))
+
+	// Reconnecting replays the meet's events after the last one seen
+	s := openLiveStream(t, srv, 1, strconv.FormatInt(seen, 10))
+	if e := s.next(t); e.id != seen+2 || e.eventType != EventResultUpdated {
+		t.Errorf("first replayed event is %+v", e)
+	}
+	if e := s.next(t); e.id != seen+3 || e.eventType != EventResultDeleted {
+		t.Errorf("second replayed event is %+v", e)
+	}
+	s.none(t)
+
+	// and then carries on live
+	waitForStreams(t, 1, 1)
+	live.publish(1, EventResultCreated, []byte(`{"id":3}`))
+	if e := s.next(t); e.id != seen+4 || e.data != `{"id":3}` {
+		t.Errorf("live event is %+v", e)
+	}
+
+	// A stream that is up to date gets nothing replayed
+	current := openLiveStream(t, srv, 1, strconv.FormatInt(live.lastID, 10))
+	current.none(t)
+}
+
+func TestLiveStreamReset(t *testing.T) {
+	srv, _ := useLiveHub(t)
+	start := live.lastID
+	for i := 0; i < liveHistory+5; i++ {
+		live.publish(1, EventResultUpdated, []byte(`{}`))
+	}
+
+	tests := []struct {
+		name   string
+		lastID int64
+	}{
+		// Events after this one have fallen out of the history
+		{"expired", start + 1},
+		// An ID the hub has not handed out is from another boot
+		{"ahead", live.lastID + 1},
+		// An ID from before the hub was made is from an earlier boot
+		{"before restart", start - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openLiveStream(t, srv, 1, strconv.FormatInt(tt.lastID, 10))
+			e := s.next(t)
+			if e.eventType != EventReset || e.id != live.lastID {
+				t.Errorf("got %+v, want a reset with ID %d", e, live.lastID)
+			}
+			s.none(t)
+		})
+	}
+
+	// The oldest event kept can still be resumed after
+	s := openLiveStream(t, srv, 1, strconv.FormatInt(live.lastID-liveHistory, 10))
+	if e := s.next(t); e.eventType != EventResultUpdated {
+		t.Errorf("got %+v, want the history replayed", e)
+	}
+}
+
+// TestLiveStreamHistoryPerMeet checks that a busy meet does not push
+// another's events out of the history
+func TestLiveStreamHistoryPerMeet(t *testing.T) {
+	srv, _ := useLiveHub(t)
+	live.publish(1, EventResultCreated, []byte(`{"id":1}`))
+	seen := live.lastID
+	live.publish(1, EventResultUpdated, []byte(`{"id":1,"version":2}`))
+	for i := 0; i < liveHistory+5; i++ {
+		live.publish(2, EventResultUpdated, []byte(`{}`))
+	}
+
+	s := openLiveStream(t, srv, 1, strconv.FormatInt(seen, 10))
+	if e := s.next(t); e.id != seen+1 || e.eventType != EventResultUpdated {
+		t.Errorf("got %+v, want the meet's own event replayed", e)
+	}
+	s.none(t)
+}
+
+func TestLiveHubIDsOutlastRestart(t *testing.T) {
+	before := newLiveHub()
+	before.publish(1, EventResultCreated, []byte(`{}`))
+	seen := before.lastID
+	time.Sleep(5 * time.Millisecond)
+
+	// A client resuming from the old hub's events after a restart is told
+	// to reload, rather than being replayed the new hub's events as if
+	// they followed on
+	after := newLiveHub()
+	for i := 0; i < 3; i++ {
+		after.publish(1, EventResultUpdated, []byte(`{}`))
+	}
+	_, missed := after.subscribe(1, seen)
+	if len(missed) != 1 || missed[0].Type != EventReset {
+		t.Errorf("resuming from event %d of an earlier boot got %+v, want a reset", seen, missed)
+	}
+}
+
+func TestLiveStreamUnknownMeet(t *testing.T) {
+	useFakeDB(t)
+	w := serve(newRouter(), "GET", "/api/meets/9/live", "")
+	if w.Code != 404 {
+		t.Errorf("got %d, want 404", w.Code)
+	}
+}
+
+// liveMeetResults serves a meet's results to GetResultsForMeet as the
+// given result IDs and places, which the test changes between writes
+func liveMeetResults(fake *fakeDB, places map[int32]int32, loads *int) {
+	fake.rows["GetResultsForMeet"] = func(args []driver.NamedValue) [][]driver.Value {
+		*loads++
+		var rows [][]driver.Value
+		for id, place := range places {
+			rows = append(rows, []driver.Value{int64(id), int64(4), nil, int64(2), int64(1), "varsity", StatusFinished, int64(1100000), int64(place), nil, int64(1), nil, "Runner", "M", "Jones County", int64(5000), nil})
+		}
+		return rows
+	}
+}
+
+func TestLivePublishChanges(t *testing.T) {
+	srv, fake := useLiveHub(t)
+	places := map[int32]int32{1: 1}
+	var loads int
+	liveMeetResults(fake, places, &loads)
+	s := openLiveStream(t, srv, 1, "")
+	waitForStreams(t, 1, 1)
+
+	// Two changes overlap: both are snapshotted before either publishes
+	first := snapshotMeet(queries, 1)
+	second := snapshotMeet(queries, 1)
+	places[2] = 1
+	places[1] = 2
+	first.publish()
+	second.publish()
+
+	events := map[string]string{}
+	for i := 0; i < 2; i++ {
+		e := s.next(t)
+		events[e.eventType] = e.data
+	}
+	if !strings.Contains(events[EventResultCreated], `"id":2,`) || !strings.Contains(events[EventResultUpdated], `"id":1,`) {
+		t.Errorf("got %v, want result 2 created and 1 updated", events)
+	}
+	// The second change's publish finds nothing the first did not send
+	s.none(t)
+
+	// Once the meet's results are known, a change loads them only after
+	delete(places, 2)
+	loads = 0
+	changes := snapshotMeet(queries, 1)
+	changes.publish()
+	if e := s.next(t); e.eventType != EventResultDeleted || !strings.Contains(e.data, `"id":2,`) {
+		t.Errorf("got %+v, want result 2 deleted", e)
+	}
+	if loads != 1 {
+		t.Errorf("loaded the meet's results %d times for one change", loads)
+	}
+	s.none(t)
+}
@@ -2,80 +2,27 @@ package main
 
 import (
 	"context"
-	"crypto/rand"
 	"database/sql"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
-	"strings"
-	"sync"
 	"time"
 
 	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/racetime"
+	"jones-county-xc/backend/timing"
 
 	"github.com/gin-gonic/gin"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
-var queries *db.Queries
-
-// Simple in-memory token store (tokens expire after 24 hours)
 var (
-	tokenStore     = make(map[string]time.Time)
-	tokenStoreLock sync.RWMutex
+	database *sql.DB
+	queries  *db.Queries
 )
 
-// generateToken creates a random token
-func generateToken() (string, error) {
-	bytes := make([]byte, 32)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(bytes), nil
-}
-
-// validateToken checks if a token is valid and not expired
-func validateToken(token string) bool {
-	tokenStoreLock.RLock()
-	defer tokenStoreLock.RUnlock()
-
-	expiry, exists := tokenStore[token]
-	if !exists {
-		return false
-	}
-	return time.Now().Before(expiry)
-}
-
-// authMiddleware checks for valid authentication
-func authMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(401, gin.H{"error": "Authorization header required"})
-			c.Abort()
-			return
-		}
-
-		// Expect "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(401, gin.H{"error": "Invalid authorization format"})
-			c.Abort()
-			return
-		}
-
-		if !validateToken(parts[1]) {
-			c.JSON(401, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
 type HealthResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
@@ -83,42 +30,204 @@ type HealthResponse struct {
 
 // API response types (different from db models for JSON formatting)
 type AthleteResponse struct {
-	ID             int32  `json:"id"`
-	Name           string `json:"name"`
-	Grade          int8   `json:"grade"`
-	PersonalRecord string `json:"personalRecord"`
-	Events         string `json:"events"`
+	ID               int32  `json:"id"`
+	Name             string `json:"name"`
+	Grade            int8   `json:"grade"`
+	Gender           string `json:"gender"`
+	PersonalRecord   string `json:"personalRecord"`
+	PersonalRecordMs int32  `json:"personalRecordMs,omitempty"`
+	// PersonalRecordResultID is the result that set the personal record
+	PersonalRecordResultID int32  `json:"personalRecordResultId,omitempty"`
+	Events                 string `json:"events"`
+	// PersonalRecords holds the athlete's best time at each distance
+	PersonalRecords []PersonalRecordResponse `json:"personalRecords"`
+}
+
+func newAthleteResponse(a db.Athlete, records []db.PersonalRecord) AthleteResponse {
+	response := AthleteResponse{
+		ID:              a.ID,
+		Name:            a.Name,
+		Grade:           a.Grade,
+		Gender:          a.Gender.String,
+		Events:          a.Events.String,
+		PersonalRecords: make([]PersonalRecordResponse, len(records)),
+	}
+	for i, pr := range records {
+		response.PersonalRecords[i] = newPersonalRecordResponse(pr)
+	}
+	if pr, ok := headlinePersonalRecord(records); ok {
+		response.PersonalRecord = racetime.Format(pr.TimeMs)
+		response.PersonalRecordMs = pr.TimeMs
+		response.PersonalRecordResultID = pr.ResultID
+	}
+	return response
 }
 
 type MeetResponse struct {
 	ID          int32  `json:"id"`
+	SeasonID    int32  `json:"seasonId,omitempty"`
+	CourseID    int32  `json:"courseId,omitempty"`
 	Name        string `json:"name"`
 	Date        string `json:"date"`
 	Location    string `json:"location"`
 	Description string `json:"description"`
 }
 
+func newMeetResponse(m db.Meet) MeetResponse {
+	return MeetResponse{
+		ID:          m.ID,
+		SeasonID:    m.SeasonID.Int32,
+		CourseID:    m.CourseID.Int32,
+		Name:        m.Name,
+		Date:        m.MeetDate.Format("2006-01-02"),
+		Location:    m.Location,
+		Description: m.Description.String,
+	}
+}
+
 type ResultResponse struct {
-	ID          int32  `json:"id"`
-	AthleteID   int32  `json:"athleteId"`
-	MeetID      int32  `json:"meetId"`
+	ID int32 `json:"id"`
+	// Exactly one of AthleteID and CompetitorID is set
+	AthleteID    int32  `json:"athleteId,omitempty"`
+	CompetitorID int32  `json:"competitorId,omitempty"`
+	RaceID       int32  `json:"raceId"`
+	MeetID       int32  `json:"meetId"`
+	Division     string `json:"division"`
+	Gender       string `json:"gender"`
+	// Status is finished, unattached, dnf, dns or dq. Time is the status in
+	// capitals, such as "DNF", and TimeMs and Place are 0 when the runner
+	// has no time or place.
+	Status      string `json:"status"`
 	Time        string `json:"time"`
+	TimeMs      int32  `json:"timeMs"`
 	Place       int32  `json:"place"`
 	AthleteName string `json:"athleteName,omitempty"`
+	// Team is our team for our athletes, and otherwise the competitor's
+	// school or empty for an unattached runner
+	Team string `json:"team"`
+	// DistanceMeters comes from the meet's course; it and the per-mile
+	// pace are omitted when the meet has no course
+	DistanceMeters int32  `json:"distanceMeters,omitempty"`
+	Pace           string `json:"pace,omitempty"`
+	PaceMs         int32  `json:"paceMs,omitempty"`
+	// IsPersonalRecord is true when this result is the athlete's current
+	// PR for its distance
+	IsPersonalRecord bool `json:"isPersonalRecord"`
+	// Version counts the result's edits, for offline sync; ClientID is
+	// the UUID of a result created offline
+	Version  int32  `json:"version"`
+	ClientID string `json:"clientId,omitempty"`
+}
+
+func newResultResponse(r db.GetResultsForMeetRow) ResultResponse {
+	var place int32
+	if r.Place.Valid {
+		place = r.Place.Int32
+	}
+	var pace string
+	var paceMs int32
+	if r.TimeMs.Valid {
+		pace, paceMs = formatPace(r.TimeMs.Int32, r.DistanceMeters)
+	}
+	return ResultResponse{
+		ID:           r.ID,
+		AthleteID:    r.AthleteID.Int32,
+		CompetitorID: r.CompetitorID.Int32,
+		RaceID:       r.RaceID,
+		MeetID:       r.MeetID,
+		Division:     r.Division,
+		Gender:       r.AthleteGender.String,
+		Status:       r.Status,
+		Time:         formatResultTime(r.Status, r.TimeMs),
+		TimeMs:       r.TimeMs.Int32,
+		Place:        place,
+		AthleteName:  r.AthleteName,
+		Team:         resultTeam(r.AthleteID, r.SchoolName, r.Status),
+
+		DistanceMeters:   r.DistanceMeters.Int32,
+		Pace:             pace,
+		PaceMs:           paceMs,
+		IsPersonalRecord: r.PrResultID.Valid,
+		Version:          r.Version,
+		ClientID:         r.ClientID.String,
+	}
 }
 
 type TopTimeResponse struct {
 	ID          int32  `json:"id"`
 	AthleteID   int32  `json:"athleteId"`
 	MeetID      int32  `json:"meetId"`
+	Division    string `json:"division"`
+	Gender      string `json:"gender"`
 	Time        string `json:"time"`
+	TimeMs      int32  `json:"timeMs"`
 	Place       int32  `json:"place"`
 	AthleteName string `json:"athleteName"`
 	MeetName    string `json:"meetName"`
 	MeetDate    string `json:"meetDate"`
+	// DistanceMeters comes from the meet's course; it and the per-mile
+	// pace are omitted when the meet has no course
+	DistanceMeters int32  `json:"distanceMeters,omitempty"`
+	Pace           string `json:"pace,omitempty"`
+	PaceMs         int32  `json:"paceMs,omitempty"`
 }
 
 func main() {
+	// Subcommands run instead of starting the server, and connect to the
+	// database once their arguments are checked. The timing simulator
+	// stands in for a reader, so it can run without the database when it
+	// is given the chips and gun time.
+	if len(os.Args) > 1 && os.Args[1] == "timing-sim" {
+		exitCommand(runTimingSimCommand(os.Args[2:]))
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		exitCommand(runImportCommand(os.Args[2:]))
+		return
+	}
+
+	conn, err := openDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	// HOME_SCHOOL names our own school when none is marked yet
+	if err := bootstrapHomeSchool(getEnv("HOME_SCHOOL", "Jones County")); err != nil {
+		log.Fatal("Failed to load our school:", err)
+	}
+
+	startSessionSweeper(sessionSweepInterval)
+
+	// A chip timing reader at the finish line sends its reads to
+	// TIMING_ADDR, such as ":4000"; the line format can be changed for
+	// readers that differ from the default
+	if addr := os.Getenv("TIMING_ADDR"); addr != "" {
+		err := startTimingIngest(addr,
+			getEnv("TIMING_PATTERN", timing.DefaultPattern),
+			getEnv("TIMING_TIME_LAYOUT", timing.DefaultTimeLayout),
+			getEnv("TIMING_DEDUPE", "5s"))
+		if err != nil {
+			log.Fatal("Failed to start timing ingest:", err)
+		}
+	}
+
+	// Admin credentials from environment variables are only used to
+	// create the first account when the users table is empty
+	err = bootstrapAdminUser(getEnv("ADMIN_USERNAME", "admin"), getEnv("ADMIN_PASSWORD", ""))
+	if err != nil {
+		log.Fatal("Failed to create initial admin user:", err)
+	}
+
+	r := newRouter()
+
+	log.Println("Starting server on :8080")
+	r.Run(":8080")
+}
+
+// openDatabase connects to MySQL with the settings from environment
+// variables and makes it the one the server uses
+func openDatabase() (*sql.DB, error) {
 	// Build database connection string from environment variables
 	dbHost := getEnv("DB_HOST", "127.0.0.1")
 	dbUser := getEnv("DB_USER", "root")
@@ -135,18 +244,38 @@ func main() {
 	// Connect to MySQL
 	conn, err := sql.Open("mysql", dsn)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return nil, fmt.Errorf("Failed to connect to database: %w", err)
 	}
-	defer conn.Close()
 
 	// Verify connection
 	if err := conn.Ping(); err != nil {
-		log.Fatal("Failed to ping database:", err)
+		conn.Close()
+		return nil, fmt.Errorf("Failed to ping database: %w", err)
 	}
 	log.Println("Connected to MySQL database")
 
+	database = conn
 	queries = db.New(conn)
+	return conn, nil
+}
 
+// exitCommand ends a subcommand. Like the flag package, it exits with
+// status 2 when the command was not used right, after -h or a bad flag,
+// once the usage is shown.
+func exitCommand(err error) {
+	if errors.Is(err, errUsage) {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newRouter sets up the API routes
+func newRouter() *gin.Engine {
 	r := gin.Default()
 
 	// Health check endpoint
@@ -165,12 +294,11 @@ func main() {
 		})
 	})
 
-	// Admin credentials from environment variables
-	adminUsername := getEnv("ADMIN_USERNAME", "admin")
-	adminPassword := getEnv("ADMIN_PASSWORD", "admin123")
+	// Public routes are read-only and need no authentication
+	api := r.Group("/api")
 
 	// Login endpoint
-	r.POST("/api/auth/login", func(c *gin.Context) {
+	api.POST("/auth/login", func(c *gin.Context) {
 		var req struct {
 			Username string `json:"username" binding:"required"`
 			Password string `json:"password" binding:"required"`
@@ -181,87 +309,98 @@ func main() {
 			return
 		}
 
-		if req.Username != adminUsername || req.Password != adminPassword {
+		user, ok := authenticateUser(req.Username, req.Password)
+		if !ok {
 			c.JSON(401, gin.H{"error": "Invalid username or password"})
 			return
 		}
 
-		// Generate token
-		token, err := generateToken()
+		// Generate token and store its session with a 24-hour expiry
+		token, err := createSession(user.ID)
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Failed to generate token"})
 			return
 		}
 
-		// Store token with 24-hour expiry
-		tokenStoreLock.Lock()
-		tokenStore[token] = time.Now().Add(24 * time.Hour)
-		tokenStoreLock.Unlock()
-
 		c.JSON(200, gin.H{
 			"token":   token,
+			"role":    user.Role,
 			"message": "Login successful",
 		})
 	})
 
 	// Verify token endpoint
-	r.GET("/api/auth/verify", func(c *gin.Context) {
+	api.GET("/auth/verify", func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(401, gin.H{"valid": false, "error": "No token provided"})
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		token, ok := bearerToken(authHeader)
+		if !ok {
 			c.JSON(401, gin.H{"valid": false, "error": "Invalid format"})
 			return
 		}
 
-		if validateToken(parts[1]) {
-			c.JSON(200, gin.H{"valid": true})
+		if session, ok := validateToken(token); ok {
+			c.JSON(200, gin.H{"valid": true, "username": session.Username, "role": session.Role})
 		} else {
 			c.JSON(401, gin.H{"valid": false, "error": "Invalid or expired token"})
 		}
 	})
 
 	// Logout endpoint
-	r.POST("/api/auth/logout", func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" {
-			parts := strings.Split(authHeader, " ")
-			if len(parts) == 2 && parts[0] == "Bearer" {
-				tokenStoreLock.Lock()
-				delete(tokenStore, parts[1])
-				tokenStoreLock.Unlock()
+	api.POST("/auth/logout", func(c *gin.Context) {
+		if token, ok := bearerToken(c.GetHeader("Authorization")); ok {
+			if err := revokeToken(token); err != nil {
+				c.JSON(500, gin.H{"error": "Failed to log out"})
+				return
 			}
 		}
 		c.JSON(200, gin.H{"message": "Logged out successfully"})
 	})
 
-	// Get all athletes
-	r.GET("/api/athletes", func(c *gin.Context) {
-		athletes, err := queries.GetAllAthletes(context.Background())
+	// Get all athletes, as JSON or as a CSV or Excel roster
+	api.GET("/athletes", func(c *gin.Context) {
+		gender, err := queryFilter(c, "gender", validGender)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		format, err := exportFormat(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		athletes, err := queries.GetAllAthletes(context.Background(), gender)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		records, err := queries.GetAllPersonalRecords(context.Background())
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
+		recordsByAthlete := personalRecordsByAthlete(records)
 
 		response := make([]AthleteResponse, len(athletes))
 		for i, a := range athletes {
-			response[i] = AthleteResponse{
-				ID:             a.ID,
-				Name:           a.Name,
-				Grade:          a.Grade,
-				PersonalRecord: a.PersonalRecord.String,
-				Events:         a.Events.String,
-			}
+			response[i] = newAthleteResponse(a, recordsByAthlete[a.ID])
+		}
+		if format != FormatJSON {
+			writeSpreadsheet(c, format, "roster", rosterTable(response))
+			return
 		}
 		c.JSON(200, response)
 	})
 
 	// Get single athlete by ID
-	r.GET("/api/athletes/:id", func(c *gin.Context) {
+	api.GET("/athletes/:id", func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
 			c.JSON(400, gin.H{"error": "Invalid athlete ID"})
@@ -274,18 +413,24 @@ func main() {
 			return
 		}
 
-		c.JSON(200, AthleteResponse{
-			ID:             athlete.ID,
-			Name:           athlete.Name,
-			Grade:          athlete.Grade,
-			PersonalRecord: athlete.PersonalRecord.String,
-			Events:         athlete.Events.String,
-		})
+		records, err := queries.GetPersonalRecordsForAthlete(context.Background(), athlete.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, newAthleteResponse(athlete, records))
 	})
 
 	// Get all meets
-	r.GET("/api/meets", func(c *gin.Context) {
-		meets, err := queries.GetAllMeets(context.Background())
+	api.GET("/meets", func(c *gin.Context) {
+		seasonID, err := seasonFilter(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		meets, err := queries.GetAllMeets(context.Background(), seasonID)
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
@@ -293,26 +438,78 @@ func main() {
 
 		response := make([]MeetResponse, len(meets))
 		for i, m := range meets {
-			response[i] = MeetResponse{
-				ID:          m.ID,
-				Name:        m.Name,
-				Date:        m.MeetDate.Format("2006-01-02"),
-				Location:    m.Location,
-				Description: m.Description.String,
-			}
+			response[i] = newMeetResponse(m)
 		}
 		c.JSON(200, response)
 	})
 
-	// Get results for a specific meet
-	r.GET("/api/meets/:id/results", func(c *gin.Context) {
+	// Get single meet by ID with its races and their results
+	api.GET("/meets/:id", func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
 			c.JSON(400, gin.H{"error": "Invalid meet ID"})
 			return
 		}
 
-		results, err := queries.GetResultsForMeet(context.Background(), int32(id))
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		races, err := queries.GetRacesForMeet(context.Background(), meet.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		results, err := queries.GetResultsForMeet(context.Background(), db.GetResultsForMeetParams{
+			MeetID: meet.ID,
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, MeetDetailResponse{
+			MeetResponse: newMeetResponse(meet),
+			Races:        groupResultsByRace(races, results),
+		})
+	})
+
+	// Get results for a specific meet across all of its races, as JSON or
+	// as a CSV or Excel spreadsheet. The JSON is kept for clients written
+	// before meets had races.
+	api.GET("/meets/:id/results", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		format, err := exportFormat(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		gender, err := queryFilter(c, "gender", validGender)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		division, err := queryFilter(c, "division", validDivision)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		results, err := queries.GetResultsForMeet(context.Background(), db.GetResultsForMeetParams{
+			MeetID:   int32(id),
+			Gender:   gender,
+			Division: division,
+		})
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
@@ -320,25 +517,72 @@ func main() {
 
 		response := make([]ResultResponse, len(results))
 		for i, r := range results {
-			var place int32
-			if r.Place.Valid {
-				place = r.Place.Int32
-			}
-			response[i] = ResultResponse{
-				ID:          r.ID,
-				AthleteID:   r.AthleteID,
-				MeetID:      r.MeetID,
-				Time:        r.Time,
-				Place:       place,
-				AthleteName: r.AthleteName,
-			}
+			response[i] = newResultResponse(r)
 		}
-		c.JSON(200, response)
+		if format == FormatJSON {
+			c.JSON(200, response)
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		races, err := queries.GetRacesForMeet(context.Background(), meet.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		raceNames := make(map[int32]string)
+		for _, race := range races {
+			raceNames[race.ID] = race.Name
+		}
+
+		writeSpreadsheet(c, format, meetFileName(meet, "results"), resultsTable(meet.Name, response, raceNames))
 	})
 
-	// Get top 10 fastest times across all meets
-	r.GET("/api/top-times", func(c *gin.Context) {
-		times, err := queries.GetTopTimes(context.Background())
+	// Get top 10 fastest times across all meets in a season, as JSON or as
+	// a CSV or Excel spreadsheet. Filter by distance so times over
+	// different course lengths are not mixed.
+	api.GET("/top-times", func(c *gin.Context) {
+		gender, err := queryFilter(c, "gender", validGender)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		format, err := exportFormat(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		division, err := queryFilter(c, "division", validDivision)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		seasonID, err := seasonFilter(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		distance, err := distanceFilter(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		times, err := queries.GetTopTimes(context.Background(), db.GetTopTimesParams{
+			Gender:         gender,
+			Division:       division,
+			SeasonID:       seasonID,
+			DistanceMeters: distance,
+		})
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
@@ -350,73 +594,76 @@ func main() {
 			if t.Place.Valid {
 				place = t.Place.Int32
 			}
+			pace, paceMs := formatPace(t.TimeMs.Int32, t.DistanceMeters)
 			response[i] = TopTimeResponse{
 				ID:          t.ID,
-				AthleteID:   t.AthleteID,
+				AthleteID:   t.AthleteID.Int32,
 				MeetID:      t.MeetID,
-				Time:        t.Time,
+				Division:    t.Division,
+				Gender:      t.AthleteGender.String,
+				Time:        racetime.Format(t.TimeMs.Int32),
+				TimeMs:      t.TimeMs.Int32,
 				Place:       place,
 				AthleteName: t.AthleteName,
 				MeetName:    t.MeetName,
 				MeetDate:    t.MeetDate.Format("2006-01-02"),
+
+				DistanceMeters: t.DistanceMeters.Int32,
+				Pace:           pace,
+				PaceMs:         paceMs,
 			}
 		}
+		if format != FormatJSON {
+			writeSpreadsheet(c, format, "top times", topTimesTable(response))
+			return
+		}
 		c.JSON(200, response)
 	})
 
-	// Create a new result
-	r.POST("/api/results", func(c *gin.Context) {
-		var req struct {
-			AthleteID int32  `json:"athleteId" binding:"required"`
-			MeetID    int32  `json:"meetId" binding:"required"`
-			Time      string `json:"time" binding:"required"`
-			Place     int32  `json:"place"`
-		}
+	// Authenticated routes require a valid bearer token
+	authed := api.Group("")
+	authed.Use(authMiddleware())
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": err.Error()})
-			return
-		}
+	// Write routes are further restricted by the role of the logged-in user
+	admins := authed.Group("", requireRole(RoleAdmin))
+	coaches := authed.Group("", requireRole(RoleAdmin, RoleCoach))
+	statisticians := authed.Group("", requireRole(RoleAdmin, RoleCoach, RoleStatistician))
 
-		result, err := queries.CreateResult(context.Background(), db.CreateResultParams{
-			AthleteID: req.AthleteID,
-			MeetID:    req.MeetID,
-			Time:      req.Time,
-			Place:     sql.NullInt32{Int32: req.Place, Valid: req.Place > 0},
-		})
+	// Log out every session belonging to the current user
+	authed.POST("/auth/logout-all", func(c *gin.Context) {
+		err := queries.DeleteSessionsForUser(context.Background(), currentSession(c).UserID)
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
 
-		id, _ := result.LastInsertId()
-		c.JSON(201, gin.H{"id": id, "message": "Result created"})
+		c.JSON(200, gin.H{"message": "All sessions logged out"})
 	})
 
-	// Delete a result
-	r.DELETE("/api/results/:id", func(c *gin.Context) {
-		id, err := strconv.Atoi(c.Param("id"))
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid result ID"})
-			return
-		}
-
-		err = queries.DeleteResult(context.Background(), int32(id))
-		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(200, gin.H{"message": "Result deleted"})
-	})
+	registerUserRoutes(admins)
+	registerSeasonRoutes(api, coaches)
+	registerCourseRoutes(api, coaches)
+	registerRaceRoutes(api, coaches)
+	registerTeamScoreRoutes(api)
+	registerSchoolRoutes(api, coaches, statisticians)
+	registerHistoryRoutes(api)
+	registerSplitRoutes(api, statisticians)
+	registerResultRoutes(statisticians)
+	registerImportRoutes(statisticians)
+	registerReportRoutes(api)
+	registerCalendarRoutes(api)
+	registerLiveRoutes(api)
+	registerTimingRoutes(statisticians)
+	registerBibRoutes(statisticians)
+	registerSyncRoutes(statisticians)
 
 	// Create a new athlete
-	r.POST("/api/athletes", func(c *gin.Context) {
+	coaches.POST("/athletes", func(c *gin.Context) {
 		var req struct {
-			Name           string `json:"name" binding:"required"`
-			Grade          int8   `json:"grade" binding:"required"`
-			PersonalRecord string `json:"personalRecord"`
-			Events         string `json:"events"`
+			Name   string `json:"name" binding:"required"`
+			Grade  int8   `json:"grade" binding:"required"`
+			Gender string `json:"gender"`
+			Events string `json:"events"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -424,11 +671,16 @@ func main() {
 			return
 		}
 
+		if req.Gender != "" && !validGender(req.Gender) {
+			c.JSON(400, gin.H{"error": "Gender must be boys or girls"})
+			return
+		}
+
 		result, err := queries.CreateAthlete(context.Background(), db.CreateAthleteParams{
-			Name:           req.Name,
-			Grade:          req.Grade,
-			PersonalRecord: sql.NullString{String: req.PersonalRecord, Valid: req.PersonalRecord != ""},
-			Events:         sql.NullString{String: req.Events, Valid: req.Events != ""},
+			Name:   req.Name,
+			Grade:  req.Grade,
+			Gender: sql.NullString{String: req.Gender, Valid: req.Gender != ""},
+			Events: sql.NullString{String: req.Events, Valid: req.Events != ""},
 		})
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
@@ -440,7 +692,7 @@ func main() {
 	})
 
 	// Update an athlete
-	r.PUT("/api/athletes/:id", func(c *gin.Context) {
+	coaches.PUT("/athletes/:id", func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
 			c.JSON(400, gin.H{"error": "Invalid athlete ID"})
@@ -448,10 +700,10 @@ func main() {
 		}
 
 		var req struct {
-			Name           string `json:"name" binding:"required"`
-			Grade          int8   `json:"grade" binding:"required"`
-			PersonalRecord string `json:"personalRecord"`
-			Events         string `json:"events"`
+			Name   string `json:"name" binding:"required"`
+			Grade  int8   `json:"grade" binding:"required"`
+			Gender string `json:"gender"`
+			Events string `json:"events"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -459,12 +711,17 @@ func main() {
 			return
 		}
 
+		if req.Gender != "" && !validGender(req.Gender) {
+			c.JSON(400, gin.H{"error": "Gender must be boys or girls"})
+			return
+		}
+
 		err = queries.UpdateAthlete(context.Background(), db.UpdateAthleteParams{
-			ID:             int32(id),
-			Name:           req.Name,
-			Grade:          req.Grade,
-			PersonalRecord: sql.NullString{String: req.PersonalRecord, Valid: req.PersonalRecord != ""},
-			Events:         sql.NullString{String: req.Events, Valid: req.Events != ""},
+			ID:     int32(id),
+			Name:   req.Name,
+			Grade:  req.Grade,
+			Gender: sql.NullString{String: req.Gender, Valid: req.Gender != ""},
+			Events: sql.NullString{String: req.Events, Valid: req.Events != ""},
 		})
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
@@ -475,7 +732,7 @@ func main() {
 	})
 
 	// Delete an athlete
-	r.DELETE("/api/athletes/:id", func(c *gin.Context) {
+	coaches.DELETE("/athletes/:id", func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
 			c.JSON(400, gin.H{"error": "Invalid athlete ID"})
@@ -492,12 +749,14 @@ func main() {
 	})
 
 	// Create a new meet
-	r.POST("/api/meets", func(c *gin.Context) {
+	coaches.POST("/meets", func(c *gin.Context) {
 		var req struct {
 			Name        string `json:"name" binding:"required"`
 			Date        string `json:"date" binding:"required"`
 			Location    string `json:"location" binding:"required"`
 			Description string `json:"description"`
+			SeasonID    int32  `json:"seasonId"`
+			CourseID    int32  `json:"courseId"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -511,7 +770,21 @@ func main() {
 			return
 		}
 
+		seasonID, err := meetSeason(req.SeasonID, meetDate)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		courseID, err := meetCourse(req.CourseID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
 		result, err := queries.CreateMeet(context.Background(), db.CreateMeetParams{
+			SeasonID:    seasonID,
+			CourseID:    courseID,
 			Name:        req.Name,
 			MeetDate:    meetDate,
 			Location:    req.Location,
@@ -526,31 +799,8 @@ func main() {
 		c.JSON(201, gin.H{"id": id, "message": "Meet created"})
 	})
 
-	// Get single meet by ID
-	r.GET("/api/meets/:id", func(c *gin.Context) {
-		id, err := strconv.Atoi(c.Param("id"))
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid meet ID"})
-			return
-		}
-
-		meet, err := queries.GetMeetByID(context.Background(), int32(id))
-		if err != nil {
-			c.JSON(404, gin.H{"error": "Meet not found"})
-			return
-		}
-
-		c.JSON(200, MeetResponse{
-			ID:          meet.ID,
-			Name:        meet.Name,
-			Date:        meet.MeetDate.Format("2006-01-02"),
-			Location:    meet.Location,
-			Description: meet.Description.String,
-		})
-	})
-
 	// Update a meet
-	r.PUT("/api/meets/:id", func(c *gin.Context) {
+	coaches.PUT("/meets/:id", func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
 			c.JSON(400, gin.H{"error": "Invalid meet ID"})
@@ -562,6 +812,8 @@ func main() {
 			Date        string `json:"date" binding:"required"`
 			Location    string `json:"location" binding:"required"`
 			Description string `json:"description"`
+			SeasonID    int32  `json:"seasonId"`
+			CourseID    int32  `json:"courseId"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -575,8 +827,22 @@ func main() {
 			return
 		}
 
+		seasonID, err := meetSeason(req.SeasonID, meetDate)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		courseID, err := meetCourse(req.CourseID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
 		err = queries.UpdateMeet(context.Background(), db.UpdateMeetParams{
 			ID:          int32(id),
+			SeasonID:    seasonID,
+			CourseID:    courseID,
 			Name:        req.Name,
 			MeetDate:    meetDate,
 			Location:    req.Location,
@@ -587,11 +853,17 @@ func main() {
 			return
 		}
 
+		// The course may have changed, moving results to another distance
+		if err := rebuildPersonalRecords(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
 		c.JSON(200, gin.H{"message": "Meet updated"})
 	})
 
 	// Delete a meet
-	r.DELETE("/api/meets/:id", func(c *gin.Context) {
+	coaches.DELETE("/meets/:id", func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
 			c.JSON(400, gin.H{"error": "Invalid meet ID"})
@@ -604,11 +876,147 @@ func main() {
 			return
 		}
 
+		// Records set at this meet were deleted with its results
+		if err := rebuildPersonalRecords(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
 		c.JSON(200, gin.H{"message": "Meet deleted"})
 	})
 
-	log.Println("Starting server on :8080")
-	r.Run(":8080")
+	// Create a new result; see resultRequest. Only finishers need a time,
+	// and splits may be recorded with the result. A runner or place already
+	// in the race is a conflict.
+	statisticians.POST("/results", func(c *gin.Context) {
+		var req resultRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+
+		row, err := req.validate(q)
+		if isResultError(err) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		e, err := entriesForRace(q, make(map[int32]*raceEntries), row.params.RaceID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if err := e.check(row.params.AthleteID, row.params.CompetitorID, row.params.Place); err != nil {
+			c.JSON(409, gin.H{"error": err.Error()})
+			return
+		}
+		changes := snapshotMeet(q, row.meetID)
+
+		id, err := createResult(q, row)
+		if isDuplicateKey(err) {
+			c.JSON(409, gin.H{"error": "The runner already has a result in this race"})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		raceID := row.params.RaceID
+		athleteIDs := make(map[int32]bool)
+		if req.AthleteID != 0 {
+			athleteIDs[req.AthleteID] = true
+		}
+		if err := finishResults(q, map[int32]bool{raceID: true}, athleteIDs); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		changes.publish()
+
+		var isPR bool
+		if req.AthleteID != 0 {
+			isPR, err = isPersonalRecord(req.AthleteID, id)
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.JSON(201, gin.H{"id": id, "raceId": raceID, "isPersonalRecord": isPR, "message": "Result created"})
+	})
+
+	// Delete a result
+	statisticians.DELETE("/results/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid result ID"})
+			return
+		}
+
+		result, err := queries.GetResultByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Result not found"})
+			return
+		}
+
+		race, err := queries.GetRaceByID(context.Background(), result.RaceID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		// The results left in the race are re-ranked with the delete, and
+		// those whose places change get new versions, as for a synced delete
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+		changes := snapshotMeet(q, race.MeetID)
+
+		if err := q.DeleteResult(context.Background(), int32(id)); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		athleteIDs := make(map[int32]bool)
+		if result.AthleteID.Valid {
+			athleteIDs[result.AthleteID.Int32] = true
+		}
+		if err := finishResults(q, map[int32]bool{result.RaceID: true}, athleteIDs); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		changes.publish()
+
+		c.JSON(200, gin.H{"message": "Result deleted"})
+	})
+
+	return r
 }
 
 func getEnv(key, fallback string) string {
@@ -617,3 +1025,10 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// isDuplicateKey reports whether err is MySQL refusing a row that would
+// break a unique key, such as when a concurrent request inserted it first
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
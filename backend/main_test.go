@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	homeSchool.Store(&testHomeSchool)
+	os.Exit(m.Run())
+}
+
+// testHomeSchool is our own school in tests
+var testHomeSchool = db.School{ID: 1, Name: "Jones County", Abbreviation: sql.NullString{String: "JC", Valid: true}, IsHome: true}
+
+// fakeDB stands in for MySQL in handler tests. Queries are answered by
+// their sqlc name: rows holds the rows a query returns, and any query
+// without an entry returns none. Statements succeed unless execErrs holds
+// an error for them. execs lists the statements run, by name, and each
+// commit as COMMIT; execArgs and execSQL hold the arguments and text of
+// the last run of each.
+type fakeDB struct {
+	mu       sync.Mutex
+	rows     map[string]func(args []driver.NamedValue) [][]driver.Value
+	execErrs map[string]error
+	execs    []string
+	execArgs map[string][]driver.NamedValue
+	execSQL  map[string]string
+	sessions map[string]string
+	lastID   int64
+}
+
+// useFakeDB points the database and queries globals at a new fakeDB for
+// the rest of the test
+func useFakeDB(t *testing.T) *fakeDB {
+	fake := &fakeDB{
+		rows:     make(map[string]func(args []driver.NamedValue) [][]driver.Value),
+		execErrs: make(map[string]error),
+		execArgs: make(map[string][]driver.NamedValue),
+		execSQL:  make(map[string]string),
+		sessions: make(map[string]string),
+	}
+	fake.rows["GetActiveSession"] = fake.activeSession
+
+	oldDatabase, oldQueries := database, queries
+	conn := sql.OpenDB(fake)
+	database, queries = conn, db.New(conn)
+	t.Cleanup(func() {
+		conn.Close()
+		database, queries = oldDatabase, oldQueries
+	})
+	return fake
+}
+
+// login makes a session for a user with role and returns its token
+func (f *fakeDB) login(role string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token := "token-" + role
+	f.sessions[hashToken(token)] = role
+	return token
+}
+
+func (f *fakeDB) activeSession(args []driver.NamedValue) [][]driver.Value {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	hash, _ := args[0].Value.(string)
+	role, ok := f.sessions[hash]
+	if !ok {
+		return nil
+	}
+	return [][]driver.Value{{int64(1), hash, int64(1), time.Now().Add(time.Hour), time.Now(), role, role}}
+}
+
+var queryName = regexp.MustCompile(`^-- name: (\w+)`)
+
+func (f *fakeDB) query(query string, args []driver.NamedValue) [][]driver.Value {
+	m := queryName.FindStringSubmatch(query)
+	if m == nil {
+		return nil
+	}
+	f.mu.Lock()
+	rows := f.rows[m[1]]
+	f.mu.Unlock()
+	if rows == nil {
+		return nil
+	}
+	return rows(args)
+}
+
+// exec runs a statement, giving each one a new insert ID
+func (f *fakeDB) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m := queryName.FindStringSubmatch(query); m != nil {
+		if f.execErrs[m[1]] != nil {
+			return nil, f.execErrs[m[1]]
+		}
+		f.execs = append(f.execs, m[1])
+		f.execArgs[m[1]] = args
+		f.execSQL[m[1]] = query
+	}
+	f.lastID++
+	return fakeResult{id: f.lastID}, nil
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeDB does not prepare statements")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{c.db}, nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{rows: c.db.query(query, args)}, nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.db.exec(query, args)
+}
+
+type fakeResult struct{ id int64 }
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.execs = append(tx.db.execs, "COMMIT")
+	return nil
+}
+
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// serve sends a request with an empty JSON body to the router, with token
+// as its bearer token unless it is empty
+func serve(r http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	return serveJSON(r, method, path, token, "{}")
+}
+
+// serveJSON sends a request with a JSON body to the router
+func serveJSON(r http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// publicWriteRoutes are the write routes that need no login
+var publicWriteRoutes = map[string]bool{
+	"POST /api/auth/login":  true,
+	"POST /api/auth/logout": true,
+}
+
+var routeParam = regexp.MustCompile(`:\w+`)
+
+// writeRoutes lists the router's routes that change data and need a login,
+// with their parameters filled in
+func writeRoutes(r *gin.Engine) []gin.RouteInfo {
+	var routes []gin.RouteInfo
+	for _, route := range r.Routes() {
+		switch route.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			continue
+		}
+		if publicWriteRoutes[route.Method+" "+route.Path] {
+			continue
+		}
+		route.Path = routeParam.ReplaceAllString(route.Path, "1")
+		routes = append(routes, route)
+	}
+	return routes
+}
@@ -0,0 +1,13 @@
+-- Adds persistent login sessions to an existing database.
+-- New databases get this table from schema.sql.
+
+CREATE TABLE sessions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL,
+    username VARCHAR(100) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_token_hash (token_hash),
+    INDEX idx_sessions_username (username),
+    INDEX idx_sessions_expires_at (expires_at)
+);
@@ -0,0 +1,9 @@
+-- Adds a role to each user. Accounts that existed before roles had full
+-- access, so they are made admins; new accounts must be given a role.
+
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'read_only'
+        CHECK (role IN ('admin', 'coach', 'statistician', 'read_only'))
+        AFTER password_hash;
+
+UPDATE users SET role = 'admin';
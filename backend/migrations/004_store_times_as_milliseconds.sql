@@ -0,0 +1,55 @@
+-- Stores race times as integer milliseconds so they sort numerically.
+--
+-- Times in mm:ss, mm:ss.f and h:mm:ss form are converted. Anything else
+-- (for example "17:5" or "DNF") cannot be, so it is left NULL and the
+-- original text is copied into unconverted_times before the old text
+-- columns are dropped; the results are kept, without a time, and can be
+-- corrected afterwards. Migration 011 marks them as not finished.
+
+ALTER TABLE results ADD COLUMN time_ms INT AFTER time;
+ALTER TABLE athletes ADD COLUMN personal_record_ms INT AFTER personal_record;
+
+UPDATE results
+SET time_ms = ROUND(1000 * (
+    CAST(SUBSTRING_INDEX(time, ':', 1) AS UNSIGNED) * 60
+    + CAST(SUBSTRING_INDEX(time, ':', -1) AS DECIMAL(6, 3))))
+WHERE TRIM(time) REGEXP '^[0-9]{1,2}:[0-5][0-9](\\.[0-9]{1,3})?$';
+
+UPDATE results
+SET time_ms = ROUND(1000 * (
+    CAST(SUBSTRING_INDEX(time, ':', 1) AS UNSIGNED) * 3600
+    + CAST(SUBSTRING_INDEX(SUBSTRING_INDEX(time, ':', 2), ':', -1) AS UNSIGNED) * 60
+    + CAST(SUBSTRING_INDEX(time, ':', -1) AS DECIMAL(6, 3))))
+WHERE TRIM(time) REGEXP '^[0-9]{1,2}:[0-5][0-9]:[0-5][0-9](\\.[0-9]{1,3})?$';
+
+UPDATE athletes
+SET personal_record_ms = ROUND(1000 * (
+    CAST(SUBSTRING_INDEX(personal_record, ':', 1) AS UNSIGNED) * 60
+    + CAST(SUBSTRING_INDEX(personal_record, ':', -1) AS DECIMAL(6, 3))))
+WHERE TRIM(personal_record) REGEXP '^[0-9]{1,2}:[0-5][0-9](\\.[0-9]{1,3})?$';
+
+UPDATE athletes
+SET personal_record_ms = ROUND(1000 * (
+    CAST(SUBSTRING_INDEX(personal_record, ':', 1) AS UNSIGNED) * 3600
+    + CAST(SUBSTRING_INDEX(SUBSTRING_INDEX(personal_record, ':', 2), ':', -1) AS UNSIGNED) * 60
+    + CAST(SUBSTRING_INDEX(personal_record, ':', -1) AS DECIMAL(6, 3))))
+WHERE TRIM(personal_record) REGEXP '^[0-9]{1,2}:[0-5][0-9]:[0-5][0-9](\\.[0-9]{1,3})?$';
+
+-- Rows that could not be converted, kept so they can be corrected by hand
+CREATE TABLE unconverted_times (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    source_table VARCHAR(20) NOT NULL,
+    source_id INT NOT NULL,
+    original_value VARCHAR(10),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO unconverted_times (source_table, source_id, original_value)
+SELECT 'results', id, time FROM results WHERE time_ms IS NULL;
+
+INSERT INTO unconverted_times (source_table, source_id, original_value)
+SELECT 'athletes', id, personal_record FROM athletes
+WHERE personal_record IS NOT NULL AND personal_record <> '' AND personal_record_ms IS NULL;
+
+ALTER TABLE results DROP COLUMN time;
+ALTER TABLE athletes DROP COLUMN personal_record;
@@ -0,0 +1,16 @@
+-- Adds athlete gender and result division so boys' and girls' races and
+-- varsity, JV, middle school and open races can be ranked separately.
+-- Existing athletes have no gender until a coach edits them; existing
+-- results are assumed to be varsity. Grades 6-8 are allowed for middle
+-- school runners.
+
+ALTER TABLE athletes
+    ADD COLUMN gender VARCHAR(5) CHECK (gender IN ('boys', 'girls')) AFTER grade;
+
+ALTER TABLE athletes DROP CHECK athletes_chk_1;
+ALTER TABLE athletes ADD CHECK (grade BETWEEN 6 AND 12);
+
+ALTER TABLE results
+    ADD COLUMN division VARCHAR(20) NOT NULL DEFAULT 'varsity'
+        CHECK (division IN ('varsity', 'jv', 'middle_school', 'open'))
+        AFTER meet_id;
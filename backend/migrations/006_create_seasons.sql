@@ -0,0 +1,27 @@
+-- Adds seasons and links meets to them. One season is created for each
+-- calendar year that already has meets, and the latest is made active.
+
+CREATE TABLE seasons (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    year INT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_year (year),
+    CHECK (end_date >= start_date)
+);
+
+ALTER TABLE meets
+    ADD COLUMN season_id INT AFTER id,
+    ADD FOREIGN KEY (season_id) REFERENCES seasons(id) ON DELETE SET NULL;
+
+INSERT INTO seasons (year, start_date, end_date)
+SELECT DISTINCT YEAR(meet_date), MAKEDATE(YEAR(meet_date), 1), LAST_DAY(MAKEDATE(YEAR(meet_date), 365))
+FROM meets;
+
+UPDATE meets m
+JOIN seasons s ON s.year = YEAR(m.meet_date)
+SET m.season_id = s.id;
+
+UPDATE seasons SET is_active = (year = (SELECT latest FROM (SELECT MAX(year) AS latest FROM seasons) AS t));
@@ -0,0 +1,24 @@
+-- Replaces the hand-entered athletes.personal_record_ms with personal
+-- records derived from results. Each athlete's fastest result (earliest
+-- entered on a tie) becomes their PR.
+
+CREATE TABLE personal_records (
+    athlete_id INT NOT NULL PRIMARY KEY,
+    result_id INT NOT NULL,
+    time_ms INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE
+);
+
+INSERT INTO personal_records (athlete_id, result_id, time_ms)
+SELECT athlete_id, id, time_ms
+FROM (
+    SELECT athlete_id, id, time_ms,
+           ROW_NUMBER() OVER (PARTITION BY athlete_id ORDER BY time_ms, id) AS rank_in_athlete
+    FROM results
+    WHERE time_ms IS NOT NULL
+) ranked
+WHERE rank_in_athlete = 1;
+
+ALTER TABLE athletes DROP COLUMN personal_record_ms;
@@ -0,0 +1,42 @@
+-- Adds courses so results can be compared by distance. Meets are linked to
+-- a course; existing meets have none until a coach picks one, so their
+-- results keep sharing a single record of unknown distance. Personal
+-- records are re-keyed by athlete and distance and rebuilt.
+
+CREATE TABLE courses (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    venue VARCHAR(200) NOT NULL,
+    distance_meters INT NOT NULL CHECK (distance_meters > 0),
+    surface VARCHAR(20) CHECK (surface IN ('grass', 'trail', 'mixed', 'road', 'track')),
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE meets
+    ADD COLUMN course_id INT AFTER season_id,
+    ADD FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE SET NULL;
+
+DROP TABLE personal_records;
+
+CREATE TABLE personal_records (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    athlete_id INT NOT NULL,
+    distance_meters INT,
+    result_id INT NOT NULL,
+    time_ms INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_athlete_distance (athlete_id, distance_meters)
+);
+
+INSERT INTO personal_records (athlete_id, distance_meters, result_id, time_ms)
+SELECT athlete_id, NULL, id, time_ms
+FROM (
+    SELECT athlete_id, id, time_ms,
+           ROW_NUMBER() OVER (PARTITION BY athlete_id ORDER BY time_ms, id) AS rank_in_athlete
+    FROM results
+    WHERE time_ms IS NOT NULL
+) ranked
+WHERE rank_in_athlete = 1;
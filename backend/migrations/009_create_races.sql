@@ -0,0 +1,55 @@
+-- Adds races under meets and attaches results to a race instead of a meet.
+-- Existing results are split into one race per meet, division and athlete
+-- gender, named like "Varsity Boys". A race's division replaces the
+-- division stored on each result.
+
+CREATE TABLE races (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT NOT NULL,
+    course_id INT,
+    name VARCHAR(100) NOT NULL,
+    gender VARCHAR(5) CHECK (gender IN ('boys', 'girls')),
+    division VARCHAR(20) NOT NULL DEFAULT 'varsity' CHECK (division IN ('varsity', 'jv', 'middle_school', 'open')),
+    start_time DATETIME,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE SET NULL
+);
+
+INSERT INTO races (meet_id, name, gender, division)
+SELECT DISTINCT r.meet_id,
+       CONCAT(
+           CASE r.division
+               WHEN 'varsity' THEN 'Varsity'
+               WHEN 'jv' THEN 'JV'
+               WHEN 'middle_school' THEN 'Middle School'
+               ELSE 'Open'
+           END,
+           CASE a.gender
+               WHEN 'boys' THEN ' Boys'
+               WHEN 'girls' THEN ' Girls'
+               ELSE ''
+           END
+       ),
+       a.gender, r.division
+FROM results r
+JOIN athletes a ON r.athlete_id = a.id;
+
+ALTER TABLE results ADD COLUMN race_id INT AFTER meet_id;
+
+UPDATE results r
+JOIN athletes a ON r.athlete_id = a.id
+JOIN races ra ON ra.meet_id = r.meet_id AND ra.division = r.division AND ra.gender <=> a.gender
+SET r.race_id = ra.id;
+
+ALTER TABLE results
+    MODIFY race_id INT NOT NULL,
+    ADD FOREIGN KEY (race_id) REFERENCES races(id) ON DELETE CASCADE,
+    ADD UNIQUE KEY unique_race_result (athlete_id, race_id);
+
+ALTER TABLE results
+    DROP FOREIGN KEY results_ibfk_2,
+    DROP INDEX unique_result,
+    DROP CHECK results_chk_1,
+    DROP COLUMN meet_id,
+    DROP COLUMN division;
@@ -0,0 +1,28 @@
+-- Adds other schools and their runners so a race's full finish order can
+-- be stored. A result now belongs to either one of our athletes or a
+-- competitor. Existing places are left as entered; they are derived from
+-- times once a race's results include other schools' runners.
+
+CREATE TABLE schools (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    abbreviation VARCHAR(10),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_school_name (name)
+);
+
+CREATE TABLE competitors (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    school_id INT,
+    gender VARCHAR(5) CHECK (gender IN ('boys', 'girls')),
+    grade TINYINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (school_id) REFERENCES schools(id) ON DELETE SET NULL
+);
+
+ALTER TABLE results
+    MODIFY athlete_id INT NULL,
+    ADD COLUMN competitor_id INT AFTER athlete_id,
+    ADD FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE CASCADE,
+    ADD UNIQUE KEY unique_race_competitor (competitor_id, race_id);
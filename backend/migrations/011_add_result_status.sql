@@ -0,0 +1,12 @@
+-- Records runners who did not finish, did not start, were disqualified or
+-- ran unattached. Only finishers need a time, and only they are placed,
+-- ranked and counted for personal records.
+
+ALTER TABLE results
+    ADD COLUMN status VARCHAR(12) NOT NULL DEFAULT 'finished' CHECK (status IN ('finished', 'unattached', 'dnf', 'dns', 'dq')) AFTER race_id,
+    MODIFY time_ms INT NULL;
+
+-- Results left without a time by migration 004, because their legacy time
+-- (such as "DNF") could not be converted, are recorded as not finished,
+-- since every finisher has a time
+UPDATE results SET status = 'dnf' WHERE time_ms IS NULL;
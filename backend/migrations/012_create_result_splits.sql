@@ -0,0 +1,12 @@
+-- Adds split times, the elapsed time at points along the course such as
+-- the mile and two mile marks, recorded alongside a result.
+
+CREATE TABLE result_splits (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    result_id INT NOT NULL,
+    distance_meters INT NOT NULL CHECK (distance_meters > 0),
+    elapsed_ms INT NOT NULL CHECK (elapsed_ms > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_result_split (result_id, distance_meters)
+);
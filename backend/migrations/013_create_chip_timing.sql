@@ -0,0 +1,35 @@
+-- Adds chip timing: the chip each runner wears at a meet, the gun time of
+-- each timed race, and the provisional finishes recorded from chip reads
+-- until an official confirms them as results.
+
+ALTER TABLE races ADD COLUMN gun_time DATETIME(3) AFTER start_time;
+
+CREATE TABLE chip_assignments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT NOT NULL,
+    chip VARCHAR(32) NOT NULL,
+    athlete_id INT,
+    competitor_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_meet_chip (meet_id, chip)
+);
+
+CREATE TABLE timed_finishes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    race_id INT NOT NULL,
+    chip VARCHAR(32) NOT NULL,
+    athlete_id INT,
+    competitor_id INT,
+    elapsed_ms INT NOT NULL CHECK (elapsed_ms > 0),
+    read_count INT NOT NULL DEFAULT 1,
+    result_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (race_id) REFERENCES races(id) ON DELETE CASCADE,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE SET NULL,
+    FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE SET NULL,
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE SET NULL,
+    UNIQUE KEY unique_race_chip (race_id, chip)
+);
@@ -0,0 +1,18 @@
+-- Adds bib numbers, assigned to runners for a whole season or for a single
+-- meet, so results can be entered by bib at the finish chute.
+
+CREATE TABLE bib_assignments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT,
+    season_id INT,
+    bib INT NOT NULL CHECK (bib > 0),
+    athlete_id INT,
+    competitor_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (season_id) REFERENCES seasons(id) ON DELETE CASCADE,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_meet_bib (meet_id, bib),
+    UNIQUE KEY unique_season_bib (season_id, bib)
+);
@@ -0,0 +1,19 @@
+-- Adds offline sync of results: a version on each result that its edits,
+-- and any change of place when its race is re-ranked, increase; the UUID
+-- of results created offline; and a record of the sync operations applied
+-- so that resent ones are not applied twice.
+
+ALTER TABLE results
+    ADD COLUMN client_id CHAR(36) AFTER place,
+    ADD COLUMN version INT NOT NULL DEFAULT 1 AFTER client_id,
+    ADD UNIQUE KEY unique_client_id (client_id);
+
+CREATE TABLE sync_operations (
+    id CHAR(36) PRIMARY KEY,
+    user_id INT,
+    operation VARCHAR(6) NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    result_id INT NOT NULL,
+    version INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
@@ -0,0 +1,18 @@
+-- Rebuilds personal records so that, of equal times, the earliest race
+-- holds the record, as in the PR progression of an athlete's history,
+-- rather than the result entered first.
+
+DELETE FROM personal_records;
+
+INSERT INTO personal_records (athlete_id, distance_meters, result_id, time_ms)
+SELECT athlete_id, distance_meters, id, time_ms
+FROM (
+    SELECT r.athlete_id, c.distance_meters, r.id, r.time_ms,
+           ROW_NUMBER() OVER (PARTITION BY r.athlete_id, c.distance_meters ORDER BY r.time_ms, m.meet_date, r.id) AS rank_at_distance
+    FROM results r
+    JOIN races ra ON r.race_id = ra.id
+    JOIN meets m ON ra.meet_id = m.id
+    LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+    WHERE r.athlete_id IS NOT NULL AND r.status IN ('finished', 'unattached')
+) ranked
+WHERE rank_at_distance = 1;
@@ -0,0 +1,7 @@
+-- Marks our own school as a school like any other, so that imported rows
+-- are matched to it by ID rather than by a prefix of its name, which also
+-- caught other schools such as "Jones County Middle School". The server
+-- marks the school named by HOME_SCHOOL, adding it if needed, when no
+-- school is marked.
+
+ALTER TABLE schools ADD COLUMN is_home BOOLEAN NOT NULL DEFAULT FALSE AFTER abbreviation;
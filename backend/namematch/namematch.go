@@ -0,0 +1,112 @@
+// Package namematch matches runner and school names typed by hand or
+// exported by timing systems against the names we have on file.
+//
+// Names are compared after normalizing: case, punctuation and extra spaces
+// are ignored, and "Last, First" is read as "First Last". Names that still
+// differ are scored by edit distance, so small typos and spelling variants
+// still match.
+package namematch
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Threshold is the lowest similarity counted as a match
+const Threshold = 0.85
+
+// Normalize puts a name in the form used for comparison
+func Normalize(name string) string {
+	if last, first, ok := strings.Cut(name, ","); ok {
+		name = first + " " + last
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '\'' || r == '.':
+			// "O'Neal" and "St. John" match "ONeal" and "St John"
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Similarity scores how alike two names are, from 0 for nothing in common
+// to 1 for names that normalize to the same thing. Word order is ignored.
+func Similarity(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	if a == b {
+		return 1
+	}
+	score := ratio(a, b)
+	if sorted := ratio(sortWords(a), sortWords(b)); sorted > score {
+		score = sorted
+	}
+	return score
+}
+
+// Best finds the candidate most like name. It reports no match when the
+// best candidate is below Threshold or another candidate scores as well,
+// since then the name is ambiguous.
+func Best(name string, candidates []string) (index int, score float64, ok bool) {
+	index = -1
+	tied := false
+	for i, c := range candidates {
+		s := Similarity(name, c)
+		switch {
+		case s > score:
+			index, score, tied = i, s, false
+		case s == score && s > 0:
+			tied = true
+		}
+	}
+	if index < 0 || score < Threshold || tied {
+		return -1, score, false
+	}
+	return index, score, true
+}
+
+// ratio is one less the edit distance between a and b as a share of the
+// longer name's length
+func ratio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(distance(ra, rb))/float64(longest)
+}
+
+// distance is the Levenshtein distance between a and b
+func distance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func sortWords(s string) string {
+	words := strings.Fields(s)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
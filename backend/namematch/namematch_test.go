@@ -0,0 +1,116 @@
+package namematch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Jane Smith", "jane smith"},
+		{"  JANE   SMITH ", "jane smith"},
+		{"Smith, Jane", "jane smith"},
+		{"  O'Neal ,  Sarah ", "sarah oneal"},
+		{"St. John-Smith, Mary", "mary st john smith"},
+		{"Smith,Jane", "jane smith"},
+		{"Renée Côté", "renée côté"},
+		{"Tyler Jackson II", "tyler jackson ii"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Jane Smith", "jane smith", 1},
+		{"Smith, Jane", "Jane Smith", 1},
+		{"Sarah O'Neal", "Sarah ONeal", 1},
+		// Word order is ignored
+		{"Smith Jane", "Jane Smith", 1},
+		// One letter off in ten
+		{"Jon Smith", "John Smith", 0.9},
+		{"Kate Brown", "Katy Browne", 1 - 2.0/11},
+		{"abc", "xyz", 0},
+		{"", "", 1},
+	}
+	for _, tt := range tests {
+		got := Similarity(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if back := Similarity(tt.b, tt.a); back != got {
+			t.Errorf("Similarity(%q, %q) = %v, but %v the other way", tt.a, tt.b, got, back)
+		}
+	}
+}
+
+func TestBest(t *testing.T) {
+	roster := []string{"Jane Smith", "Sarah O'Neal", "Kate Brown", "Tyler Jackson"}
+
+	tests := []struct {
+		name  string
+		index int
+		ok    bool
+	}{
+		{"Jane Smith", 0, true},
+		{"SMITH, JANE", 0, true},
+		{"Sarah ONeal", 1, true},
+		// One letter off in thirteen is a match, two in ten is not
+		{"Tylor Jackson", 3, true},
+		{"Jon Smith", -1, false},
+		// Two letters off in eleven, 0.82, is under the threshold
+		{"Katy Browne", -1, false},
+		{"Mary Doe", -1, false},
+		{"", -1, false},
+	}
+	for _, tt := range tests {
+		index, score, ok := Best(tt.name, roster)
+		if index != tt.index || ok != tt.ok {
+			t.Errorf("Best(%q) = %d (%.2f), %v; want %d, %v", tt.name, index, score, ok, tt.index, tt.ok)
+		}
+	}
+}
+
+func TestBestThreshold(t *testing.T) {
+	if index, _, ok := Best("Jon Smith", []string{"John Smith"}); !ok || index != 0 {
+		t.Errorf("Best at 0.9 = %d, %v; want a match", index, ok)
+	}
+	// 17 of 20 letters alike scores exactly 0.85
+	name, candidate := "abcdefghijklmnopqrst", "abcdefghijklmnopqxyz"
+	if s := Similarity(name, candidate); math.Abs(s-Threshold) > 1e-9 {
+		t.Fatalf("Similarity = %v, want %v", s, Threshold)
+	}
+	if _, _, ok := Best(name, []string{candidate}); !ok {
+		t.Error("a score equal to the threshold is not a match")
+	}
+	if _, _, ok := Best(name, []string{"abcdefghijklmnopwxyz"}); ok {
+		t.Error("a score of 0.8 is a match")
+	}
+}
+
+func TestBestTies(t *testing.T) {
+	// "Jane Smith" is one letter from both, so it is ambiguous
+	if index, score, ok := Best("Jane Smith", []string{"Jake Smith", "Jana Smith"}); ok || index != -1 {
+		t.Errorf("Best with a tie = %d (%.2f), %v; want no match", index, score, ok)
+	}
+	// Two runners with the same name cannot be told apart
+	if _, _, ok := Best("Jane Smith", []string{"Jane Smith", "Smith, Jane"}); ok {
+		t.Error("Best with a duplicate name matched")
+	}
+	// A better candidate after a tie still wins
+	if index, _, ok := Best("Jane Smith", []string{"Jake Smith", "Jana Smith", "Jane Smith"}); !ok || index != 2 {
+		t.Errorf("Best = %d, %v; want 2, true", index, ok)
+	}
+	if index, _, ok := Best("Jane Smith", nil); ok || index != -1 {
+		t.Errorf("Best with no candidates = %d, %v", index, ok)
+	}
+}
@@ -0,0 +1,76 @@
+package pdf_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"jones-county-xc/backend/pdf"
+	"jones-county-xc/backend/pdf/pdftest"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestDocumentStructure(t *testing.T) {
+	doc := pdf.New("Results (Varsity) \\ Café")
+	doc.Created = time.Date(2024, 9, 14, 15, 30, 0, 0, time.UTC)
+
+	p := doc.AddPage()
+	p.Text(54, 738, pdf.HelveticaBold, 18, "Jones County Invitational")
+	p.TextRight(558, 738, pdf.Helvetica, 9, "Page 1 of 2")
+	p.Line(54, 728, 558, 728, 0.5)
+	p.Text(54, 700, pdf.Helvetica, 9, "O'Neal (JC) – Zoë → 1st")
+	p.Text(54, 688, pdf.Helvetica, 9, "")
+
+	p = doc.AddPage()
+	p.Text(54, 738, pdf.Helvetica, 9, pdf.Truncate(pdf.Helvetica, 9, 60, "Mary Persons High School"))
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "testdata/document.golden", buf.Bytes())
+}
+
+// checkGolden compares a PDF's structure with a golden file
+func checkGolden(t *testing.T, golden string, data []byte) {
+	t.Helper()
+	got, err := pdftest.Dump(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("structure differs from %s; run with -update to accept it\ngot:\n%s", golden, got)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		font pdf.Font
+		size float64
+		s    string
+		want float64
+	}{
+		{pdf.Helvetica, 10, "", 0},
+		{pdf.Helvetica, 10, "Jones", 5 + 5.56 + 5.56 + 5.56 + 5},
+		{pdf.HelveticaBold, 10, "Jones", 5.56 + 6.11 + 6.11 + 5.56 + 5.56},
+		{pdf.Helvetica, 1000, "→", 556},
+	}
+	for _, tt := range tests {
+		got := pdf.TextWidth(tt.font, tt.size, tt.s)
+		if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("TextWidth(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package pdftest renders the PDF files written by package pdf as text, for
+// comparing their structure against golden files in tests.
+//
+// The file's cross-reference table and trailer are checked rather than
+// shown, since their offsets change whenever any object does, and content
+// streams are shown inflated with their compressed length left out, so the
+// text does not depend on the zlib implementation.
+package pdftest
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	objectStart = regexp.MustCompile(`^(\d+) 0 obj\n`)
+	streamDict  = regexp.MustCompile(`/Length (\d+)`)
+	trailer     = regexp.MustCompile(`trailer\n<< /Size (\d+) /Root 1 0 R /Info 3 0 R >>\nstartxref\n(\d+)\n%%EOF\n$`)
+)
+
+// Dump checks that data is a well-formed PDF file and returns its objects
+// as text, one after another in file order
+func Dump(data []byte) (string, error) {
+	header, _, ok := bytes.Cut(data, []byte("\n"))
+	if !ok || !bytes.HasPrefix(header, []byte("%PDF-")) {
+		return "", fmt.Errorf("pdftest: no PDF header")
+	}
+
+	m := trailer.FindSubmatch(data)
+	if m == nil {
+		return "", fmt.Errorf("pdftest: no trailer")
+	}
+	size, _ := strconv.Atoi(string(m[1]))
+	xref, _ := strconv.Atoi(string(m[2]))
+	if xref >= len(data) || !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		return "", fmt.Errorf("pdftest: startxref %d does not point to the cross-reference table", xref)
+	}
+	offsets, err := readXref(data[xref:], size)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.Write(header)
+	out.WriteString("\n")
+	for i, start := range offsets {
+		end := xref
+		if i+1 < len(offsets) {
+			end = offsets[i+1]
+		}
+		if start >= end {
+			return "", fmt.Errorf("pdftest: object %d is out of order", i+1)
+		}
+		obj := data[start:end]
+		m := objectStart.FindSubmatch(obj)
+		if m == nil || string(m[1]) != strconv.Itoa(i+1) {
+			return "", fmt.Errorf("pdftest: object %d is not at offset %d", i+1, start)
+		}
+		body, ok := bytes.CutSuffix(obj[len(m[0]):], []byte("\nendobj\n"))
+		if !ok {
+			return "", fmt.Errorf("pdftest: object %d is not ended", i+1)
+		}
+		text, err := object(body)
+		if err != nil {
+			return "", fmt.Errorf("pdftest: object %d: %w", i+1, err)
+		}
+		fmt.Fprintf(&out, "%d 0 obj\n%s\n", i+1, text)
+	}
+	return out.String(), nil
+}
+
+// readXref reads the offsets of objects 1 to size-1 from the table
+func readXref(table []byte, size int) ([]int, error) {
+	lines := strings.Split(string(table), "\n")
+	if len(lines) < size+2 || lines[1] != fmt.Sprintf("0 %d", size) {
+		return nil, fmt.Errorf("pdftest: cross-reference table does not list %d objects", size)
+	}
+	offsets := make([]int, size-1)
+	for i := range offsets {
+		entry := lines[i+3]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			return nil, fmt.Errorf("pdftest: bad cross-reference entry %q", entry)
+		}
+		offsets[i], _ = strconv.Atoi(entry[:10])
+	}
+	return offsets, nil
+}
+
+// object renders an object's body, inflating its stream if it has one
+func object(body []byte) (string, error) {
+	dict, stream, ok := bytes.Cut(body, []byte("\nstream\n"))
+	if !ok {
+		return string(body), nil
+	}
+	stream, ok = bytes.CutSuffix(stream, []byte("\nendstream"))
+	if !ok {
+		return "", fmt.Errorf("stream is not ended")
+	}
+	m := streamDict.FindSubmatch(dict)
+	if m == nil {
+		return "", fmt.Errorf("stream has no length")
+	}
+	if length, _ := strconv.Atoi(string(m[1])); length != len(stream) {
+		return "", fmt.Errorf("stream is %d bytes, not %d", len(stream), length)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return "", err
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	dict = streamDict.ReplaceAll(dict, []byte("/Length _"))
+	return fmt.Sprintf("%s\nstream\n%sendstream", dict, content), nil
+}
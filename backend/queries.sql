@@ -1,67 +1,568 @@
 -- name: GetAllAthletes :many
-SELECT id, name, grade, personal_record, events, created_at
+SELECT id, name, grade, gender, events, created_at
 FROM athletes
+WHERE (sqlc.narg('gender') IS NULL OR gender = sqlc.narg('gender'))
 ORDER BY name;
 
 -- name: GetAthleteByID :one
-SELECT id, name, grade, personal_record, events, created_at
+SELECT id, name, grade, gender, events, created_at
 FROM athletes
 WHERE id = ?;
 
 -- name: GetAllMeets :many
-SELECT id, name, meet_date, location, description, created_at
+SELECT id, season_id, course_id, name, meet_date, location, description, created_at
 FROM meets
+WHERE (sqlc.narg('season_id') IS NULL OR season_id = sqlc.narg('season_id'))
 ORDER BY meet_date;
 
 -- name: GetResultsForMeet :many
-SELECT r.id, r.athlete_id, r.meet_id, r.time, r.place, r.created_at,
-       a.name as athlete_name
+SELECT r.id, r.athlete_id, r.competitor_id, r.race_id, ra.meet_id, ra.division, r.status, r.time_ms, r.place, r.client_id, r.version, r.created_at,
+       COALESCE(a.name, co.name, '') as athlete_name, COALESCE(a.gender, co.gender) as athlete_gender,
+       sc.name as school_name, c.distance_meters, pr.result_id as pr_result_id
 FROM results r
-JOIN athletes a ON r.athlete_id = a.id
-WHERE r.meet_id = ?
-ORDER BY r.place;
+LEFT JOIN athletes a ON r.athlete_id = a.id
+LEFT JOIN competitors co ON r.competitor_id = co.id
+LEFT JOIN schools sc ON co.school_id = sc.id
+JOIN races ra ON r.race_id = ra.id
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+LEFT JOIN personal_records pr ON pr.result_id = r.id
+WHERE ra.meet_id = sqlc.arg('meet_id')
+  AND (sqlc.narg('gender') IS NULL OR a.gender = sqlc.narg('gender') OR co.gender = sqlc.narg('gender'))
+  AND (sqlc.narg('division') IS NULL OR ra.division = sqlc.narg('division'))
+ORDER BY ra.start_time, ra.id, r.place IS NULL, r.place, r.time_ms;
+
+-- name: GetResultsForRace :many
+SELECT r.id, r.athlete_id, r.competitor_id, r.race_id, ra.meet_id, ra.division, r.status, r.time_ms, r.place, r.client_id, r.version, r.created_at,
+       COALESCE(a.name, co.name, '') as athlete_name, COALESCE(a.gender, co.gender) as athlete_gender,
+       sc.name as school_name, c.distance_meters, pr.result_id as pr_result_id
+FROM results r
+LEFT JOIN athletes a ON r.athlete_id = a.id
+LEFT JOIN competitors co ON r.competitor_id = co.id
+LEFT JOIN schools sc ON co.school_id = sc.id
+JOIN races ra ON r.race_id = ra.id
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+LEFT JOIN personal_records pr ON pr.result_id = r.id
+WHERE r.race_id = ?
+ORDER BY r.place IS NULL, r.place, r.time_ms;
 
 -- name: CreateResult :execresult
-INSERT INTO results (athlete_id, meet_id, time, place)
-VALUES (?, ?, ?, ?);
+INSERT INTO results (athlete_id, competitor_id, race_id, status, time_ms, place, client_id)
+VALUES (?, ?, ?, ?, ?, ?, ?);
 
 -- name: CreateAthlete :execresult
-INSERT INTO athletes (name, grade, personal_record, events)
+INSERT INTO athletes (name, grade, gender, events)
 VALUES (?, ?, ?, ?);
 
 -- name: UpdateAthlete :exec
 UPDATE athletes
-SET name = ?, grade = ?, personal_record = ?, events = ?
+SET name = ?, grade = ?, gender = ?, events = ?
 WHERE id = ?;
 
 -- name: DeleteAthlete :exec
 DELETE FROM athletes WHERE id = ?;
 
 -- name: CreateMeet :execresult
-INSERT INTO meets (name, meet_date, location, description)
-VALUES (?, ?, ?, ?);
+INSERT INTO meets (season_id, course_id, name, meet_date, location, description)
+VALUES (?, ?, ?, ?, ?, ?);
 
 -- name: UpdateMeet :exec
 UPDATE meets
-SET name = ?, meet_date = ?, location = ?, description = ?
+SET season_id = ?, course_id = ?, name = ?, meet_date = ?, location = ?, description = ?
 WHERE id = ?;
 
 -- name: DeleteMeet :exec
 DELETE FROM meets WHERE id = ?;
 
 -- name: GetMeetByID :one
-SELECT id, name, meet_date, location, description, created_at
+SELECT id, season_id, course_id, name, meet_date, location, description, created_at
 FROM meets
 WHERE id = ?;
 
+-- name: GetMeetByNameDate :one
+SELECT id, season_id, course_id, name, meet_date, location, description, created_at
+FROM meets
+WHERE name = ? AND meet_date = ?
+ORDER BY id
+LIMIT 1;
+
+-- name: GetResultByID :one
+SELECT id, athlete_id, competitor_id, race_id, status, time_ms, place, client_id, version, created_at
+FROM results
+WHERE id = ?;
+
+-- name: GetResultByClientID :one
+SELECT id, athlete_id, competitor_id, race_id, status, time_ms, place, client_id, version, created_at
+FROM results
+WHERE client_id = ?;
+
+-- name: UpdateResultVersion :execrows
+UPDATE results
+SET athlete_id = ?, competitor_id = ?, race_id = ?, status = ?, time_ms = ?, place = ?, version = version + 1
+WHERE id = ? AND version = ?;
+
+-- name: IncrementResultVersion :exec
+UPDATE results SET version = version + 1 WHERE id = ?;
+
+-- name: DeleteResultVersion :execrows
+DELETE FROM results WHERE id = ? AND version = ?;
+
 -- name: DeleteResult :exec
 DELETE FROM results WHERE id = ?;
 
 -- name: GetTopTimes :many
-SELECT r.id, r.athlete_id, r.meet_id, r.time, r.place, r.created_at,
-       a.name as athlete_name, m.name as meet_name, m.meet_date
+SELECT r.id, r.athlete_id, r.race_id, ra.meet_id, ra.division, r.status, r.time_ms, r.place, r.created_at,
+       a.name as athlete_name, a.gender as athlete_gender, m.name as meet_name, m.meet_date,
+       c.distance_meters
 FROM results r
 JOIN athletes a ON r.athlete_id = a.id
-JOIN meets m ON r.meet_id = m.id
-ORDER BY r.time
+JOIN races ra ON r.race_id = ra.id
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+WHERE (sqlc.narg('gender') IS NULL OR a.gender = sqlc.narg('gender'))
+  AND (sqlc.narg('division') IS NULL OR ra.division = sqlc.narg('division'))
+  AND (sqlc.narg('season_id') IS NULL OR m.season_id = sqlc.narg('season_id'))
+  AND (sqlc.narg('distance_meters') IS NULL OR c.distance_meters = sqlc.narg('distance_meters'))
+  AND r.status IN ('finished', 'unattached')
+ORDER BY r.time_ms
 LIMIT 10;
+
+-- name: CreateSession :exec
+INSERT INTO sessions (token_hash, user_id, expires_at)
+VALUES (?, ?, ?);
+
+-- name: GetActiveSession :one
+SELECT s.id, s.token_hash, s.user_id, s.expires_at, s.created_at,
+       u.username, u.role
+FROM sessions s
+JOIN users u ON s.user_id = u.id
+WHERE s.token_hash = ? AND s.expires_at > ? AND u.disabled = FALSE;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE token_hash = ?;
+
+-- name: DeleteSessionsForUser :exec
+DELETE FROM sessions WHERE user_id = ?;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions WHERE expires_at <= ?;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: GetAllUsers :many
+SELECT id, username, password_hash, role, disabled, created_at
+FROM users
+ORDER BY username;
+
+-- name: GetUserByID :one
+SELECT id, username, password_hash, role, disabled, created_at
+FROM users
+WHERE id = ?;
+
+-- name: GetUserByUsername :one
+SELECT id, username, password_hash, role, disabled, created_at
+FROM users
+WHERE username = ?;
+
+-- name: CreateUser :execresult
+INSERT INTO users (username, password_hash, role)
+VALUES (?, ?, ?);
+
+-- name: UpdateUserPassword :exec
+UPDATE users SET password_hash = ? WHERE id = ?;
+
+-- name: UpdateUserRole :exec
+UPDATE users SET role = ? WHERE id = ?;
+
+-- name: SetUserDisabled :exec
+UPDATE users SET disabled = ? WHERE id = ?;
+
+-- name: GetAllSeasons :many
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+ORDER BY year DESC;
+
+-- name: GetSeasonByID :one
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+WHERE id = ?;
+
+-- name: GetSeasonByYear :one
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+WHERE year = ?;
+
+-- name: GetActiveSeason :one
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+WHERE is_active = TRUE
+LIMIT 1;
+
+-- name: GetSeasonForDate :one
+SELECT id, year, start_date, end_date, is_active, created_at
+FROM seasons
+WHERE start_date <= sqlc.arg('meet_date') AND end_date >= sqlc.arg('meet_date')
+ORDER BY start_date DESC
+LIMIT 1;
+
+-- name: CreateSeason :execresult
+INSERT INTO seasons (year, start_date, end_date)
+VALUES (?, ?, ?);
+
+-- name: UpdateSeason :exec
+UPDATE seasons
+SET year = ?, start_date = ?, end_date = ?
+WHERE id = ?;
+
+-- name: DeleteSeason :exec
+DELETE FROM seasons WHERE id = ?;
+
+-- name: SetActiveSeason :exec
+UPDATE seasons SET is_active = (id = sqlc.arg('id'));
+
+-- name: GetResultsForAthlete :many
+SELECT r.id, r.athlete_id, r.race_id, ra.meet_id, ra.division, r.status, r.time_ms, r.place, r.created_at,
+       ra.name as race_name, m.name as meet_name, m.meet_date, m.location as meet_location,
+       m.season_id, s.year as season_year, c.distance_meters
+FROM results r
+JOIN races ra ON r.race_id = ra.id
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN seasons s ON m.season_id = s.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+WHERE r.athlete_id = ?
+ORDER BY m.meet_date, r.id;
+
+-- name: GetAllPersonalRecords :many
+SELECT id, athlete_id, distance_meters, result_id, time_ms, created_at
+FROM personal_records
+ORDER BY athlete_id, distance_meters;
+
+-- name: GetPersonalRecordsForAthlete :many
+SELECT id, athlete_id, distance_meters, result_id, time_ms, created_at
+FROM personal_records
+WHERE athlete_id = ?
+ORDER BY distance_meters;
+
+-- name: DeletePersonalRecordsForAthlete :exec
+DELETE FROM personal_records WHERE athlete_id = ?;
+
+-- name: CreatePersonalRecordsForAthlete :exec
+INSERT INTO personal_records (athlete_id, distance_meters, result_id, time_ms)
+SELECT athlete_id, distance_meters, id, time_ms
+FROM (
+    SELECT r.athlete_id, c.distance_meters, r.id, r.time_ms,
+           ROW_NUMBER() OVER (PARTITION BY c.distance_meters ORDER BY r.time_ms, m.meet_date, r.id) AS rank_at_distance
+    FROM results r
+    JOIN races ra ON r.race_id = ra.id
+    JOIN meets m ON ra.meet_id = m.id
+    LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+    WHERE r.athlete_id = ? AND r.status IN ('finished', 'unattached')
+) ranked
+WHERE rank_at_distance = 1;
+
+-- name: DeleteAllPersonalRecords :exec
+DELETE FROM personal_records;
+
+-- name: CreateAllPersonalRecords :exec
+INSERT INTO personal_records (athlete_id, distance_meters, result_id, time_ms)
+SELECT athlete_id, distance_meters, id, time_ms
+FROM (
+    SELECT r.athlete_id, c.distance_meters, r.id, r.time_ms,
+           ROW_NUMBER() OVER (PARTITION BY r.athlete_id, c.distance_meters ORDER BY r.time_ms, m.meet_date, r.id) AS rank_at_distance
+    FROM results r
+    JOIN races ra ON r.race_id = ra.id
+    JOIN meets m ON ra.meet_id = m.id
+    LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+    WHERE r.athlete_id IS NOT NULL AND r.status IN ('finished', 'unattached')
+) ranked
+WHERE rank_at_distance = 1;
+
+-- name: GetAllCourses :many
+SELECT id, name, venue, distance_meters, surface, notes, created_at
+FROM courses
+ORDER BY name;
+
+-- name: GetCourseByID :one
+SELECT id, name, venue, distance_meters, surface, notes, created_at
+FROM courses
+WHERE id = ?;
+
+-- name: CreateCourse :execresult
+INSERT INTO courses (name, venue, distance_meters, surface, notes)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: UpdateCourse :exec
+UPDATE courses
+SET name = ?, venue = ?, distance_meters = ?, surface = ?, notes = ?
+WHERE id = ?;
+
+-- name: DeleteCourse :exec
+DELETE FROM courses WHERE id = ?;
+
+-- name: GetRacesForMeet :many
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE meet_id = ?
+ORDER BY start_time, id;
+
+-- name: GetRacesForSeason :many
+SELECT ra.id, ra.meet_id, ra.course_id, ra.name, ra.gender, ra.division, ra.start_time, ra.gun_time, ra.created_at
+FROM races ra
+JOIN meets m ON m.id = ra.meet_id
+WHERE (sqlc.narg('season_id') IS NULL OR m.season_id = sqlc.narg('season_id'))
+ORDER BY ra.start_time, ra.id;
+
+-- name: GetRaceByID :one
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE id = ?;
+
+-- name: GetRaceForMeetDivision :one
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE meet_id = ? AND division = ? AND gender <=> ?
+ORDER BY id
+LIMIT 1;
+
+-- name: GetRaceForMeetName :one
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE meet_id = ? AND name = ?
+ORDER BY id
+LIMIT 1;
+
+-- name: CreateRace :execresult
+INSERT INTO races (meet_id, course_id, name, gender, division, start_time)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: UpdateRace :exec
+UPDATE races
+SET course_id = ?, name = ?, gender = ?, division = ?, start_time = ?
+WHERE id = ?;
+
+-- name: DeleteRace :exec
+DELETE FROM races WHERE id = ?;
+
+-- name: CountCompetitorResultsForRace :one
+SELECT COUNT(*) FROM results WHERE race_id = ? AND competitor_id IS NOT NULL;
+
+-- name: BumpRerankedResultVersions :exec
+UPDATE results r
+LEFT JOIN (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY time_ms, id) AS finish_place
+    FROM results
+    WHERE race_id = sqlc.arg('race_id') AND status IN ('finished', 'unattached')
+) ranked ON r.id = ranked.id
+SET r.version = r.version + 1
+WHERE r.race_id = sqlc.arg('race_id') AND NOT (r.place <=> ranked.finish_place);
+
+-- name: UpdateRacePlaces :exec
+UPDATE results r
+LEFT JOIN (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY time_ms, id) AS finish_place
+    FROM results
+    WHERE race_id = sqlc.arg('race_id') AND status IN ('finished', 'unattached')
+) ranked ON r.id = ranked.id
+SET r.place = ranked.finish_place
+WHERE r.race_id = sqlc.arg('race_id');
+
+-- name: GetAllSchools :many
+SELECT id, name, abbreviation, is_home, created_at
+FROM schools
+ORDER BY name;
+
+-- name: GetSchoolByID :one
+SELECT id, name, abbreviation, is_home, created_at
+FROM schools
+WHERE id = ?;
+
+-- name: GetHomeSchool :one
+SELECT id, name, abbreviation, is_home, created_at
+FROM schools
+WHERE is_home
+ORDER BY id
+LIMIT 1;
+
+-- name: CreateHomeSchool :exec
+INSERT INTO schools (name, is_home)
+VALUES (?, TRUE)
+ON DUPLICATE KEY UPDATE is_home = TRUE;
+
+-- name: CreateSchool :execresult
+INSERT INTO schools (name, abbreviation)
+VALUES (?, ?);
+
+-- name: UpdateSchool :exec
+UPDATE schools SET name = ?, abbreviation = ? WHERE id = ?;
+
+-- name: DeleteSchool :exec
+DELETE FROM schools WHERE id = ?;
+
+-- name: GetAllCompetitors :many
+SELECT id, name, school_id, gender, grade, created_at
+FROM competitors
+WHERE (sqlc.narg('school_id') IS NULL OR school_id = sqlc.narg('school_id'))
+ORDER BY name;
+
+-- name: GetCompetitorByID :one
+SELECT id, name, school_id, gender, grade, created_at
+FROM competitors
+WHERE id = ?;
+
+-- name: CreateCompetitor :execresult
+INSERT INTO competitors (name, school_id, gender, grade)
+VALUES (?, ?, ?, ?);
+
+-- name: UpdateCompetitor :exec
+UPDATE competitors
+SET name = ?, school_id = ?, gender = ?, grade = ?
+WHERE id = ?;
+
+-- name: DeleteCompetitor :exec
+DELETE FROM competitors WHERE id = ?;
+
+-- name: GetRacesForCompetitor :many
+SELECT DISTINCT ra.id, ra.meet_id, ra.course_id, ra.name, ra.gender, ra.division, ra.start_time, ra.gun_time, ra.created_at
+FROM races ra
+JOIN results r ON r.race_id = ra.id
+WHERE r.competitor_id = ?
+ORDER BY ra.id;
+
+-- name: GetRaceDistance :one
+SELECT c.distance_meters
+FROM races ra
+JOIN meets m ON ra.meet_id = m.id
+LEFT JOIN courses c ON c.id = COALESCE(ra.course_id, m.course_id)
+WHERE ra.id = ?;
+
+-- name: GetSplitsForResult :many
+SELECT id, result_id, distance_meters, elapsed_ms, created_at
+FROM result_splits
+WHERE result_id = ?
+ORDER BY distance_meters;
+
+-- name: GetSplitsForAthlete :many
+SELECT s.id, s.result_id, s.distance_meters, s.elapsed_ms, s.created_at
+FROM result_splits s
+JOIN results r ON s.result_id = r.id
+WHERE r.athlete_id = ?
+ORDER BY s.result_id, s.distance_meters;
+
+-- name: CreateSplit :exec
+INSERT INTO result_splits (result_id, distance_meters, elapsed_ms)
+VALUES (?, ?, ?);
+
+-- name: DeleteSplitsForResult :exec
+DELETE FROM result_splits WHERE result_id = ?;
+
+-- name: UpdateRaceGunTime :exec
+UPDATE races SET gun_time = ? WHERE id = ?;
+
+-- name: GetRacesStartedBetween :many
+SELECT id, meet_id, course_id, name, gender, division, start_time, gun_time, created_at
+FROM races
+WHERE gun_time >= sqlc.arg('since') AND gun_time < sqlc.arg('until')
+ORDER BY gun_time DESC, id DESC;
+
+-- name: GetChipAssignmentsForMeet :many
+SELECT ca.id, ca.chip, ca.athlete_id, ca.competitor_id, a.name AS athlete_name, co.name AS competitor_name
+FROM chip_assignments ca
+LEFT JOIN athletes a ON ca.athlete_id = a.id
+LEFT JOIN competitors co ON ca.competitor_id = co.id
+WHERE ca.meet_id = ?
+ORDER BY ca.chip;
+
+-- name: GetChipAssignment :one
+SELECT id, meet_id, chip, athlete_id, competitor_id, created_at
+FROM chip_assignments
+WHERE meet_id = ? AND chip = ?;
+
+-- name: CreateChipAssignment :exec
+INSERT INTO chip_assignments (meet_id, chip, athlete_id, competitor_id)
+VALUES (?, ?, ?, ?);
+
+-- name: DeleteChipAssignmentsForMeet :exec
+DELETE FROM chip_assignments WHERE meet_id = ?;
+
+-- name: RecordTimedFinish :exec
+INSERT INTO timed_finishes (race_id, chip, athlete_id, competitor_id, elapsed_ms)
+VALUES (?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    read_count = read_count + 1,
+    elapsed_ms = IF(result_id IS NULL, LEAST(elapsed_ms, VALUES(elapsed_ms)), elapsed_ms);
+
+-- name: GetTimedFinishesForRace :many
+SELECT tf.id, tf.chip, tf.athlete_id, tf.competitor_id, tf.elapsed_ms, tf.read_count, tf.result_id,
+       a.name AS athlete_name, co.name AS competitor_name
+FROM timed_finishes tf
+LEFT JOIN athletes a ON tf.athlete_id = a.id
+LEFT JOIN competitors co ON tf.competitor_id = co.id
+WHERE tf.race_id = ?
+ORDER BY tf.elapsed_ms, tf.id;
+
+-- name: GetTimedFinishByID :one
+SELECT id, race_id, chip, athlete_id, competitor_id, elapsed_ms, read_count, result_id, created_at
+FROM timed_finishes
+WHERE id = ?;
+
+-- name: AssignTimedFinish :exec
+UPDATE timed_finishes SET athlete_id = ?, competitor_id = ? WHERE id = ?;
+
+-- name: ConfirmTimedFinish :exec
+UPDATE timed_finishes SET result_id = ? WHERE id = ?;
+
+-- name: DeleteTimedFinish :exec
+DELETE FROM timed_finishes WHERE id = ?;
+
+-- name: GetBibAssignmentsForMeet :many
+SELECT b.bib, b.meet_id, b.athlete_id, b.competitor_id, a.name AS athlete_name, COALESCE(a.grade, co.grade) AS grade,
+       co.name AS competitor_name, s.name AS school_name
+FROM bib_assignments b
+JOIN meets m ON m.id = sqlc.arg('meet_id')
+LEFT JOIN athletes a ON b.athlete_id = a.id
+LEFT JOIN competitors co ON b.competitor_id = co.id
+LEFT JOIN schools s ON co.school_id = s.id
+WHERE b.meet_id = m.id
+   OR (b.season_id = m.season_id AND NOT EXISTS (
+       SELECT 1 FROM bib_assignments o
+       WHERE o.meet_id = m.id
+         AND (o.athlete_id = b.athlete_id OR o.competitor_id = b.competitor_id)))
+ORDER BY b.bib, b.meet_id IS NULL;
+
+-- name: GetBibAssignmentsForSeason :many
+SELECT b.bib, b.athlete_id, b.competitor_id, a.name AS athlete_name, co.name AS competitor_name
+FROM bib_assignments b
+LEFT JOIN athletes a ON b.athlete_id = a.id
+LEFT JOIN competitors co ON b.competitor_id = co.id
+WHERE b.season_id = ?
+ORDER BY b.bib;
+
+-- name: GetMeetBibAssignmentsForSeason :many
+SELECT b.meet_id, m.name AS meet_name, b.bib, b.athlete_id, b.competitor_id, a.name AS athlete_name, co.name AS competitor_name
+FROM bib_assignments b
+JOIN meets m ON m.id = b.meet_id
+LEFT JOIN athletes a ON b.athlete_id = a.id
+LEFT JOIN competitors co ON b.competitor_id = co.id
+WHERE m.season_id = ?
+ORDER BY m.meet_date, m.id, b.bib;
+
+-- name: CreateBibAssignment :exec
+INSERT INTO bib_assignments (meet_id, season_id, bib, athlete_id, competitor_id)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: DeleteBibAssignmentsForMeet :exec
+DELETE FROM bib_assignments WHERE meet_id = ?;
+
+-- name: DeleteBibAssignmentsForSeason :exec
+DELETE FROM bib_assignments WHERE season_id = ?;
+
+-- name: GetSyncOperation :one
+SELECT id, user_id, operation, result_id, version, created_at
+FROM sync_operations
+WHERE id = ?;
+
+-- name: CreateSyncOperation :exec
+INSERT INTO sync_operations (id, user_id, operation, result_id, version)
+VALUES (?, ?, ?, ?, ?);
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// divisionNames are the display names of divisions, used to name races
+var divisionNames = map[string]string{
+	DivisionVarsity:      "Varsity",
+	DivisionJV:           "JV",
+	DivisionMiddleSchool: "Middle School",
+	DivisionOpen:         "Open",
+}
+
+type RaceResponse struct {
+	ID       int32  `json:"id"`
+	MeetID   int32  `json:"meetId"`
+	CourseID int32  `json:"courseId,omitempty"`
+	Name     string `json:"name"`
+	Gender   string `json:"gender"`
+	Division string `json:"division"`
+	// StartTime is the race's start on the meet day, as "15:04"
+	StartTime string `json:"startTime,omitempty"`
+	// GunTime is when a chip timed race actually started, as "15:04:05.000"
+	GunTime string `json:"gunTime,omitempty"`
+}
+
+func newRaceResponse(r db.Race) RaceResponse {
+	response := RaceResponse{
+		ID:       r.ID,
+		MeetID:   r.MeetID,
+		CourseID: r.CourseID.Int32,
+		Name:     r.Name,
+		Gender:   r.Gender.String,
+		Division: r.Division,
+	}
+	if r.StartTime.Valid {
+		response.StartTime = r.StartTime.Time.Format("15:04")
+	}
+	if r.GunTime.Valid {
+		response.GunTime = r.GunTime.Time.Format(gunTimeLayout)
+	}
+	return response
+}
+
+// RaceResultsResponse is a race together with its results
+type RaceResultsResponse struct {
+	RaceResponse
+	Results []ResultResponse `json:"results"`
+}
+
+// MeetDetailResponse is a meet with its races and their results
+type MeetDetailResponse struct {
+	MeetResponse
+	Races []RaceResultsResponse `json:"races"`
+}
+
+// defaultRaceName names a race after its division and gender, such as
+// "Varsity Boys" or "Middle School"
+func defaultRaceName(division string, gender sql.NullString) string {
+	name := divisionNames[division]
+	switch gender.String {
+	case GenderBoys:
+		name += " Boys"
+	case GenderGirls:
+		name += " Girls"
+	}
+	return name
+}
+
+// resultRace finds the race at a meet for a division and gender, creating
+// it if needed using q, which may be bound to a transaction. It lets
+// results be entered against a meet, as they were before meets had races.
+func resultRace(q *db.Queries, meetID int32, division string, gender sql.NullString) (int32, error) {
+	race, err := q.GetRaceForMeetDivision(context.Background(), db.GetRaceForMeetDivisionParams{
+		MeetID:   meetID,
+		Division: division,
+		Gender:   gender,
+	})
+	if err == nil {
+		return race.ID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	_, err = q.GetMeetByID(context.Background(), meetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, resultError("Meet not found")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := q.CreateRace(context.Background(), db.CreateRaceParams{
+		MeetID:   meetID,
+		Name:     defaultRaceName(division, gender),
+		Gender:   gender,
+		Division: division,
+	})
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int32(id), err
+}
+
+// updateRacePlaces derives places from finishing times once a race's full
+// field has been recorded, which is taken to be as soon as it includes any
+// other school's runners. Until then the places entered by hand are kept.
+// Each result whose place changes gets a new version, so synced clients
+// see it; versions are bumped first, by comparing against the old places,
+// since MySQL does not order the assignments of a multi-table UPDATE. q
+// may be bound to a transaction.
+func updateRacePlaces(q *db.Queries, raceID int32) error {
+	count, err := q.CountCompetitorResultsForRace(context.Background(), raceID)
+	if err != nil || count == 0 {
+		return err
+	}
+	if err := q.BumpRerankedResultVersions(context.Background(), raceID); err != nil {
+		return err
+	}
+	return q.UpdateRacePlaces(context.Background(), raceID)
+}
+
+// raceStartTime combines a "15:04" start time with the meet date
+func raceStartTime(meetDate time.Time, startTime string) (sql.NullTime, error) {
+	if startTime == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse("15:04", startTime)
+	if err != nil {
+		return sql.NullTime{}, errors.New("Invalid start time format. Use HH:MM")
+	}
+	start := time.Date(meetDate.Year(), meetDate.Month(), meetDate.Day(), t.Hour(), t.Minute(), 0, 0, meetDate.Location())
+	return sql.NullTime{Time: start, Valid: true}, nil
+}
+
+// raceRequest is the body for creating or updating a race
+type raceRequest struct {
+	Name      string `json:"name"`
+	Gender    string `json:"gender"`
+	Division  string `json:"division"`
+	CourseID  int32  `json:"courseId"`
+	StartTime string `json:"startTime"`
+}
+
+// validate checks a race request, filling in its default division and
+// name, and resolves its gender and course
+func (req *raceRequest) validate() (sql.NullString, sql.NullInt32, error) {
+	if req.Division == "" {
+		req.Division = DivisionVarsity
+	}
+	if !validDivision(req.Division) {
+		return sql.NullString{}, sql.NullInt32{}, errors.New("Division must be varsity, jv, middle_school or open")
+	}
+	if req.Gender != "" && !validGender(req.Gender) {
+		return sql.NullString{}, sql.NullInt32{}, errors.New("Gender must be boys or girls")
+	}
+	gender := sql.NullString{String: req.Gender, Valid: req.Gender != ""}
+
+	courseID, err := meetCourse(req.CourseID)
+	if err != nil {
+		return sql.NullString{}, sql.NullInt32{}, err
+	}
+
+	if req.Name == "" {
+		req.Name = defaultRaceName(req.Division, gender)
+	}
+	return gender, courseID, nil
+}
+
+// groupResultsByRace pairs each race with its results
+func groupResultsByRace(races []db.Race, results []db.GetResultsForMeetRow) []RaceResultsResponse {
+	response := make([]RaceResultsResponse, len(races))
+	index := make(map[int32]int)
+	for i, race := range races {
+		response[i] = RaceResultsResponse{
+			RaceResponse: newRaceResponse(race),
+			Results:      []ResultResponse{},
+		}
+		index[race.ID] = i
+	}
+	for _, r := range results {
+		if i, ok := index[r.RaceID]; ok {
+			response[i].Results = append(response[i].Results, newResultResponse(r))
+		}
+	}
+	return response
+}
+
+// registerRaceRoutes adds race routes to the public and coach groups
+func registerRaceRoutes(api, coaches *gin.RouterGroup) {
+	// Get the races at a meet
+	api.GET("/meets/:id/races", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		races, err := queries.GetRacesForMeet(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]RaceResponse, len(races))
+		for i, r := range races {
+			response[i] = newRaceResponse(r)
+		}
+		c.JSON(200, response)
+	})
+
+	// Get a single race with its results
+	api.GET("/races/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid race ID"})
+			return
+		}
+
+		race, err := queries.GetRaceByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Race not found"})
+			return
+		}
+
+		results, err := queries.GetResultsForRace(context.Background(), race.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := RaceResultsResponse{
+			RaceResponse: newRaceResponse(race),
+			Results:      make([]ResultResponse, len(results)),
+		}
+		for i, r := range results {
+			response.Results[i] = newResultResponse(db.GetResultsForMeetRow(r))
+		}
+		c.JSON(200, response)
+	})
+
+	// Add a race to a meet
+	coaches.POST("/meets/:id/races", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		var req raceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		gender, courseID, err := req.validate()
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		startTime, err := raceStartTime(meet.MeetDate, req.StartTime)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := queries.CreateRace(context.Background(), db.CreateRaceParams{
+			MeetID:    meet.ID,
+			CourseID:  courseID,
+			Name:      req.Name,
+			Gender:    gender,
+			Division:  req.Division,
+			StartTime: startTime,
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		raceID, _ := result.LastInsertId()
+		c.JSON(201, gin.H{"id": raceID, "message": "Race created"})
+	})
+
+	// Update a race. Its course may change, moving results to another
+	// distance, so personal records are rebuilt. Splits recorded for the
+	// old distance are kept as they are.
+	coaches.PUT("/races/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid race ID"})
+			return
+		}
+
+		race, err := queries.GetRaceByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Race not found"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), race.MeetID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req raceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		gender, courseID, err := req.validate()
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		startTime, err := raceStartTime(meet.MeetDate, req.StartTime)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = queries.UpdateRace(context.Background(), db.UpdateRaceParams{
+			CourseID:  courseID,
+			Name:      req.Name,
+			Gender:    gender,
+			Division:  req.Division,
+			StartTime: startTime,
+			ID:        race.ID,
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := rebuildPersonalRecords(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Race updated"})
+	})
+
+	// Delete a race and its results
+	coaches.DELETE("/races/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid race ID"})
+			return
+		}
+
+		err = queries.DeleteRace(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Records set in this race were deleted with its results
+		if err := rebuildPersonalRecords(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Race deleted"})
+	})
+}
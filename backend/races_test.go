@@ -0,0 +1,171 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestUpdateRacePlacesBumpsVersions checks that results whose places
+// change when a race is re-ranked get new versions, even when nothing but
+// the place changed. MySQL may make the assignments of a multi-table
+// UPDATE in any order, so the version is bumped by a statement of its own,
+// comparing the old places with the new, before the places are written.
+func TestUpdateRacePlacesBumpsVersions(t *testing.T) {
+	fake := useFakeDB(t)
+	fake.rows["CountCompetitorResultsForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(3)}}
+	}
+
+	if err := updateRacePlaces(queries, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"BumpRerankedResultVersions", "UpdateRacePlaces"}
+	if !reflect.DeepEqual(fake.execs, want) {
+		t.Fatalf("ran %v, want %v", fake.execs, want)
+	}
+	for _, name := range want {
+		for _, arg := range fake.execArgs[name] {
+			if arg.Value != int64(2) {
+				t.Errorf("%s ran for race %v, want 2", name, arg.Value)
+			}
+		}
+	}
+
+	assignments, where, _ := strings.Cut(setClause(fake.execSQL["BumpRerankedResultVersions"]), "WHERE")
+	if strings.TrimSpace(assignments) != "r.version = r.version + 1" {
+		t.Errorf("the version statement sets %q", assignments)
+	}
+	if !strings.Contains(where, "NOT (r.place <=> ranked.finish_place)") {
+		t.Errorf("the version statement bumps rows where %q, not those whose place changes", where)
+	}
+	if places := setClause(fake.execSQL["UpdateRacePlaces"]); strings.Contains(places, "version") {
+		t.Errorf("the place statement also sets the version: %q", places)
+	}
+}
+
+// setClause is the part of an UPDATE statement after its SET
+func setClause(query string) string {
+	_, set, _ := strings.Cut(query, "\nSET ")
+	return set
+}
+
+func TestUpdateRacePlacesKeepsEnteredPlaces(t *testing.T) {
+	fake := useFakeDB(t)
+	fake.rows["CountCompetitorResultsForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(0)}}
+	}
+
+	// With only our own runners in the race, the places entered stand
+	if err := updateRacePlaces(queries, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+}
+
+// multiRaceMeet stubs a meet with varsity boys and girls races at
+// different distances and a middle school race with no results yet
+func multiRaceMeet(fake *fakeDB) {
+	fake.rows["GetMeetByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, nil, nil, "Jones County Invitational", time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC), "Gray", nil, time.Now()}}
+	}
+	fake.rows["GetRacesForMeet"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{
+			{int64(10), int64(1), nil, "Varsity Boys", GenderBoys, DivisionVarsity, nil, nil, nil},
+			{int64(11), int64(1), nil, "Varsity Girls", GenderGirls, DivisionVarsity, nil, nil, nil},
+			{int64(12), int64(1), nil, "Middle School", nil, DivisionMiddleSchool, nil, nil, nil},
+		}
+	}
+	fake.rows["GetResultsForMeet"] = func([]driver.NamedValue) [][]driver.Value {
+		row := func(id, raceID int64, gender string, distance int64) []driver.Value {
+			return []driver.Value{id, id, nil, raceID, int64(1), DivisionVarsity, StatusFinished, int64(1100000), int64(1), nil, int64(1), nil, "Runner", gender, nil, distance, nil}
+		}
+		return [][]driver.Value{
+			row(1, 10, GenderBoys, 5000),
+			row(2, 10, GenderBoys, 5000),
+			row(3, 11, GenderGirls, 4000),
+			// A result in a race no longer at the meet is left out
+			row(4, 99, GenderGirls, 4000),
+		}
+	}
+}
+
+func TestMeetDetailGroupsResultsByRace(t *testing.T) {
+	fake := useFakeDB(t)
+	multiRaceMeet(fake)
+	w := serve(newRouter(), "GET", "/api/meets/1", "")
+	if w.Code != 200 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+
+	var meet MeetDetailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &meet); err != nil {
+		t.Fatal(err)
+	}
+	if meet.ID != 1 || len(meet.Races) != 3 {
+		t.Fatalf("got %+v, want meet 1 with 3 races", meet)
+	}
+	want := map[string][]int32{"Varsity Boys": {1, 2}, "Varsity Girls": {3}, "Middle School": {}}
+	for _, race := range meet.Races {
+		ids := []int32{}
+		for _, r := range race.Results {
+			ids = append(ids, r.ID)
+			if r.RaceID != race.ID {
+				t.Errorf("result %d of race %d is under %s", r.ID, r.RaceID, race.Name)
+			}
+		}
+		if !reflect.DeepEqual(ids, want[race.Name]) {
+			t.Errorf("%s has results %v, want %v", race.Name, ids, want[race.Name])
+		}
+	}
+	// Each race keeps its own distance, and a race without results has
+	// an empty list rather than none
+	if meet.Races[1].Results[0].DistanceMeters != 4000 {
+		t.Errorf("the girls' race is %dm", meet.Races[1].Results[0].DistanceMeters)
+	}
+	if !strings.Contains(w.Body.String(), `"name":"Middle School","gender":"","division":"middle_school","results":[]`) {
+		t.Errorf("the middle school race is %s", w.Body)
+	}
+}
+
+// TestMeetResultsAcrossRaces checks that the results endpoint older
+// clients use still lists the meet's results from every race
+func TestMeetResultsAcrossRaces(t *testing.T) {
+	fake := useFakeDB(t)
+	multiRaceMeet(fake)
+	w := serve(newRouter(), "GET", "/api/meets/1/results", "")
+	if w.Code != 200 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	var results []ResultResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Errorf("got %d results, want all 4", len(results))
+	}
+}
+
+func TestDefaultRaceName(t *testing.T) {
+	tests := []struct {
+		division string
+		gender   sql.NullString
+		want     string
+	}{
+		{DivisionVarsity, sql.NullString{String: GenderBoys, Valid: true}, "Varsity Boys"},
+		{DivisionJV, sql.NullString{String: GenderGirls, Valid: true}, "JV Girls"},
+		{DivisionMiddleSchool, sql.NullString{}, "Middle School"},
+	}
+	for _, tt := range tests {
+		if got := defaultRaceName(tt.division, tt.gender); got != tt.want {
+			t.Errorf("%s %s: got %q, want %q", tt.division, tt.gender.String, got, tt.want)
+		}
+	}
+}
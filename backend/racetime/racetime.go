@@ -0,0 +1,116 @@
+// Package racetime parses and formats cross country race times.
+//
+// Times are stored as whole milliseconds so they sort numerically; the
+// accepted text forms are "mm:ss", "mm:ss.f" (up to three fractional
+// digits) and "h:mm:ss".
+package racetime
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Parse converts a race time such as "17:05", "17:05.3" or "1:02:10"
+// into milliseconds
+func Parse(s string) (int32, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.Split(s, ":")
+
+	var hours, minutes int
+	var err error
+	switch len(parts) {
+	case 2:
+		if minutes, err = parseDigits(parts[0], 1, 2); err != nil {
+			return 0, invalid(s)
+		}
+	case 3:
+		if hours, err = parseDigits(parts[0], 1, 2); err != nil {
+			return 0, invalid(s)
+		}
+		if minutes, err = parseDigits(parts[1], 2, 2); err != nil || minutes >= 60 {
+			return 0, invalid(s)
+		}
+	default:
+		return 0, invalid(s)
+	}
+
+	// Seconds are always two digits, optionally followed by a fraction
+	secPart, fracPart, hasFrac := strings.Cut(parts[len(parts)-1], ".")
+	seconds, err := parseDigits(secPart, 2, 2)
+	if err != nil || seconds >= 60 {
+		return 0, invalid(s)
+	}
+
+	var millis int
+	if hasFrac {
+		if millis, err = parseDigits(fracPart, 1, 3); err != nil {
+			return 0, invalid(s)
+		}
+		for i := len(fracPart); i < 3; i++ {
+			millis *= 10
+		}
+	}
+
+	total := ((hours*60+minutes)*60+seconds)*1000 + millis
+	if total == 0 {
+		return 0, invalid(s)
+	}
+	return int32(total), nil
+}
+
+// Format renders milliseconds as "m:ss" or "h:mm:ss", adding only as many
+// fractional digits as needed
+func Format(ms int32) string {
+	if ms < 0 {
+		return "-" + Format(-ms)
+	}
+
+	millis := ms % 1000
+	totalSeconds := ms / 1000
+	hours := totalSeconds / 3600
+	minutes := (totalSeconds / 60) % 60
+	seconds := totalSeconds % 60
+
+	var s string
+	if hours > 0 {
+		s = fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	} else {
+		s = fmt.Sprintf("%d:%02d", minutes, seconds)
+	}
+
+	if millis > 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%03d", millis), "0")
+	}
+	return s
+}
+
+// MetersPerMile is the length of a mile in meters
+const MetersPerMile = 1609.344
+
+// Pace converts a time over a distance into milliseconds per mile, rounded
+// to the nearest millisecond. It returns 0 when the distance is unknown.
+func Pace(ms, distanceMeters int32) int32 {
+	if distanceMeters <= 0 {
+		return 0
+	}
+	return int32(math.Round(float64(ms) * MetersPerMile / float64(distanceMeters)))
+}
+
+// parseDigits parses a string of between min and max ASCII digits
+func parseDigits(s string, min, max int) (int, error) {
+	if len(s) < min || len(s) > max {
+		return 0, fmt.Errorf("expected %d-%d digits", min, max)
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+func invalid(s string) error {
+	return fmt.Errorf("invalid time %q: use mm:ss, mm:ss.f or h:mm:ss", s)
+}
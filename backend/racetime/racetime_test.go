@@ -0,0 +1,116 @@
+package racetime
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int32
+	}{
+		{"17:05", 1025000},
+		{"9:59", 599000},
+		{" 17:05 ", 1025000},
+		{"17:05.3", 1025300},
+		{"17:05.32", 1025320},
+		{"17:05.321", 1025321},
+		{"17:05.03", 1025030},
+		{"1:02:10", 3730000},
+		{"01:02:10.5", 3730500},
+		{"0:00.001", 1},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"  ",
+		"17:5",
+		"17:60",
+		"17:99.5",
+		"1:60:00",
+		"1:2:10",
+		"-17:05",
+		"17:-05",
+		"17:05.",
+		"17:05.1234",
+		"123:05",
+		"17",
+		"1:02:10:00",
+		"0:00",
+		"00:00.000",
+		"DNF",
+		"17:05a",
+	} {
+		if got, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %d, want an error", in, got)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		ms   int32
+		want string
+	}{
+		{1025000, "17:05"},
+		{1025300, "17:05.3"},
+		{1025320, "17:05.32"},
+		{1025321, "17:05.321"},
+		{1025030, "17:05.03"},
+		{59000, "0:59"},
+		{3730000, "1:02:10"},
+		{3730500, "1:02:10.5"},
+		{-1025000, "-17:05"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.ms); got != tt.want {
+			t.Errorf("Format(%d) = %q, want %q", tt.ms, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, ms := range []int32{1, 999, 1000, 59999, 60000, 1025300, 1025321, 3599999, 3600000, 3730500} {
+		got, err := Parse(Format(ms))
+		if err != nil {
+			t.Errorf("Parse(Format(%d)) failed: %v", ms, err)
+			continue
+		}
+		if got != ms {
+			t.Errorf("Parse(Format(%d)) = %d", ms, got)
+		}
+	}
+}
+
+func TestPace(t *testing.T) {
+	tests := []struct {
+		ms, meters int32
+		want       int32
+	}{
+		// A 5K in 17:05 is 5:29.9 per mile
+		{1025000, 5000, 329916},
+		{1025000, 1609, 1025219},
+		// 1609 m is just short of a mile, so the pace is a little slower
+		{300000, 1609, 300064},
+		// Rounds to the nearest millisecond
+		{1, 5000, 0},
+		{2, 5000, 1},
+		{1025000, 0, 0},
+		{1025000, -5000, 0},
+	}
+	for _, tt := range tests {
+		if got := Pace(tt.ms, tt.meters); got != tt.want {
+			t.Errorf("Pace(%d, %d) = %d, want %d", tt.ms, tt.meters, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/racetime"
+)
+
+// Personal records are derived from results rather than entered by hand.
+// An athlete has one for each distance they have raced, taken from the
+// course of each meet; results at meets without a course share a record
+// of unknown distance. Of equal times, the earliest holds the record, as
+// in the PR progression of an athlete's history. They are rebuilt for an
+// athlete whenever one of their results is added or removed, and for
+// everyone when meets or courses change.
+
+// PersonalRecordResponse is an athlete's best time at one distance
+type PersonalRecordResponse struct {
+	DistanceMeters int32  `json:"distanceMeters,omitempty"`
+	ResultID       int32  `json:"resultId"`
+	Time           string `json:"time"`
+	TimeMs         int32  `json:"timeMs"`
+}
+
+func newPersonalRecordResponse(pr db.PersonalRecord) PersonalRecordResponse {
+	return PersonalRecordResponse{
+		DistanceMeters: pr.DistanceMeters.Int32,
+		ResultID:       pr.ResultID,
+		Time:           racetime.Format(pr.TimeMs),
+		TimeMs:         pr.TimeMs,
+	}
+}
+
+// headlinePersonalRecord picks the record quoted as an athlete's PR: the
+// 5K record, or failing that the record of unknown distance
+func headlinePersonalRecord(records []db.PersonalRecord) (db.PersonalRecord, bool) {
+	var fallback *db.PersonalRecord
+	for i, pr := range records {
+		if pr.DistanceMeters.Valid && pr.DistanceMeters.Int32 == standardDistanceMeters {
+			return pr, true
+		}
+		if !pr.DistanceMeters.Valid {
+			fallback = &records[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return db.PersonalRecord{}, false
+}
+
+// recomputePersonalRecords rebuilds an athlete's personal records from
+// their results using q, which may be bound to a transaction
+func recomputePersonalRecords(q *db.Queries, athleteID int32) error {
+	if err := q.DeletePersonalRecordsForAthlete(context.Background(), athleteID); err != nil {
+		return err
+	}
+	return q.CreatePersonalRecordsForAthlete(context.Background(), sql.NullInt32{Int32: athleteID, Valid: true})
+}
+
+// updatePersonalRecords recomputes an athlete's personal records in a transaction
+func updatePersonalRecords(athleteID int32) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recomputePersonalRecords(queries.WithTx(tx), athleteID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rebuildPersonalRecords recomputes every athlete's personal records in a
+// transaction, for changes such as a meet's course that affect many athletes
+func rebuildPersonalRecords() error {
+	tx, err := database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := queries.WithTx(tx)
+	if err := q.DeleteAllPersonalRecords(context.Background()); err != nil {
+		return err
+	}
+	if err := q.CreateAllPersonalRecords(context.Background()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isPersonalRecord reports whether a result currently holds one of the
+// athlete's personal records
+func isPersonalRecord(athleteID, resultID int32) (bool, error) {
+	records, err := queries.GetPersonalRecordsForAthlete(context.Background(), athleteID)
+	if err != nil {
+		return false, err
+	}
+	for _, pr := range records {
+		if pr.ResultID == resultID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// personalRecordsByAthlete groups personal records by athlete ID
+func personalRecordsByAthlete(records []db.PersonalRecord) map[int32][]db.PersonalRecord {
+	byAthlete := make(map[int32][]db.PersonalRecord)
+	for _, pr := range records {
+		byAthlete[pr.AthleteID] = append(byAthlete[pr.AthleteID], pr)
+	}
+	return byAthlete
+}
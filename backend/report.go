@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/pdf"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Meet report layout, in points
+const (
+	reportMargin     = 54
+	reportLineHeight = 12
+	reportTextSize   = 9
+	reportFooterY    = 30
+)
+
+// reportColumn is a column of a race's results table. Right-aligned
+// columns are drawn ending at x plus width.
+type reportColumn struct {
+	title string
+	x     float64
+	width float64
+	right bool
+}
+
+var reportColumns = []reportColumn{
+	{"Pl", reportMargin, 22, true},
+	{"Name", reportMargin + 34, 170, false},
+	{"Team", reportMargin + 212, 150, false},
+	{"Time", reportMargin + 362, 60, true},
+	{"Pace", reportMargin + 428, 50, true},
+	{"", reportMargin + 486, 18, false},
+}
+
+// meetReport lays out a meet's results as pages of a PDF: each race's
+// finishers with places, times, paces and PRs, then its team scores
+type meetReport struct {
+	doc   *pdf.Document
+	page  *pdf.Page
+	y     float64
+	title string
+}
+
+// newPage starts a page, heading it with the meet's name after the first
+func (r *meetReport) newPage() {
+	r.page = r.doc.AddPage()
+	r.y = pdf.PageHeight - reportMargin
+	if len(r.doc.Pages()) > 1 {
+		r.page.Text(reportMargin, r.y, pdf.HelveticaBold, 10, r.title)
+		r.y -= 8
+		r.page.Line(reportMargin, r.y, pdf.PageWidth-reportMargin, r.y, 0.5)
+		r.y -= 16
+	}
+}
+
+// need starts a new page unless height points are left on this one, and
+// reports whether it did
+func (r *meetReport) need(height float64) bool {
+	if r.y-height >= reportMargin {
+		return false
+	}
+	r.newPage()
+	return true
+}
+
+// heading draws the meet's name, date and location at the top of the report
+func (r *meetReport) heading(meet db.Meet) {
+	r.page.Text(reportMargin, r.y, pdf.HelveticaBold, 18, meet.Name)
+	r.y -= 18
+	r.page.Text(reportMargin, r.y, pdf.Helvetica, 11, meet.MeetDate.Format("Monday, January 2, 2006")+"  -  "+meet.Location)
+	r.y -= 10
+	r.page.Line(reportMargin, r.y, pdf.PageWidth-reportMargin, r.y, 1)
+	r.y -= 24
+}
+
+// raceTitle draws a race's name, marking it continued on later pages
+func (r *meetReport) raceTitle(race RaceResponse, continued bool) {
+	title := race.Name
+	if race.StartTime != "" && !continued {
+		title += "  (" + race.StartTime + ")"
+	}
+	if continued {
+		title += " (continued)"
+	}
+	r.page.Text(reportMargin, r.y, pdf.HelveticaBold, 13, title)
+	r.y -= 16
+	for _, col := range reportColumns {
+		r.cell(col, pdf.HelveticaBold, col.title)
+	}
+	r.y -= 4
+	r.page.Line(reportMargin, r.y, pdf.PageWidth-reportMargin, r.y, 0.5)
+	r.y -= reportLineHeight
+}
+
+// cell draws text in a column on the current line
+func (r *meetReport) cell(col reportColumn, font pdf.Font, text string) {
+	text = pdf.Truncate(font, reportTextSize, col.width, text)
+	if col.right {
+		r.page.TextRight(col.x+col.width, r.y, font, reportTextSize, text)
+	} else {
+		r.page.Text(col.x, r.y, font, reportTextSize, text)
+	}
+}
+
+// race draws a race's results followed by its team scores
+func (r *meetReport) race(race RaceResultsResponse, scores TeamScoresResponse) {
+	r.need(16 + 3*reportLineHeight)
+	r.raceTitle(race.RaceResponse, false)
+
+	if len(race.Results) == 0 {
+		r.page.Text(reportMargin, r.y, pdf.Helvetica, reportTextSize, "No results recorded")
+		r.y -= reportLineHeight
+	}
+	for _, result := range race.Results {
+		if r.need(reportLineHeight) {
+			r.raceTitle(race.RaceResponse, true)
+		}
+		place := ""
+		if result.Place > 0 {
+			place = strconv.Itoa(int(result.Place))
+		}
+		font := pdf.Helvetica
+		if result.AthleteID != 0 {
+			font = pdf.HelveticaBold // our runners stand out
+		}
+		pr := ""
+		if result.IsPersonalRecord {
+			pr = "PR"
+		}
+		for i, text := range []string{place, result.AthleteName, result.Team, result.Time, result.Pace, pr} {
+			r.cell(reportColumns[i], font, text)
+		}
+		r.y -= reportLineHeight
+	}
+
+	if len(scores.Teams) > 0 || len(scores.IncompleteTeams) > 0 {
+		r.teamScores(scores)
+	}
+	r.y -= 18
+}
+
+// teamScores draws each complete team's place, score and runners' points,
+// with displacers' points in parentheses
+func (r *meetReport) teamScores(scores TeamScoresResponse) {
+	r.need(10 + 2*reportLineHeight)
+	r.y -= 6
+	r.page.Text(reportMargin, r.y, pdf.HelveticaBold, 10, "Team Scores")
+	r.y -= reportLineHeight + 2
+
+	for _, team := range scores.Teams {
+		r.need(reportLineHeight)
+		points := make([]string, len(team.Runners))
+		for i, runner := range team.Runners {
+			points[i] = strconv.Itoa(runner.Points)
+			if !runner.Scoring {
+				points[i] = "(" + points[i] + ")"
+			}
+		}
+		r.page.TextRight(reportMargin+22, r.y, pdf.Helvetica, reportTextSize, strconv.Itoa(team.Place))
+		r.page.Text(reportMargin+34, r.y, pdf.Helvetica, reportTextSize, pdf.Truncate(pdf.Helvetica, reportTextSize, 170, team.Team))
+		r.page.TextRight(reportMargin+242, r.y, pdf.HelveticaBold, reportTextSize, strconv.Itoa(team.Score))
+		r.page.Text(reportMargin+262, r.y, pdf.Helvetica, reportTextSize, strings.Join(points, "  "))
+		r.y -= reportLineHeight
+	}
+	if len(scores.IncompleteTeams) > 0 {
+		r.need(reportLineHeight)
+		r.page.Text(reportMargin+34, r.y, pdf.Helvetica, reportTextSize,
+			"Incomplete teams: "+strings.Join(scores.IncompleteTeams, ", "))
+		r.y -= reportLineHeight
+	}
+}
+
+// footers numbers every page once the report is laid out
+func (r *meetReport) footers() {
+	pages := r.doc.Pages()
+	for i, p := range pages {
+		p.Text(reportMargin, reportFooterY, pdf.Helvetica, 8, r.title)
+		p.TextRight(pdf.PageWidth-reportMargin, reportFooterY, pdf.Helvetica, 8, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+}
+
+// buildMeetReport renders a meet's races, results and team scores as a PDF
+func buildMeetReport(meet db.Meet, races []db.Race, results []db.GetResultsForMeetRow) *pdf.Document {
+	title := meet.Name + " - " + meet.MeetDate.Format("January 2, 2006")
+	r := &meetReport{doc: pdf.New(title), title: title}
+	r.newPage()
+	r.heading(meet)
+
+	byRace := make(map[int32][]db.GetResultsForRaceRow)
+	for _, row := range results {
+		byRace[row.RaceID] = append(byRace[row.RaceID], db.GetResultsForRaceRow(row))
+	}
+	for _, race := range groupResultsByRace(races, results) {
+		r.race(race, scoreRace(byRace[race.ID]))
+	}
+	if len(races) == 0 {
+		r.page.Text(reportMargin, r.y, pdf.Helvetica, 11, "No races recorded")
+	}
+
+	r.footers()
+	return r.doc
+}
+
+// registerReportRoutes adds meet report routes to the public group
+func registerReportRoutes(api *gin.RouterGroup) {
+	// Get a printable PDF of a meet's results
+	api.GET("/meets/:id/report.pdf", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		races, err := queries.GetRacesForMeet(context.Background(), meet.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		results, err := queries.GetResultsForMeet(context.Background(), db.GetResultsForMeetParams{
+			MeetID: meet.ID,
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		var buf bytes.Buffer
+		if _, err := buildMeetReport(meet, races, results).WriteTo(&buf); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, meetFileName(meet, "results")))
+		c.Data(200, "application/pdf", buf.Bytes())
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/pdf/pdftest"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// checkGolden compares a PDF's structure with a golden file
+func checkGolden(t *testing.T, golden string, data []byte) {
+	t.Helper()
+	got, err := pdftest.Dump(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("structure differs from %s; run with -update to accept it\ngot:\n%s", golden, got)
+	}
+}
+
+// reportResult makes a result row as GetResultsForMeet returns it; an
+// empty school makes it one of our athletes'
+func reportResult(id, raceID int32, name, school, status string, timeMs, place int32) db.GetResultsForMeetRow {
+	r := db.GetResultsForMeetRow{
+		ID:             id,
+		RaceID:         raceID,
+		MeetID:         1,
+		Division:       DivisionVarsity,
+		Status:         status,
+		TimeMs:         sql.NullInt32{Int32: timeMs, Valid: timeMs > 0},
+		Place:          sql.NullInt32{Int32: place, Valid: place > 0},
+		Version:        1,
+		AthleteName:    name,
+		DistanceMeters: sql.NullInt32{Int32: 5000, Valid: true},
+	}
+	if school == "" {
+		r.AthleteID = sql.NullInt32{Int32: id, Valid: true}
+	} else {
+		r.CompetitorID = sql.NullInt32{Int32: id, Valid: true}
+		r.SchoolName = sql.NullString{String: school, Valid: true}
+	}
+	return r
+}
+
+func TestMeetReportStructure(t *testing.T) {
+	meet := db.Meet{ID: 1, Name: "Jones County Invitational", MeetDate: time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC), Location: "Gray, GA"}
+	races := []db.Race{
+		{ID: 1, MeetID: 1, Name: "Girls Varsity 5K", Gender: sql.NullString{String: "girls", Valid: true}, Division: DivisionVarsity,
+			StartTime: sql.NullTime{Time: time.Date(2024, 9, 14, 9, 0, 0, 0, time.UTC), Valid: true}},
+		{ID: 2, MeetID: 1, Name: "Boys Varsity 5K", Gender: sql.NullString{String: "boys", Valid: true}, Division: DivisionVarsity},
+		{ID: 3, MeetID: 1, Name: "Middle School 3K", Division: DivisionMiddleSchool},
+	}
+
+	// The girls' race has two complete teams, an incomplete one and
+	// runners without a place
+	results := []db.GetResultsForMeetRow{
+		reportResult(1, 1, "Jane Smith", "", StatusFinished, 1185200, 1),
+		reportResult(2, 1, "Mary Doe", "Mary Persons", StatusFinished, 1201300, 2),
+		reportResult(3, 1, "Sarah O'Neal", "", StatusFinished, 1215050, 3),
+		reportResult(4, 1, "Ana Lee", "Mary Persons", StatusFinished, 1240100, 4),
+		reportResult(5, 1, "Ruth Ng", "Northside", StatusFinished, 1244000, 5),
+		reportResult(6, 1, "Kate Brown", "", StatusFinished, 1262000, 6),
+		reportResult(7, 1, "Beth Cole", "Mary Persons", StatusFinished, 1270000, 7),
+		reportResult(8, 1, "Amy Diaz", "", StatusFinished, 1281000, 8),
+		reportResult(9, 1, "Cara Evans", "Mary Persons", StatusFinished, 1290000, 9),
+		reportResult(10, 1, "Dana Fox", "", StatusFinished, 1301000, 10),
+		reportResult(11, 1, "Erin Gray", "Mary Persons", StatusFinished, 1312000, 11),
+		reportResult(12, 1, "Fay Hill", "", StatusFinished, 1320000, 12),
+		reportResult(13, 1, "Gwendolyn Alexandra Montgomery-Whitfield", "Mary Persons", StatusFinished, 1333000, 13),
+		reportResult(14, 1, "Ellie Green", "Mary Persons", StatusDNF, 0, 0),
+		reportResult(15, 1, "Lily White", "", StatusDQ, 0, 0),
+	}
+	results[0].PrResultID = sql.NullInt32{Int32: 1, Valid: true}
+
+	// The boys' race is long enough to run onto another page
+	schools := []string{"", "Mary Persons", "Northside", "Howard"}
+	for i := int32(0); i < 60; i++ {
+		id := 100 + i
+		results = append(results, reportResult(id, 2, fmt.Sprintf("Runner %d", i+1), schools[i%4], StatusFinished, 1000000+i*3100, i+1))
+	}
+
+	doc := buildMeetReport(meet, races, results)
+	doc.Created = time.Date(2024, 9, 14, 15, 30, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "testdata/meet_report.golden", buf.Bytes())
+}
+
+func TestEmptyMeetReportStructure(t *testing.T) {
+	meet := db.Meet{ID: 2, Name: "Season Opener", MeetDate: time.Date(2024, 8, 24, 0, 0, 0, 0, time.UTC), Location: "Jones County High School"}
+	doc := buildMeetReport(meet, nil, nil)
+	doc.Created = time.Date(2024, 8, 24, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "testdata/empty_meet_report.golden", buf.Bytes())
+}
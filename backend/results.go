@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resultRequest is the body for creating a result for one of our athletes
+// or another school's competitor. Results belong to a race; older clients
+// may instead give a meet and division, and the result goes in the race
+// for that division and the runner's gender.
+type resultRequest struct {
+	AthleteID    int32  `json:"athleteId"`
+	CompetitorID int32  `json:"competitorId"`
+	RaceID       int32  `json:"raceId"`
+	MeetID       int32  `json:"meetId"`
+	Division     string `json:"division"`
+	Status       string `json:"status"`
+	Time         string `json:"time"`
+	Place        int32  `json:"place"`
+	// Splits are optional, such as the mile and two mile marks
+	Splits []splitRequest `json:"splits" binding:"dive"`
+	// gender, when set, picks the race for a meet and division in place of
+	// the runner's gender, such as the gender of an imported race
+	gender string
+}
+
+// newResult is a checked result ready to be added to its meet
+type newResult struct {
+	meetID int32
+	params db.CreateResultParams
+	splits []db.CreateSplitParams
+}
+
+// resultError is a problem with a result, as opposed to a failure to
+// check it
+type resultError string
+
+func (e resultError) Error() string { return string(e) }
+
+// isResultError reports whether err is a problem with a result
+func isResultError(err error) bool {
+	var e resultError
+	return errors.As(err, &e)
+}
+
+// validate checks a result request and resolves its race using q, which
+// may be bound to a transaction. A missing race for a meet and division is
+// created. Problems with the request are resultErrors.
+func (req resultRequest) validate(q *db.Queries) (newResult, error) {
+	status, timeMs, err := resultTime(req.Status, req.Time)
+	if err != nil {
+		return newResult{}, resultError(err.Error())
+	}
+
+	var gender sql.NullString
+	switch {
+	case req.AthleteID != 0 && req.CompetitorID != 0:
+		return newResult{}, resultError("A result is for an athlete or a competitor, not both")
+	case req.AthleteID != 0:
+		athlete, err := q.GetAthleteByID(context.Background(), req.AthleteID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return newResult{}, resultError("Athlete not found")
+		}
+		if err != nil {
+			return newResult{}, err
+		}
+		gender = athlete.Gender
+	case req.CompetitorID != 0:
+		competitor, err := q.GetCompetitorByID(context.Background(), req.CompetitorID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return newResult{}, resultError("Competitor not found")
+		}
+		if err != nil {
+			return newResult{}, err
+		}
+		gender = competitor.Gender
+	default:
+		return newResult{}, resultError("An athlete or competitor is required")
+	}
+
+	raceID, meetID := req.RaceID, req.MeetID
+	switch {
+	case raceID != 0:
+		race, err := q.GetRaceByID(context.Background(), raceID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return newResult{}, resultError("Race not found")
+		}
+		if err != nil {
+			return newResult{}, err
+		}
+		if req.MeetID != 0 && race.MeetID != req.MeetID {
+			return newResult{}, resultError("Race not found")
+		}
+		meetID = race.MeetID
+	case req.MeetID != 0:
+		if req.Division == "" {
+			req.Division = DivisionVarsity
+		}
+		if !validDivision(req.Division) {
+			return newResult{}, resultError("Division must be varsity, jv, middle_school or open")
+		}
+		raceGender := gender
+		if req.gender != "" {
+			raceGender = sql.NullString{String: req.gender, Valid: true}
+		}
+		raceID, err = resultRace(q, req.MeetID, req.Division, raceGender)
+		if err != nil {
+			return newResult{}, err
+		}
+	default:
+		return newResult{}, resultError("A race or meet is required")
+	}
+
+	distance, err := q.GetRaceDistance(context.Background(), raceID)
+	if err != nil {
+		return newResult{}, err
+	}
+
+	splits, err := splitParams(req.Splits, timeMs, distance)
+	if err != nil {
+		return newResult{}, resultError(err.Error())
+	}
+
+	return newResult{
+		meetID: meetID,
+		params: db.CreateResultParams{
+			AthleteID:    sql.NullInt32{Int32: req.AthleteID, Valid: req.AthleteID != 0},
+			CompetitorID: sql.NullInt32{Int32: req.CompetitorID, Valid: req.CompetitorID != 0},
+			RaceID:       raceID,
+			Status:       status,
+			TimeMs:       timeMs,
+			Place:        sql.NullInt32{Int32: req.Place, Valid: req.Place > 0 && isFinisher(status)},
+		},
+		splits: splits,
+	}, nil
+}
+
+// createResult adds a checked result and its splits using q, which may be
+// bound to a transaction, and returns its ID
+func createResult(q *db.Queries, r newResult) (int32, error) {
+	result, err := q.CreateResult(context.Background(), r.params)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int32(id), createSplits(q, int32(id), r.splits)
+}
+
+// finishResults rederives places in the given races and personal records
+// for the given athletes after results are added or removed, using q,
+// which may be bound to a transaction
+func finishResults(q *db.Queries, raceIDs, athleteIDs map[int32]bool) error {
+	for raceID := range raceIDs {
+		if err := updateRacePlaces(q, raceID); err != nil {
+			return err
+		}
+	}
+	for athleteID := range athleteIDs {
+		if err := recomputePersonalRecords(q, athleteID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RowErrorResponse is a problem with one row of a bulk request; Row
+// counts from 1
+type RowErrorResponse struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// raceEntries tracks who has a result and which places are taken in each
+// race, so a bulk request can be checked for duplicates before insert
+type raceEntries struct {
+	athletes    map[int32]bool
+	competitors map[int32]bool
+	places      map[int32]bool
+}
+
+// entriesForRace loads a race's existing results into entries, once per race
+func entriesForRace(q *db.Queries, entries map[int32]*raceEntries, raceID int32) (*raceEntries, error) {
+	if e, ok := entries[raceID]; ok {
+		return e, nil
+	}
+	existing, err := q.GetResultsForRace(context.Background(), raceID)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &raceEntries{
+		athletes:    make(map[int32]bool),
+		competitors: make(map[int32]bool),
+		places:      make(map[int32]bool),
+	}
+	for _, r := range existing {
+		e.add(r.AthleteID, r.CompetitorID, r.Place)
+	}
+	entries[raceID] = e
+	return e, nil
+}
+
+// check reports a result that duplicates a runner or place already in the race
+func (e *raceEntries) check(athleteID, competitorID, place sql.NullInt32) error {
+	switch {
+	case athleteID.Valid && e.athletes[athleteID.Int32]:
+		return resultError("Athlete already has a result in this race")
+	case competitorID.Valid && e.competitors[competitorID.Int32]:
+		return resultError("Competitor already has a result in this race")
+	case place.Valid && e.places[place.Int32]:
+		return resultError(fmt.Sprintf("Place %d is already taken in this race", place.Int32))
+	}
+	return nil
+}
+
+func (e *raceEntries) add(athleteID, competitorID, place sql.NullInt32) {
+	if athleteID.Valid {
+		e.athletes[athleteID.Int32] = true
+	}
+	if competitorID.Valid {
+		e.competitors[competitorID.Int32] = true
+	}
+	if place.Valid {
+		e.places[place.Int32] = true
+	}
+}
+
+// remove takes a result back out of the race, such as one being replaced
+func (e *raceEntries) remove(athleteID, competitorID, place sql.NullInt32) {
+	delete(e.athletes, athleteID.Int32)
+	delete(e.competitors, competitorID.Int32)
+	delete(e.places, place.Int32)
+}
+
+// addResults checks and adds a meet's results using q, which should be
+// bound to a transaction. Every row is checked before any is added; when
+// some are invalid, their problems are returned and nothing is added, but
+// races created for meet and division rows are left for the caller's
+// rollback to remove.
+func addResults(q *db.Queries, meetID int32, requests []resultRequest) ([]int32, []RowErrorResponse, error) {
+	rows := make([]newResult, len(requests))
+	rowErrors := []RowErrorResponse{}
+	entries := make(map[int32]*raceEntries)
+	for i, r := range requests {
+		r.MeetID = meetID
+		row, err := r.validate(q)
+		if err == nil {
+			var e *raceEntries
+			if e, err = entriesForRace(q, entries, row.params.RaceID); err == nil {
+				if err = e.check(row.params.AthleteID, row.params.CompetitorID, row.params.Place); err == nil {
+					e.add(row.params.AthleteID, row.params.CompetitorID, row.params.Place)
+				}
+			}
+		}
+		if isResultError(err) {
+			rowErrors = append(rowErrors, RowErrorResponse{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows[i] = row
+	}
+	if len(rowErrors) > 0 {
+		return nil, rowErrors, nil
+	}
+
+	ids := make([]int32, len(rows))
+	raceIDs := make(map[int32]bool)
+	athleteIDs := make(map[int32]bool)
+	for i, row := range rows {
+		var err error
+		if ids[i], err = createResult(q, row); err != nil {
+			return nil, nil, err
+		}
+		raceIDs[row.params.RaceID] = true
+		if row.params.AthleteID.Valid {
+			athleteIDs[row.params.AthleteID.Int32] = true
+		}
+	}
+	return ids, nil, finishResults(q, raceIDs, athleteIDs)
+}
+
+// registerResultRoutes adds bulk result routes to the statistician group
+func registerResultRoutes(statisticians *gin.RouterGroup) {
+	// Create a meet's results all at once. Every row is checked first and
+	// nothing is added unless all of them are valid.
+	statisticians.POST("/meets/:id/results/bulk", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid meet ID"})
+			return
+		}
+
+		meet, err := queries.GetMeetByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Meet not found"})
+			return
+		}
+
+		var req struct {
+			Results []resultRequest `json:"results" binding:"required,dive"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if len(req.Results) == 0 {
+			c.JSON(400, gin.H{"error": "At least one result is required"})
+			return
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+		changes := snapshotMeet(q, meet.ID)
+
+		ids, rowErrors, err := addResults(q, meet.ID, req.Results)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if len(rowErrors) > 0 {
+			c.JSON(400, gin.H{"error": "No results were added", "errors": rowErrors})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		changes.publish()
+
+		c.JSON(201, gin.H{"ids": ids, "message": fmt.Sprintf("%d results created", len(ids))})
+	})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestDeleteResultReranksRace(t *testing.T) {
+	fake := useFakeDB(t)
+	fake.rows["GetResultByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, int64(4), nil, int64(2), StatusFinished, int64(1185200), int64(1), nil, int64(1), time.Now()}}
+	}
+	fake.rows["GetRaceByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, int64(1), nil, "Varsity Girls", "girls", DivisionVarsity, nil, nil, time.Now()}}
+	}
+	// Another school's runners are in the race, so places are derived
+	fake.rows["CountCompetitorResultsForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(3)}}
+	}
+	r := newRouter()
+
+	w := serve(r, "DELETE", "/api/results/7", fake.login(RoleStatistician))
+	if w.Code != 200 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+
+	// The places left are re-ranked, bumping the versions of results whose
+	// places change, in the same transaction as the delete
+	want := []string{"DeleteResult", "BumpRerankedResultVersions", "UpdateRacePlaces", "DeletePersonalRecordsForAthlete", "CreatePersonalRecordsForAthlete", "COMMIT"}
+	if !reflect.DeepEqual(fake.execs, want) {
+		t.Errorf("ran %v, want %v", fake.execs, want)
+	}
+}
+
+// raceResults answers the queries for adding results to race 3 of meet 1,
+// which already has athlete 4's result in first place
+func raceResults(fake *fakeDB) {
+	fake.rows["GetAthleteByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, "Runner", int64(10), "girls", nil, time.Now()}}
+	}
+	fake.rows["GetRaceByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, int64(1), nil, "Varsity Girls", "girls", DivisionVarsity, nil, nil, time.Now()}}
+	}
+	fake.rows["GetMeetByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, nil, nil, "Invitational", time.Now(), "Gray", nil, time.Now()}}
+	}
+	fake.rows["GetRaceDistance"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(5000)}}
+	}
+	fake.rows["GetResultsForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(20), int64(4), nil, int64(3), int64(1), DivisionVarsity, StatusFinished, int64(1100000), int64(1), nil, int64(1), time.Now(),
+			"Runner", "girls", nil, int64(5000), nil}}
+	}
+}
+
+func TestCreateResultConflicts(t *testing.T) {
+	fake := useFakeDB(t)
+	raceResults(fake)
+	r := newRouter()
+	token := fake.login(RoleStatistician)
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"athleteId": 4, "raceId": 3, "time": "18:40"}`, "Athlete already has a result in this race"},
+		{`{"athleteId": 5, "raceId": 3, "time": "18:40", "place": 1}`, "Place 1 is already taken in this race"},
+	}
+	for _, tt := range tests {
+		w := serveJSON(r, "POST", "/api/results", token, tt.body)
+		if w.Code != 409 || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s got %d: %s", tt.body, w.Code, w.Body)
+		}
+	}
+
+	// A result added since the check still conflicts in the database
+	fake.execErrs["CreateResult"] = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+	if w := serveJSON(r, "POST", "/api/results", token, `{"athleteId": 5, "raceId": 3, "time": "18:40"}`); w.Code != 409 {
+		t.Errorf("a duplicate key got %d: %s", w.Code, w.Body)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+}
+
+func TestCreateResultErrors(t *testing.T) {
+	fake := useFakeDB(t)
+	raceResults(fake)
+	r := newRouter()
+	token := fake.login(RoleStatistician)
+
+	if w := serveJSON(r, "POST", "/api/results", token, `{"athleteId": 5, "raceId": 3, "time": "fast"}`); w.Code != 400 {
+		t.Errorf("an invalid time got %d: %s", w.Code, w.Body)
+	}
+
+	// Failing to add the race for a meet and division is not the request's
+	// fault
+	fake.execErrs["CreateRace"] = errors.New("connection reset")
+	w := serveJSON(r, "POST", "/api/results", token, `{"athleteId": 5, "meetId": 1, "division": "jv", "time": "18:40"}`)
+	if w.Code != 500 {
+		t.Errorf("a database error got %d: %s", w.Code, w.Body)
+	}
+}
+
+// TestBulkResultsAllOrNothing checks that one bad row keeps the whole
+// batch out, with each bad row's problem reported
+func TestBulkResultsAllOrNothing(t *testing.T) {
+	fake := useFakeDB(t)
+	raceResults(fake)
+
+	body := `{"results": [
+		{"athleteId": 5, "raceId": 3, "time": "18:40", "place": 2},
+		{"athleteId": 6, "raceId": 3, "time": "fast"},
+		{"athleteId": 5, "raceId": 3, "time": "19:05"},
+		{"athleteId": 7, "raceId": 3, "time": "19:30", "place": 1}
+	]}`
+	w := serveJSON(newRouter(), "POST", "/api/meets/1/results/bulk", fake.login(RoleStatistician), body)
+	if w.Code != 400 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+
+	var response struct {
+		Errors []RowErrorResponse `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	want := []RowErrorResponse{
+		{Row: 2, Error: `invalid time "fast": use mm:ss, mm:ss.f or h:mm:ss`},
+		{Row: 3, Error: "Athlete already has a result in this race"},
+		{Row: 4, Error: "Place 1 is already taken in this race"},
+	}
+	if !reflect.DeepEqual(response.Errors, want) {
+		t.Errorf("got errors %v, want %v", response.Errors, want)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Roles a user can hold. Each session carries its user's role, and
+// requireRole restricts route groups to the roles allowed to use them:
+//
+//	admin         everything, including managing users
+//	coach         athletes, meets and results
+//	statistician  results only
+//	read_only     no changes
+const (
+	RoleAdmin        = "admin"
+	RoleCoach        = "coach"
+	RoleStatistician = "statistician"
+	RoleReadOnly     = "read_only"
+)
+
+// validRole reports whether role is one of the known roles
+func validRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleCoach, RoleStatistician, RoleReadOnly:
+		return true
+	}
+	return false
+}
+
+// requireRole only allows sessions whose user has one of the given roles.
+// It must run after authMiddleware, which stores the session.
+func requireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := currentSession(c).Role
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(403, gin.H{"error": "You do not have permission to do that"})
+		c.Abort()
+	}
+}
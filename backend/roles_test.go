@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+)
+
+// Who may use each group of routes
+var (
+	adminOnly          = []string{RoleAdmin}
+	coachesAndAdmins   = []string{RoleAdmin, RoleCoach}
+	statisticiansAndUp = []string{RoleAdmin, RoleCoach, RoleStatistician}
+	anyRole            = []string{RoleAdmin, RoleCoach, RoleStatistician, RoleReadOnly}
+)
+
+// permissions is the permission matrix: the roles allowed to use each
+// write route
+var permissions = []struct {
+	method, path string
+	roles        []string
+}{
+	{"POST", "/api/auth/logout-all", anyRole},
+
+	{"POST", "/api/users", adminOnly},
+	{"PUT", "/api/users/:id/role", adminOnly},
+	{"POST", "/api/users/:id/disable", adminOnly},
+	{"POST", "/api/users/:id/enable", adminOnly},
+	{"POST", "/api/users/:id/reset-password", adminOnly},
+
+	{"POST", "/api/athletes", coachesAndAdmins},
+	{"PUT", "/api/athletes/:id", coachesAndAdmins},
+	{"DELETE", "/api/athletes/:id", coachesAndAdmins},
+	{"POST", "/api/meets", coachesAndAdmins},
+	{"PUT", "/api/meets/:id", coachesAndAdmins},
+	{"DELETE", "/api/meets/:id", coachesAndAdmins},
+	{"POST", "/api/meets/:id/races", coachesAndAdmins},
+	{"PUT", "/api/races/:id", coachesAndAdmins},
+	{"DELETE", "/api/races/:id", coachesAndAdmins},
+	{"POST", "/api/seasons", coachesAndAdmins},
+	{"PUT", "/api/seasons/:id", coachesAndAdmins},
+	{"POST", "/api/seasons/:id/activate", coachesAndAdmins},
+	{"DELETE", "/api/seasons/:id", coachesAndAdmins},
+	{"POST", "/api/courses", coachesAndAdmins},
+	{"PUT", "/api/courses/:id", coachesAndAdmins},
+	{"DELETE", "/api/courses/:id", coachesAndAdmins},
+	{"POST", "/api/schools", coachesAndAdmins},
+	{"PUT", "/api/schools/:id", coachesAndAdmins},
+	{"DELETE", "/api/schools/:id", coachesAndAdmins},
+	{"DELETE", "/api/competitors/:id", coachesAndAdmins},
+
+	{"POST", "/api/results", statisticiansAndUp},
+	{"DELETE", "/api/results/:id", statisticiansAndUp},
+	{"PUT", "/api/results/:id/splits", statisticiansAndUp},
+	{"POST", "/api/results/sync", statisticiansAndUp},
+	{"POST", "/api/meets/:id/results/bulk", statisticiansAndUp},
+	{"POST", "/api/meets/:id/results/import", statisticiansAndUp},
+	{"POST", "/api/results/import", statisticiansAndUp},
+	{"POST", "/api/meets/:id/results/bibs", statisticiansAndUp},
+	{"POST", "/api/competitors", statisticiansAndUp},
+	{"PUT", "/api/competitors/:id", statisticiansAndUp},
+	{"PUT", "/api/meets/:id/chips", statisticiansAndUp},
+	{"POST", "/api/races/:id/start", statisticiansAndUp},
+	{"POST", "/api/races/:id/timing/confirm", statisticiansAndUp},
+	{"PUT", "/api/timed-finishes/:id", statisticiansAndUp},
+	{"DELETE", "/api/timed-finishes/:id", statisticiansAndUp},
+	{"PUT", "/api/meets/:id/bibs", statisticiansAndUp},
+	{"PUT", "/api/seasons/:id/bibs", statisticiansAndUp},
+}
+
+func TestRolePermissions(t *testing.T) {
+	fake := useFakeDB(t)
+	r := newRouter()
+
+	for _, p := range permissions {
+		path := routeParam.ReplaceAllString(p.path, "1")
+		for _, role := range anyRole {
+			allowed := false
+			for _, allowedRole := range p.roles {
+				allowed = allowed || allowedRole == role
+			}
+
+			t.Run(p.method+" "+p.path+" as "+role, func(t *testing.T) {
+				w := serve(r, p.method, path, fake.login(role))
+				switch {
+				case allowed && (w.Code == 401 || w.Code == 403):
+					t.Errorf("got %d, want the route to be allowed: %s", w.Code, w.Body)
+				case !allowed && w.Code != 403:
+					t.Errorf("got %d, want 403", w.Code)
+				}
+			})
+		}
+	}
+}
+
+// TestPermissionsCoverRoutes keeps the permission matrix in step with the
+// router, so a new write route cannot go untested
+func TestPermissionsCoverRoutes(t *testing.T) {
+	r := newRouter()
+
+	inMatrix := make(map[string]bool)
+	for _, p := range permissions {
+		inMatrix[p.method+" "+p.path] = true
+	}
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		switch route.Method {
+		case "POST", "PUT", "PATCH", "DELETE":
+			if !inMatrix[key] && !publicWriteRoutes[key] {
+				t.Errorf("%s is not in the permission matrix", key)
+			}
+		}
+	}
+	for key := range inMatrix {
+		if !registered[key] {
+			t.Errorf("%s is in the permission matrix but not registered", key)
+		}
+	}
+}
@@ -1,38 +1,250 @@
 -- Jones County Cross Country Database Schema
 
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS sync_operations;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS personal_records;
+DROP TABLE IF EXISTS timed_finishes;
+DROP TABLE IF EXISTS bib_assignments;
+DROP TABLE IF EXISTS chip_assignments;
+DROP TABLE IF EXISTS result_splits;
 DROP TABLE IF EXISTS results;
+DROP TABLE IF EXISTS races;
+DROP TABLE IF EXISTS competitors;
+DROP TABLE IF EXISTS schools;
 DROP TABLE IF EXISTS meets;
+DROP TABLE IF EXISTS courses;
+DROP TABLE IF EXISTS seasons;
 DROP TABLE IF EXISTS athletes;
 
 -- Athletes table
 CREATE TABLE athletes (
     id INT AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
-    grade TINYINT NOT NULL CHECK (grade BETWEEN 9 AND 12),
-    personal_record VARCHAR(10),
+    grade TINYINT NOT NULL CHECK (grade BETWEEN 6 AND 12),
+    gender VARCHAR(5) CHECK (gender IN ('boys', 'girls')),
     events VARCHAR(100),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Seasons table (at most one season is active at a time)
+CREATE TABLE seasons (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    year INT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_year (year),
+    CHECK (end_date >= start_date)
+);
+
+-- Courses table (where a race is run and how far, so times are comparable)
+CREATE TABLE courses (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    venue VARCHAR(200) NOT NULL,
+    distance_meters INT NOT NULL CHECK (distance_meters > 0),
+    surface VARCHAR(20) CHECK (surface IN ('grass', 'trail', 'mixed', 'road', 'track')),
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Meets table
 CREATE TABLE meets (
     id INT AUTO_INCREMENT PRIMARY KEY,
+    season_id INT,
+    course_id INT,
     name VARCHAR(150) NOT NULL,
     meet_date DATE NOT NULL,
     location VARCHAR(200) NOT NULL,
     description TEXT,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (season_id) REFERENCES seasons(id) ON DELETE SET NULL,
+    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE SET NULL
 );
 
--- Results table (links athletes to meets)
-CREATE TABLE results (
+-- Races table (the separate races run at a meet; a race without a course
+-- is run on the meet's course, and a race without a gender is mixed).
+-- start_time is the scheduled start and gun_time the actual start, to the
+-- millisecond, recorded when the race is chip timed.
+CREATE TABLE races (
     id INT AUTO_INCREMENT PRIMARY KEY,
-    athlete_id INT NOT NULL,
     meet_id INT NOT NULL,
-    time VARCHAR(10) NOT NULL,
+    course_id INT,
+    name VARCHAR(100) NOT NULL,
+    gender VARCHAR(5) CHECK (gender IN ('boys', 'girls')),
+    division VARCHAR(20) NOT NULL DEFAULT 'varsity' CHECK (division IN ('varsity', 'jv', 'middle_school', 'open')),
+    start_time DATETIME,
+    gun_time DATETIME(3),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE SET NULL
+);
+
+-- Schools table (the other teams we race against, and our own, marked as
+-- home, which our athletes run for)
+CREATE TABLE schools (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    abbreviation VARCHAR(10),
+    is_home BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_school_name (name)
+);
+
+-- Competitors table (runners from other schools, or unattached runners,
+-- recorded so a race's full finish order can be stored)
+CREATE TABLE competitors (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    school_id INT,
+    gender VARCHAR(5) CHECK (gender IN ('boys', 'girls')),
+    grade TINYINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (school_id) REFERENCES schools(id) ON DELETE SET NULL
+);
+
+-- Results table (links athletes or competitors to races; exactly one of
+-- athlete_id and competitor_id is set). Finishers, including those running
+-- unattached, always have a time; runners who did not finish or start
+-- never do, and a disqualified runner may.
+-- version counts the changes made to a result, so offline clients can tell
+-- when it changed under them; re-ranking the race counts when it changes
+-- the result's place.
+-- client_id is the UUID of a result created offline.
+CREATE TABLE results (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    athlete_id INT,
+    competitor_id INT,
+    race_id INT NOT NULL,
+    status VARCHAR(12) NOT NULL DEFAULT 'finished' CHECK (status IN ('finished', 'unattached', 'dnf', 'dns', 'dq')),
+    time_ms INT,
     place INT,
+    client_id CHAR(36),
+    version INT NOT NULL DEFAULT 1,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE CASCADE,
+    FOREIGN KEY (race_id) REFERENCES races(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_race_result (athlete_id, race_id),
+    UNIQUE KEY unique_race_competitor (competitor_id, race_id),
+    UNIQUE KEY unique_client_id (client_id)
+);
+
+-- Result splits table (elapsed times at points along the course, such as
+-- the mile and two mile marks)
+CREATE TABLE result_splits (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    result_id INT NOT NULL,
+    distance_meters INT NOT NULL CHECK (distance_meters > 0),
+    elapsed_ms INT NOT NULL CHECK (elapsed_ms > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_result_split (result_id, distance_meters)
+);
+
+-- Chip assignments table (the timing chip each runner wears at a meet;
+-- exactly one of athlete_id and competitor_id is set)
+CREATE TABLE chip_assignments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT NOT NULL,
+    chip VARCHAR(32) NOT NULL,
+    athlete_id INT,
+    competitor_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
-    UNIQUE KEY unique_result (athlete_id, meet_id)
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_meet_chip (meet_id, chip)
+);
+
+-- Bib assignments table (the bib number each runner wears, for a season
+-- or for one meet; a runner's meet bib replaces their season bib at that
+-- meet). Exactly one of meet_id and season_id is set, and exactly one of
+-- athlete_id and competitor_id.
+CREATE TABLE bib_assignments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meet_id INT,
+    season_id INT,
+    bib INT NOT NULL CHECK (bib > 0),
+    athlete_id INT,
+    competitor_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE,
+    FOREIGN KEY (season_id) REFERENCES seasons(id) ON DELETE CASCADE,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_meet_bib (meet_id, bib),
+    UNIQUE KEY unique_season_bib (season_id, bib)
+);
+
+-- Timed finishes table (provisional results from chip reads at the finish
+-- line, one per chip per race, kept until an official confirms them as
+-- results). The runner is copied from the chip's assignment and is empty
+-- for an unassigned chip.
+CREATE TABLE timed_finishes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    race_id INT NOT NULL,
+    chip VARCHAR(32) NOT NULL,
+    athlete_id INT,
+    competitor_id INT,
+    elapsed_ms INT NOT NULL CHECK (elapsed_ms > 0),
+    read_count INT NOT NULL DEFAULT 1,
+    result_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (race_id) REFERENCES races(id) ON DELETE CASCADE,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE SET NULL,
+    FOREIGN KEY (competitor_id) REFERENCES competitors(id) ON DELETE SET NULL,
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE SET NULL,
+    UNIQUE KEY unique_race_chip (race_id, chip)
+);
+
+-- Personal records table (each athlete's fastest result at each distance,
+-- derived from results; distance is NULL for meets without a course)
+CREATE TABLE personal_records (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    athlete_id INT NOT NULL,
+    distance_meters INT,
+    result_id INT NOT NULL,
+    time_ms INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_athlete_distance (athlete_id, distance_meters)
+);
+
+-- Users table (coaches and volunteers who can log in)
+CREATE TABLE users (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'read_only' CHECK (role IN ('admin', 'coach', 'statistician', 'read_only')),
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_username (username)
+);
+
+-- Sync operations table (result changes pushed by offline clients, kept
+-- under the client's operation ID so a batch sent twice is applied once)
+CREATE TABLE sync_operations (
+    id CHAR(36) PRIMARY KEY,
+    user_id INT,
+    operation VARCHAR(6) NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    result_id INT NOT NULL,
+    version INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Sessions table (login tokens are stored as SHA-256 hashes, never in plain text)
+CREATE TABLE sessions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL,
+    user_id INT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_token_hash (token_hash),
+    INDEX idx_sessions_expires_at (expires_at)
 );
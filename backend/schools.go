@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"sync/atomic"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SchoolResponse struct {
+	ID           int32  `json:"id"`
+	Name         string `json:"name"`
+	Abbreviation string `json:"abbreviation"`
+	// IsHome is true for our own school
+	IsHome bool `json:"isHome,omitempty"`
+}
+
+func newSchoolResponse(s db.School) SchoolResponse {
+	return SchoolResponse{
+		ID:           s.ID,
+		Name:         s.Name,
+		Abbreviation: s.Abbreviation.String,
+		IsHome:       s.IsHome,
+	}
+}
+
+// homeSchool is our own school, the one marked as home, which our athletes
+// run and score for. It is loaded at startup and again when it changes.
+var homeSchool atomic.Pointer[db.School]
+
+// homeTeam is the name of our own school
+func homeTeam() string {
+	if s := homeSchool.Load(); s != nil {
+		return s.Name
+	}
+	return ""
+}
+
+// loadHomeSchool reads our own school into homeSchool
+func loadHomeSchool() error {
+	s, err := queries.GetHomeSchool(context.Background())
+	if err != nil {
+		return err
+	}
+	homeSchool.Store(&s)
+	return nil
+}
+
+// bootstrapHomeSchool marks the school with name as our own, adding it if
+// needed, when no school is marked yet, and loads our school
+func bootstrapHomeSchool(name string) error {
+	err := loadHomeSchool()
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err := queries.CreateHomeSchool(context.Background(), name); err != nil {
+		return err
+	}
+	log.Printf("Marked %q as our school", name)
+	return loadHomeSchool()
+}
+
+// CompetitorResponse is a runner from another school, or an unattached
+// runner, who appears in race results
+type CompetitorResponse struct {
+	ID       int32  `json:"id"`
+	Name     string `json:"name"`
+	SchoolID int32  `json:"schoolId,omitempty"`
+	Gender   string `json:"gender"`
+	Grade    int16  `json:"grade,omitempty"`
+}
+
+func newCompetitorResponse(c db.Competitor) CompetitorResponse {
+	return CompetitorResponse{
+		ID:       c.ID,
+		Name:     c.Name,
+		SchoolID: c.SchoolID.Int32,
+		Gender:   c.Gender.String,
+		Grade:    c.Grade.Int16,
+	}
+}
+
+// resultTeam is the team a result scores for: ours for our athletes, the
+// competitor's school otherwise, or none for an unattached competitor or a
+// runner racing unattached
+func resultTeam(athleteID sql.NullInt32, schoolName sql.NullString, status string) string {
+	if status == StatusUnattached {
+		return ""
+	}
+	if athleteID.Valid {
+		return homeTeam()
+	}
+	return schoolName.String
+}
+
+// competitorRequest is the body for creating or updating a competitor
+type competitorRequest struct {
+	Name     string `json:"name" binding:"required"`
+	SchoolID int32  `json:"schoolId"`
+	Gender   string `json:"gender"`
+	Grade    int16  `json:"grade"`
+}
+
+// params checks a competitor request and converts it for the database
+func (req competitorRequest) params() (db.CreateCompetitorParams, error) {
+	if req.Gender != "" && !validGender(req.Gender) {
+		return db.CreateCompetitorParams{}, errors.New("Gender must be boys or girls")
+	}
+	if req.SchoolID != 0 {
+		if _, err := queries.GetSchoolByID(context.Background(), req.SchoolID); err != nil {
+			return db.CreateCompetitorParams{}, errors.New("School not found")
+		}
+	}
+	return db.CreateCompetitorParams{
+		Name:     req.Name,
+		SchoolID: sql.NullInt32{Int32: req.SchoolID, Valid: req.SchoolID != 0},
+		Gender:   sql.NullString{String: req.Gender, Valid: req.Gender != ""},
+		Grade:    sql.NullInt16{Int16: req.Grade, Valid: req.Grade != 0},
+	}, nil
+}
+
+// registerSchoolRoutes adds school and competitor routes. Coaches manage
+// schools; statisticians may add competitors while entering results.
+func registerSchoolRoutes(api, coaches, statisticians *gin.RouterGroup) {
+	// Get all schools
+	api.GET("/schools", func(c *gin.Context) {
+		schools, err := queries.GetAllSchools(context.Background())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]SchoolResponse, len(schools))
+		for i, s := range schools {
+			response[i] = newSchoolResponse(s)
+		}
+		c.JSON(200, response)
+	})
+
+	// Create a new school
+	coaches.POST("/schools", func(c *gin.Context) {
+		var req struct {
+			Name         string `json:"name" binding:"required"`
+			Abbreviation string `json:"abbreviation"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := queries.CreateSchool(context.Background(), db.CreateSchoolParams{
+			Name:         req.Name,
+			Abbreviation: sql.NullString{String: req.Abbreviation, Valid: req.Abbreviation != ""},
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		id, _ := result.LastInsertId()
+		c.JSON(201, gin.H{"id": id, "message": "School created"})
+	})
+
+	// Update a school
+	coaches.PUT("/schools/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid school ID"})
+			return
+		}
+
+		var req struct {
+			Name         string `json:"name" binding:"required"`
+			Abbreviation string `json:"abbreviation"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = queries.UpdateSchool(context.Background(), db.UpdateSchoolParams{
+			Name:         req.Name,
+			Abbreviation: sql.NullString{String: req.Abbreviation, Valid: req.Abbreviation != ""},
+			ID:           int32(id),
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Our own school may have been renamed
+		if err := loadHomeSchool(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "School updated"})
+	})
+
+	// Delete a school (its competitors are kept as unattached runners).
+	// Our own school cannot be deleted.
+	coaches.DELETE("/schools/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid school ID"})
+			return
+		}
+
+		if home := homeSchool.Load(); home != nil && home.ID == int32(id) {
+			c.JSON(400, gin.H{"error": "Our own school cannot be deleted"})
+			return
+		}
+
+		err = queries.DeleteSchool(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "School deleted"})
+	})
+
+	// Get all competitors, optionally only those from one school
+	api.GET("/competitors", func(c *gin.Context) {
+		var schoolID sql.NullInt32
+		if value := c.Query("school"); value != "" {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "Invalid school ID"})
+				return
+			}
+			schoolID = sql.NullInt32{Int32: int32(id), Valid: true}
+		}
+
+		competitors, err := queries.GetAllCompetitors(context.Background(), schoolID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]CompetitorResponse, len(competitors))
+		for i, comp := range competitors {
+			response[i] = newCompetitorResponse(comp)
+		}
+		c.JSON(200, response)
+	})
+
+	// Create a new competitor
+	statisticians.POST("/competitors", func(c *gin.Context) {
+		var req competitorRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		params, err := req.params()
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := queries.CreateCompetitor(context.Background(), params)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		id, _ := result.LastInsertId()
+		c.JSON(201, gin.H{"id": id, "message": "Competitor created"})
+	})
+
+	// Update a competitor
+	statisticians.PUT("/competitors/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid competitor ID"})
+			return
+		}
+
+		var req competitorRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		params, err := req.params()
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = queries.UpdateCompetitor(context.Background(), db.UpdateCompetitorParams{
+			Name:     params.Name,
+			SchoolID: params.SchoolID,
+			Gender:   params.Gender,
+			Grade:    params.Grade,
+			ID:       int32(id),
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Competitor updated"})
+	})
+
+	// Delete a competitor and their results. The races they ran in are
+	// re-ranked with the delete, as for a deleted result.
+	coaches.DELETE("/competitors/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid competitor ID"})
+			return
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+
+		races, err := q.GetRacesForCompetitor(context.Background(), sql.NullInt32{Int32: int32(id), Valid: true})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		raceIDs := make(map[int32]bool)
+		changes := make(map[int32]*meetSnapshot)
+		for _, race := range races {
+			raceIDs[race.ID] = true
+			if _, ok := changes[race.MeetID]; !ok {
+				changes[race.MeetID] = snapshotMeet(q, race.MeetID)
+			}
+		}
+
+		if err := q.DeleteCompetitor(context.Background(), int32(id)); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if err := finishResults(q, raceIDs, nil); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		for _, meet := range changes {
+			meet.publish()
+		}
+
+		c.JSON(200, gin.H{"message": "Competitor deleted"})
+	})
+}
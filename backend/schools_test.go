@@ -0,0 +1,95 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDeleteCompetitorReranksRaces(t *testing.T) {
+	fake := useFakeDB(t)
+	// The competitor ran in two races of one meet
+	fake.rows["GetRacesForCompetitor"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{
+			{int64(2), int64(1), nil, "Varsity Girls", "girls", DivisionVarsity, nil, nil, time.Now()},
+			{int64(3), int64(1), nil, "Varsity Boys", "boys", DivisionVarsity, nil, nil, time.Now()},
+		}
+	}
+	// Other competitors are left in both races, so places are derived
+	fake.rows["CountCompetitorResultsForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(3)}}
+	}
+	r := newRouter()
+
+	w := serve(r, "DELETE", "/api/competitors/9", fake.login(RoleCoach))
+	if w.Code != 200 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+
+	// Both races are re-ranked in the same transaction as the delete
+	want := []string{"DeleteCompetitor", "BumpRerankedResultVersions", "UpdateRacePlaces", "BumpRerankedResultVersions", "UpdateRacePlaces", "COMMIT"}
+	if !reflect.DeepEqual(fake.execs, want) {
+		t.Errorf("ran %v, want %v", fake.execs, want)
+	}
+}
+
+func TestBootstrapHomeSchool(t *testing.T) {
+	fake := useFakeDB(t)
+	t.Cleanup(func() { homeSchool.Store(&testHomeSchool) })
+	// A school is marked once CreateHomeSchool has run
+	fake.rows["GetHomeSchool"] = func([]driver.NamedValue) [][]driver.Value {
+		args := fake.execArgs["CreateHomeSchool"]
+		if args == nil {
+			return nil
+		}
+		return [][]driver.Value{{int64(5), args[0].Value, nil, true, time.Now()}}
+	}
+
+	// With no school marked, the one named is marked or added
+	if err := bootstrapHomeSchool("Jones County"); err != nil {
+		t.Fatal(err)
+	}
+	if home := homeSchool.Load(); home.ID != 5 || homeTeam() != "Jones County" {
+		t.Errorf("loaded %+v", home)
+	}
+
+	// Once one is marked, it stays our school
+	fake.execs = nil
+	if err := bootstrapHomeSchool("Somewhere Else"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v with a school already marked", fake.execs)
+	}
+	if homeTeam() != "Jones County" {
+		t.Errorf("our school is %q", homeTeam())
+	}
+}
+
+func TestSchoolChangesKeepOurSchool(t *testing.T) {
+	fake := useFakeDB(t)
+	t.Cleanup(func() { homeSchool.Store(&testHomeSchool) })
+	fake.rows["GetHomeSchool"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(testHomeSchool.ID), "Jones County High School", nil, true, time.Now()}}
+	}
+	r := newRouter()
+	token := fake.login(RoleCoach)
+
+	if w := serve(r, "DELETE", "/api/schools/1", token); w.Code != 400 {
+		t.Errorf("deleting our school got %d: %s", w.Code, w.Body)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+
+	// Renaming our school renames the team our athletes score for
+	w := serveJSON(r, "PUT", "/api/schools/1", token, `{"name": "Jones County High School"}`)
+	if w.Code != 200 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	if team := resultTeam(sql.NullInt32{Int32: 4, Valid: true}, sql.NullString{}, StatusFinished); team != "Jones County High School" {
+		t.Errorf("our athletes score for %q", team)
+	}
+}
@@ -0,0 +1,135 @@
+// Package scoring computes cross country team scores for a race.
+//
+// Finishers are placed in finishing order. Only runners on complete teams,
+// those with at least five finishers, are placed for team scoring, and only
+// each team's first seven count: the first five score and the sixth and
+// seventh displace, pushing back runners from other teams without adding
+// to their own team's score. Unattached runners and runners on incomplete
+// teams are skipped, as are a team's runners past its seventh.
+//
+// The lowest score wins. Ties are broken by the teams' sixth runners, and
+// a team with a sixth runner beats one without; if neither team has one,
+// the team whose fifth runner finished first wins.
+package scoring
+
+import "sort"
+
+const (
+	// Scorers is how many runners' places add up to a team's score
+	Scorers = 5
+	// Displacers is how many runners after the scorers still take places
+	Displacers = 2
+)
+
+// Finisher is a runner who finished a race
+type Finisher struct {
+	// ID identifies the finisher to the caller, such as a result ID
+	ID int32
+	// Team is the runner's team, or empty for an unattached runner
+	Team string
+}
+
+// Placing is a finisher's place in the race and in the team scoring
+type Placing struct {
+	Finisher
+	// Place is the finisher's overall place, counting every runner
+	Place int
+	// Points is the place used for team scoring, or 0 when the runner does
+	// not count for a team
+	Points int
+	// Scoring is true for a team's first five runners
+	Scoring bool
+}
+
+// TeamScore is a complete team's result
+type TeamScore struct {
+	Team string
+	// Place is the team's finishing position, starting at 1
+	Place int
+	// Score is the sum of the scorers' points
+	Score int
+	// Runners are the team's first seven runners in finishing order
+	Runners []Placing
+}
+
+// Result is the outcome of scoring a race
+type Result struct {
+	// Placings holds every finisher in finishing order
+	Placings []Placing
+	// Teams holds the complete teams in team place order
+	Teams []TeamScore
+	// IncompleteTeams lists teams with fewer than five finishers, in the
+	// order their first runner finished
+	IncompleteTeams []string
+}
+
+// Score scores a race from its finishers, which must be in finishing order
+func Score(finishers []Finisher) Result {
+	counts := make(map[string]int)
+	var teamOrder []string
+	for _, f := range finishers {
+		if f.Team == "" {
+			continue
+		}
+		if counts[f.Team] == 0 {
+			teamOrder = append(teamOrder, f.Team)
+		}
+		counts[f.Team]++
+	}
+
+	var result Result
+	teams := make(map[string]*TeamScore)
+	for _, team := range teamOrder {
+		if counts[team] < Scorers {
+			result.IncompleteTeams = append(result.IncompleteTeams, team)
+		} else {
+			teams[team] = &TeamScore{Team: team}
+		}
+	}
+
+	result.Placings = make([]Placing, len(finishers))
+	points := 0
+	for i, f := range finishers {
+		placing := Placing{Finisher: f, Place: i + 1}
+		if team, ok := teams[f.Team]; ok && len(team.Runners) < Scorers+Displacers {
+			points++
+			placing.Points = points
+			placing.Scoring = len(team.Runners) < Scorers
+			if placing.Scoring {
+				team.Score += points
+			}
+			team.Runners = append(team.Runners, placing)
+		}
+		result.Placings[i] = placing
+	}
+
+	for _, team := range teamOrder {
+		if t, ok := teams[team]; ok {
+			result.Teams = append(result.Teams, *t)
+		}
+	}
+	sort.SliceStable(result.Teams, func(i, j int) bool {
+		return beats(result.Teams[i], result.Teams[j])
+	})
+	for i := range result.Teams {
+		result.Teams[i].Place = i + 1
+	}
+	return result
+}
+
+// beats reports whether team a places ahead of team b
+func beats(a, b TeamScore) bool {
+	if a.Score != b.Score {
+		return a.Score < b.Score
+	}
+
+	// Sixth runner tiebreaker
+	aSixth, bSixth := len(a.Runners) > Scorers, len(b.Runners) > Scorers
+	switch {
+	case aSixth && bSixth:
+		return a.Runners[Scorers].Points < b.Runners[Scorers].Points
+	case aSixth != bSixth:
+		return aSixth
+	}
+	return a.Runners[Scorers-1].Points < b.Runners[Scorers-1].Points
+}
@@ -0,0 +1,182 @@
+package scoring
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// race makes finishers in finishing order from their teams, written one
+// per finisher with "-" for an unattached runner; IDs count from 1
+func race(teams string) []Finisher {
+	var finishers []Finisher
+	for i, team := range strings.Fields(teams) {
+		if team == "-" {
+			team = ""
+		}
+		finishers = append(finishers, Finisher{ID: int32(i + 1), Team: team})
+	}
+	return finishers
+}
+
+// An invitational with two complete teams, Jones County (JC) and Macon
+// (MAC), each running eight; an incomplete Northside (NW) team of three;
+// and an unattached runner in third
+const invitational = `JC MAC - JC NW MAC MAC JC JC MAC
+	NW JC MAC JC MAC JC JC NW MAC MAC`
+
+func TestScoreInvitational(t *testing.T) {
+	result := Score(race(invitational))
+
+	// Unattached and Northside runners take no points, so everyone behind
+	// them moves up; the eighth JC and MAC runners take none either
+	wantPoints := []int{1, 2, 0, 3, 0, 4, 5, 6, 7, 8, 0, 9, 10, 11, 12, 13, 0, 0, 14, 0}
+	if len(result.Placings) != len(wantPoints) {
+		t.Fatalf("got %d placings, want %d", len(result.Placings), len(wantPoints))
+	}
+	for i, p := range result.Placings {
+		if p.Place != i+1 || p.ID != int32(i+1) {
+			t.Errorf("placing %d is finisher %d in place %d", i, p.ID, p.Place)
+		}
+		if p.Points != wantPoints[i] {
+			t.Errorf("place %d (%s) got %d points, want %d", p.Place, p.Team, p.Points, wantPoints[i])
+		}
+	}
+
+	if !reflect.DeepEqual(result.IncompleteTeams, []string{"NW"}) {
+		t.Errorf("incomplete teams are %v, want [NW]", result.IncompleteTeams)
+	}
+
+	want := []struct {
+		team         string
+		score        int
+		runnerPoints []int
+	}{
+		{"JC", 1 + 3 + 6 + 7 + 9, []int{1, 3, 6, 7, 9, 11, 13}},
+		{"MAC", 2 + 4 + 5 + 8 + 10, []int{2, 4, 5, 8, 10, 12, 14}},
+	}
+	if len(result.Teams) != len(want) {
+		t.Fatalf("got %d teams, want %d", len(result.Teams), len(want))
+	}
+	for i, w := range want {
+		team := result.Teams[i]
+		if team.Team != w.team || team.Place != i+1 || team.Score != w.score {
+			t.Errorf("team place %d is %s with %d, want %s with %d", team.Place, team.Team, team.Score, w.team, w.score)
+		}
+		var points []int
+		for j, r := range team.Runners {
+			points = append(points, r.Points)
+			if r.Scoring != (j < Scorers) {
+				t.Errorf("%s runner %d has Scoring %v", team.Team, j+1, r.Scoring)
+			}
+		}
+		if !reflect.DeepEqual(points, w.runnerPoints) {
+			t.Errorf("%s runners have points %v, want %v", team.Team, points, w.runnerPoints)
+		}
+	}
+}
+
+func TestScoreTiebreakers(t *testing.T) {
+	tests := []struct {
+		name  string
+		race  string
+		teams []string
+		score []int
+	}{
+		{
+			// Jones County and Northside tie on 36; Northside's fifth runner
+			// is ahead, but Jones County's sixth is
+			name:  "sixth runners",
+			race:  "JC HOU NW JC NW JC NW NW HOU JC HOU HOU NW HOU JC JC NW",
+			teams: []string{"JC", "NW", "HOU"},
+			score: []int{36, 36, 48},
+		},
+		{
+			// Only Jones County has a sixth runner, which beats Northside's
+			// earlier fifth
+			name:  "one sixth runner",
+			race:  "JC NW HOU JC HOU JC NW NW NW NW JC HOU HOU JC JC HOU",
+			teams: []string{"JC", "NW", "HOU"},
+			score: []int{36, 36, 49},
+		},
+		{
+			// Neither team has a sixth runner, so Northside's earlier fifth
+			// runner wins
+			name:  "fifth runners",
+			race:  "NW NW JC JC JC JC NW HOU NW HOU HOU HOU NW JC HOU",
+			teams: []string{"NW", "JC", "HOU"},
+			score: []int{32, 32, 56},
+		},
+		{
+			// Scores decide before any tiebreaker, and a team of exactly
+			// five is complete
+			name:  "lowest score",
+			race:  "HOU HOU HOU HOU HOU JC JC JC JC JC JC",
+			teams: []string{"HOU", "JC"},
+			score: []int{15, 40},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Score(race(tt.race))
+			var teams []string
+			var score []int
+			for i, team := range result.Teams {
+				if team.Place != i+1 {
+					t.Errorf("%s is in place %d at index %d", team.Team, team.Place, i)
+				}
+				teams = append(teams, team.Team)
+				score = append(score, team.Score)
+			}
+			if !reflect.DeepEqual(teams, tt.teams) || !reflect.DeepEqual(score, tt.score) {
+				t.Errorf("got %v scoring %v, want %v scoring %v", teams, score, tt.teams, tt.score)
+			}
+		})
+	}
+}
+
+func TestScoreIncompleteTeams(t *testing.T) {
+	tests := []struct {
+		name       string
+		race       string
+		points     []int
+		incomplete []string
+	}{
+		{
+			// Four runners are not a team, however well they run
+			name:       "four finishers",
+			race:       "NW NW NW NW JC JC JC JC JC",
+			points:     []int{0, 0, 0, 0, 1, 2, 3, 4, 5},
+			incomplete: []string{"NW"},
+		},
+		{
+			name:       "only unattached runners",
+			race:       "- - -",
+			points:     []int{0, 0, 0},
+			incomplete: nil,
+		},
+		{
+			// Incomplete teams are listed in the order their first runner
+			// finished
+			name:       "no complete team",
+			race:       "MAC NW - NW MAC MAC",
+			points:     []int{0, 0, 0, 0, 0, 0},
+			incomplete: []string{"MAC", "NW"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Score(race(tt.race))
+			var points []int
+			for _, p := range result.Placings {
+				points = append(points, p.Points)
+			}
+			if !reflect.DeepEqual(points, tt.points) {
+				t.Errorf("got points %v, want %v", points, tt.points)
+			}
+			if !reflect.DeepEqual(result.IncompleteTeams, tt.incomplete) {
+				t.Errorf("got incomplete teams %v, want %v", result.IncompleteTeams, tt.incomplete)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeasonResponse struct {
+	ID        int32  `json:"id"`
+	Year      int32  `json:"year"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	IsActive  bool   `json:"isActive"`
+}
+
+func newSeasonResponse(s db.Season) SeasonResponse {
+	return SeasonResponse{
+		ID:        s.ID,
+		Year:      s.Year,
+		StartDate: s.StartDate.Format("2006-01-02"),
+		EndDate:   s.EndDate.Format("2006-01-02"),
+		IsActive:  s.IsActive,
+	}
+}
+
+// seasonFilter resolves the "season" query parameter to a season ID.
+// A year selects that season and "all" matches every season. Without the
+// parameter the active season is used, or every season if none is active.
+func seasonFilter(c *gin.Context) (sql.NullInt32, error) {
+	value := c.Query("season")
+	if value == "all" {
+		return sql.NullInt32{}, nil
+	}
+
+	var season db.Season
+	var err error
+	if value == "" {
+		season, err = queries.GetActiveSeason(context.Background())
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.NullInt32{}, nil
+		}
+	} else {
+		year, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return sql.NullInt32{}, fmt.Errorf("Invalid season %q", value)
+		}
+		season, err = queries.GetSeasonByYear(context.Background(), int32(year))
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.NullInt32{}, fmt.Errorf("Season %d not found", year)
+		}
+	}
+	if err != nil {
+		return sql.NullInt32{}, err
+	}
+	return sql.NullInt32{Int32: season.ID, Valid: true}, nil
+}
+
+// meetSeason picks the season for a meet: the one requested, or otherwise
+// the season whose dates contain the meet date
+func meetSeason(seasonID int32, meetDate time.Time) (sql.NullInt32, error) {
+	if seasonID != 0 {
+		if _, err := queries.GetSeasonByID(context.Background(), seasonID); err != nil {
+			return sql.NullInt32{}, errors.New("Season not found")
+		}
+		return sql.NullInt32{Int32: seasonID, Valid: true}, nil
+	}
+
+	season, err := queries.GetSeasonForDate(context.Background(), meetDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sql.NullInt32{}, nil
+	}
+	if err != nil {
+		return sql.NullInt32{}, err
+	}
+	return sql.NullInt32{Int32: season.ID, Valid: true}, nil
+}
+
+// registerSeasonRoutes adds season routes to the public and coach groups
+func registerSeasonRoutes(api, coaches *gin.RouterGroup) {
+	// Get all seasons, newest first
+	api.GET("/seasons", func(c *gin.Context) {
+		seasons, err := queries.GetAllSeasons(context.Background())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]SeasonResponse, len(seasons))
+		for i, s := range seasons {
+			response[i] = newSeasonResponse(s)
+		}
+		c.JSON(200, response)
+	})
+
+	// Create a new season
+	coaches.POST("/seasons", func(c *gin.Context) {
+		var req struct {
+			Year      int32  `json:"year" binding:"required"`
+			StartDate string `json:"startDate" binding:"required"`
+			EndDate   string `json:"endDate" binding:"required"`
+			Active    bool   `json:"active"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		startDate, endDate, err := parseSeasonDates(req.StartDate, req.EndDate)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if _, err := queries.GetSeasonByYear(context.Background(), req.Year); err == nil {
+			c.JSON(409, gin.H{"error": "A season already exists for that year"})
+			return
+		}
+
+		// An active season is created and made active together, so a failure
+		// leaves neither it nor the season that was active changed
+		tx, err := database.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer tx.Rollback()
+		q := queries.WithTx(tx)
+
+		result, err := q.CreateSeason(context.Background(), db.CreateSeasonParams{
+			Year:      req.Year,
+			StartDate: startDate,
+			EndDate:   endDate,
+		})
+		if isDuplicateKey(err) {
+			c.JSON(409, gin.H{"error": "A season already exists for that year"})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		id, _ := result.LastInsertId()
+		if req.Active {
+			if err := q.SetActiveSeason(context.Background(), int32(id)); err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(201, gin.H{"id": id, "message": "Season created"})
+	})
+
+	// Update a season
+	coaches.PUT("/seasons/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid season ID"})
+			return
+		}
+
+		var req struct {
+			Year      int32  `json:"year" binding:"required"`
+			StartDate string `json:"startDate" binding:"required"`
+			EndDate   string `json:"endDate" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		startDate, endDate, err := parseSeasonDates(req.StartDate, req.EndDate)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if other, err := queries.GetSeasonByYear(context.Background(), req.Year); err == nil && other.ID != int32(id) {
+			c.JSON(409, gin.H{"error": "A season already exists for that year"})
+			return
+		}
+
+		err = queries.UpdateSeason(context.Background(), db.UpdateSeasonParams{
+			Year:      req.Year,
+			StartDate: startDate,
+			EndDate:   endDate,
+			ID:        int32(id),
+		})
+		if isDuplicateKey(err) {
+			c.JSON(409, gin.H{"error": "A season already exists for that year"})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Season updated"})
+	})
+
+	// Make a season the active one, deactivating all others
+	coaches.POST("/seasons/:id/activate", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid season ID"})
+			return
+		}
+
+		if _, err := queries.GetSeasonByID(context.Background(), int32(id)); err != nil {
+			c.JSON(404, gin.H{"error": "Season not found"})
+			return
+		}
+
+		if err := queries.SetActiveSeason(context.Background(), int32(id)); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Season activated"})
+	})
+
+	// Delete a season (its meets are kept but no longer belong to a season)
+	coaches.DELETE("/seasons/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid season ID"})
+			return
+		}
+
+		err = queries.DeleteSeason(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Season deleted"})
+	})
+}
+
+// parseSeasonDates parses and checks a season's start and end dates
+func parseSeasonDates(start, end string) (time.Time, time.Time, error) {
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid start date format. Use YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid end date format. Use YYYY-MM-DD")
+	}
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, errors.New("End date must not be before start date")
+	}
+	return startDate, endDate, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestUpdateSeasonDuplicateYear(t *testing.T) {
+	fake := useFakeDB(t)
+	r := newRouter()
+	token := fake.login(RoleCoach)
+	body := `{"year":2025,"startDate":"2025-08-01","endDate":"2025-11-15"}`
+
+	// season 2 already has the year
+	fake.rows["GetSeasonByYear"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(2), int64(2025), time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 15, 0, 0, 0, 0, time.UTC), false, time.Now()}}
+	}
+	if w := serveJSON(r, "PUT", "/api/seasons/1", token, body); w.Code != 409 {
+		t.Errorf("with the year taken got %d, want 409: %s", w.Code, w.Body)
+	}
+	if w := serveJSON(r, "PUT", "/api/seasons/2", token, body); w.Code != 200 {
+		t.Errorf("keeping its own year got %d, want 200: %s", w.Code, w.Body)
+	}
+
+	// another request took the year between the check and the update
+	delete(fake.rows, "GetSeasonByYear")
+	fake.execErrs["UpdateSeason"] = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '2025' for key 'unique_year'"}
+	if w := serveJSON(r, "PUT", "/api/seasons/1", token, body); w.Code != 409 {
+		t.Errorf("with a duplicate key got %d, want 409: %s", w.Code, w.Body)
+	}
+}
+
+func TestCreateActiveSeasonTogether(t *testing.T) {
+	fake := useFakeDB(t)
+	r := newRouter()
+	token := fake.login(RoleCoach)
+	body := `{"year":2025,"startDate":"2025-08-01","endDate":"2025-11-15","active":true}`
+
+	if w := serveJSON(r, "POST", "/api/seasons", token, body); w.Code != 201 {
+		t.Fatalf("got %d, want 201: %s", w.Code, w.Body)
+	}
+	if want := []string{"CreateSeason", "SetActiveSeason", "COMMIT"}; !slices.Equal(fake.execs, want) {
+		t.Errorf("ran %v, want %v", fake.execs, want)
+	}
+
+	// A season that cannot be made active is not created either
+	fake.execs = nil
+	fake.execErrs["SetActiveSeason"] = errors.New("connection lost")
+	if w := serveJSON(r, "POST", "/api/seasons", token, body); w.Code != 500 {
+		t.Errorf("got %d, want 500: %s", w.Code, w.Body)
+	}
+	if slices.Contains(fake.execs, "COMMIT") {
+		t.Errorf("committed %v", fake.execs)
+	}
+}
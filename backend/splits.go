@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/racetime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Split types, comparing the pace of a race's last segment with its first
+const (
+	SplitNegative = "negative"
+	SplitPositive = "positive"
+	SplitEven     = "even"
+)
+
+// evenSplitToleranceMs is how far apart, per mile, the first and last
+// segment paces can be for a race still to count as evenly split
+const evenSplitToleranceMs = 1000
+
+// SplitResponse is the elapsed time at a point in a race, with the time
+// and per-mile pace of the segment since the previous split
+type SplitResponse struct {
+	DistanceMeters int32  `json:"distanceMeters"`
+	Time           string `json:"time"`
+	ElapsedMs      int32  `json:"elapsedMs"`
+	Segment        string `json:"segment"`
+	SegmentMs      int32  `json:"segmentMs"`
+	SegmentPace    string `json:"segmentPace"`
+	SegmentPaceMs  int32  `json:"segmentPaceMs"`
+	// Finish is true for the segment from the last split to the finish,
+	// which is included when the course distance and final time are known
+	Finish bool `json:"finish,omitempty"`
+}
+
+// SplitsResponse is a result's splits and how evenly the race was run
+type SplitsResponse struct {
+	ResultID int32           `json:"resultId"`
+	Splits   []SplitResponse `json:"splits"`
+	// SplitType is negative when the last segment was run faster than the
+	// first, positive when slower and even when within a second per mile;
+	// it is omitted with fewer than two segments
+	SplitType string `json:"splitType,omitempty"`
+	// SplitDifferenceMs is the last segment's pace less the first's, per mile
+	SplitDifferenceMs int32 `json:"splitDifferenceMs"`
+}
+
+// splitRequest is one split when recording a result's splits
+type splitRequest struct {
+	DistanceMeters int32  `json:"distanceMeters" binding:"required"`
+	Time           string `json:"time" binding:"required"`
+}
+
+// analyzeSplits derives each segment's time and pace from a result's
+// splits, adding the finish as a last segment when the race distance and
+// final time are known and past the last split. Splits are only checked
+// when they are recorded, so a later change to the race's course or the
+// course's distance can leave them at or past the finish; the finish
+// segment is then left out rather than given a negative time or pace.
+func analyzeSplits(resultID int32, splits []db.ResultSplit, timeMs, distanceMeters sql.NullInt32) SplitsResponse {
+	response := SplitsResponse{ResultID: resultID, Splits: []SplitResponse{}}
+	if len(splits) == 0 {
+		return response
+	}
+
+	var previousDistance, previousElapsed int32
+	add := func(distance, elapsed int32, finish bool) {
+		segment := elapsed - previousElapsed
+		pace := racetime.Pace(segment, distance-previousDistance)
+		response.Splits = append(response.Splits, SplitResponse{
+			DistanceMeters: distance,
+			Time:           racetime.Format(elapsed),
+			ElapsedMs:      elapsed,
+			Segment:        racetime.Format(segment),
+			SegmentMs:      segment,
+			SegmentPace:    racetime.Format(pace),
+			SegmentPaceMs:  pace,
+			Finish:         finish,
+		})
+		previousDistance, previousElapsed = distance, elapsed
+	}
+	for _, s := range splits {
+		add(s.DistanceMeters, s.ElapsedMs, false)
+	}
+	if timeMs.Valid && distanceMeters.Valid && distanceMeters.Int32 > previousDistance && timeMs.Int32 > previousElapsed {
+		add(distanceMeters.Int32, timeMs.Int32, true)
+	}
+
+	if len(response.Splits) < 2 {
+		return response
+	}
+	first, last := response.Splits[0], response.Splits[len(response.Splits)-1]
+	response.SplitDifferenceMs = last.SegmentPaceMs - first.SegmentPaceMs
+	switch {
+	case response.SplitDifferenceMs <= -evenSplitToleranceMs:
+		response.SplitType = SplitNegative
+	case response.SplitDifferenceMs >= evenSplitToleranceMs:
+		response.SplitType = SplitPositive
+	default:
+		response.SplitType = SplitEven
+	}
+	return response
+}
+
+// splitParams checks a result's splits and converts them for the database,
+// leaving the result ID for createSplits to fill in.
+// Splits must be in order of distance, short of the race distance when it
+// is known, and faster than the final time when there is one. They are
+// checked again whenever the result is replaced, but not when its race's
+// distance changes; see analyzeSplits.
+func splitParams(splits []splitRequest, timeMs, distanceMeters sql.NullInt32) ([]db.CreateSplitParams, error) {
+	params := make([]db.CreateSplitParams, len(splits))
+	var previousDistance, previousElapsed int32
+	for i, s := range splits {
+		if s.DistanceMeters <= previousDistance {
+			return nil, errors.New("Splits must be in order of increasing distance")
+		}
+		if distanceMeters.Valid && s.DistanceMeters >= distanceMeters.Int32 {
+			return nil, errors.New("Splits must be short of the race distance")
+		}
+		elapsed, err := racetime.Parse(s.Time)
+		if err != nil {
+			return nil, err
+		}
+		if elapsed <= previousElapsed || (timeMs.Valid && elapsed >= timeMs.Int32) {
+			return nil, errors.New("Split times must increase and be faster than the final time")
+		}
+		params[i] = db.CreateSplitParams{
+			DistanceMeters: s.DistanceMeters,
+			ElapsedMs:      elapsed,
+		}
+		previousDistance, previousElapsed = s.DistanceMeters, elapsed
+	}
+	return params, nil
+}
+
+// createSplits adds splits to a result using q, which may be bound to a
+// transaction
+func createSplits(q *db.Queries, resultID int32, splits []db.CreateSplitParams) error {
+	for _, s := range splits {
+		s.ResultID = resultID
+		if err := q.CreateSplit(context.Background(), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceSplits replaces a result's splits in a transaction, which counts
+// as an edit to the result
+func replaceSplits(resultID int32, splits []db.CreateSplitParams) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := queries.WithTx(tx)
+	if err := q.DeleteSplitsForResult(context.Background(), resultID); err != nil {
+		return err
+	}
+	if err := createSplits(q, resultID, splits); err != nil {
+		return err
+	}
+	if err := q.IncrementResultVersion(context.Background(), resultID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// splitsByResult groups splits by result ID
+func splitsByResult(splits []db.ResultSplit) map[int32][]db.ResultSplit {
+	byResult := make(map[int32][]db.ResultSplit)
+	for _, s := range splits {
+		byResult[s.ResultID] = append(byResult[s.ResultID], s)
+	}
+	return byResult
+}
+
+// registerSplitRoutes adds result split routes to the public and
+// statistician groups
+func registerSplitRoutes(api, statisticians *gin.RouterGroup) {
+	// Get a result's splits with segment paces
+	api.GET("/results/:id/splits", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid result ID"})
+			return
+		}
+
+		result, err := queries.GetResultByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Result not found"})
+			return
+		}
+
+		distance, err := queries.GetRaceDistance(context.Background(), result.RaceID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		splits, err := queries.GetSplitsForResult(context.Background(), result.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, analyzeSplits(result.ID, splits, result.TimeMs, distance))
+	})
+
+	// Replace a result's splits; an empty list removes them
+	statisticians.PUT("/results/:id/splits", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid result ID"})
+			return
+		}
+
+		result, err := queries.GetResultByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Result not found"})
+			return
+		}
+
+		var req struct {
+			Splits []splitRequest `json:"splits" binding:"dive"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		distance, err := queries.GetRaceDistance(context.Background(), result.RaceID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		params, err := splitParams(req.Splits, result.TimeMs, distance)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		race, err := queries.GetRaceByID(context.Background(), result.RaceID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		changes := snapshotMeet(queries, race.MeetID)
+
+		if err := replaceSplits(result.ID, params); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		changes.publish()
+
+		c.JSON(200, gin.H{"message": "Splits updated"})
+	})
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"jones-county-xc/backend/db"
+)
+
+func validMs(ms int32) sql.NullInt32 { return sql.NullInt32{Int32: ms, Valid: true} }
+
+func TestSplitParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		splits   []splitRequest
+		timeMs   sql.NullInt32
+		distance sql.NullInt32
+		want     []int32 // elapsed times, or nil for an error
+		err      string
+	}{
+		{"in order", []splitRequest{{1609, "5:40"}, {3218, "11:35.5"}}, validMs(1100000), validMs(5000), []int32{340000, 695500}, ""},
+		{"none", nil, validMs(1100000), validMs(5000), []int32{}, ""},
+		{"out of order", []splitRequest{{3218, "11:35"}, {1609, "5:40"}}, validMs(1100000), validMs(5000), nil, "Splits must be in order of increasing distance"},
+		{"same distance twice", []splitRequest{{1609, "5:40"}, {1609, "5:41"}}, validMs(1100000), validMs(5000), nil, "Splits must be in order of increasing distance"},
+		{"at the finish", []splitRequest{{5000, "18:00"}}, validMs(1100000), validMs(5000), nil, "Splits must be short of the race distance"},
+		{"past the finish", []splitRequest{{5100, "18:00"}}, validMs(1100000), validMs(5000), nil, "Splits must be short of the race distance"},
+		// Without a course the distance cannot be checked
+		{"unknown distance", []splitRequest{{5100, "18:00"}}, validMs(1100000), sql.NullInt32{}, []int32{1080000}, ""},
+		{"slower than before", []splitRequest{{1609, "5:40"}, {3218, "5:30"}}, validMs(1100000), validMs(5000), nil, "Split times must increase and be faster than the final time"},
+		{"as slow as the final time", []splitRequest{{3218, "18:20"}}, validMs(1100000), validMs(5000), nil, "Split times must increase and be faster than the final time"},
+		{"slower than the final time", []splitRequest{{3218, "18:30"}}, validMs(1100000), validMs(5000), nil, "Split times must increase and be faster than the final time"},
+		// A runner who did not finish has no final time to be faster than
+		{"no final time", []splitRequest{{3218, "25:00"}}, sql.NullInt32{}, validMs(5000), []int32{1500000}, ""},
+		{"bad time", []splitRequest{{1609, "fast"}}, validMs(1100000), validMs(5000), nil, `invalid time "fast": use mm:ss, mm:ss.f or h:mm:ss`},
+	}
+	for _, tt := range tests {
+		params, err := splitParams(tt.splits, tt.timeMs, tt.distance)
+		if tt.want == nil {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: got %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := []int32{}
+		for i, p := range params {
+			if p.DistanceMeters != tt.splits[i].DistanceMeters {
+				t.Errorf("%s: split %d is at %dm, want %dm", tt.name, i, p.DistanceMeters, tt.splits[i].DistanceMeters)
+			}
+			got = append(got, p.ElapsedMs)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got elapsed %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// kilometerSplits are splits at each kilometer with the given segment
+// times, the pace of a 5:00 kilometer being 8:02.803 a mile
+func kilometerSplits(segments ...int32) []db.ResultSplit {
+	var splits []db.ResultSplit
+	var elapsed int32
+	for i, ms := range segments {
+		elapsed += ms
+		splits = append(splits, db.ResultSplit{ResultID: 7, DistanceMeters: int32(i+1) * 1000, ElapsedMs: elapsed})
+	}
+	return splits
+}
+
+func TestAnalyzeSplits(t *testing.T) {
+	tests := []struct {
+		name     string
+		splits   []db.ResultSplit
+		timeMs   sql.NullInt32
+		distance sql.NullInt32
+		// segments are each segment's time, with the finish last when
+		// finish is true
+		segments   []int32
+		finish     bool
+		splitType  string
+		difference int32
+	}{
+		{"no splits", nil, validMs(900000), validMs(3000), []int32{}, false, "", 0},
+		{"even", kilometerSplits(300000, 300000), validMs(900000), validMs(3000), []int32{300000, 300000, 300000}, true, SplitEven, 0},
+		// A last segment a second a mile slower is positive, and just under
+		// that is still even
+		{"positive", kilometerSplits(300000, 300000), validMs(900621), validMs(3000), []int32{300000, 300000, 300621}, true, SplitPositive, 1000},
+		{"nearly positive", kilometerSplits(300000, 300000), validMs(900620), validMs(3000), []int32{300000, 300000, 300620}, true, SplitEven, 998},
+		{"negative", kilometerSplits(300000, 300000), validMs(899378), validMs(3000), []int32{300000, 300000, 299378}, true, SplitNegative, -1001},
+		{"nearly negative", kilometerSplits(300000, 300000), validMs(899379), validMs(3000), []int32{300000, 300000, 299379}, true, SplitEven, -999},
+		// Without a final time or course, the splits alone are compared
+		{"no final time", kilometerSplits(300000, 310000), sql.NullInt32{}, validMs(3000), []int32{300000, 310000}, false, SplitPositive, 16094},
+		{"no course", kilometerSplits(300000, 290000), validMs(900000), sql.NullInt32{}, []int32{300000, 290000}, false, SplitNegative, -16093},
+		{"one split", kilometerSplits(300000), sql.NullInt32{}, sql.NullInt32{}, []int32{300000}, false, "", 0},
+		// Splits left past the finish by a shorter course are shown, with
+		// no finish segment
+		{"stale distance", kilometerSplits(300000, 300000), validMs(900000), validMs(2000), []int32{300000, 300000}, false, SplitEven, 0},
+		{"stale time", kilometerSplits(300000, 300000), validMs(590000), validMs(3000), []int32{300000, 300000}, false, SplitEven, 0},
+	}
+	for _, tt := range tests {
+		response := analyzeSplits(7, tt.splits, tt.timeMs, tt.distance)
+		if response.ResultID != 7 || response.SplitType != tt.splitType || response.SplitDifferenceMs != tt.difference {
+			t.Errorf("%s: got %s split by %d, want %s by %d", tt.name, response.SplitType, response.SplitDifferenceMs, tt.splitType, tt.difference)
+		}
+
+		segments := []int32{}
+		var elapsed, distance int32
+		for i, s := range response.Splits {
+			segments = append(segments, s.SegmentMs)
+			last := i == len(response.Splits)-1
+			if s.Finish != (last && tt.finish) {
+				t.Errorf("%s: segment %d finish is %v", tt.name, i, s.Finish)
+			}
+			elapsed += s.SegmentMs
+			if s.ElapsedMs != elapsed {
+				t.Errorf("%s: segment %d ends at %d, want %d", tt.name, i, s.ElapsedMs, elapsed)
+			}
+			if s.DistanceMeters <= distance {
+				t.Errorf("%s: segment %d ends at %dm", tt.name, i, s.DistanceMeters)
+			}
+			distance = s.DistanceMeters
+		}
+		if !reflect.DeepEqual(segments, tt.segments) {
+			t.Errorf("%s: got segments %v, want %v", tt.name, segments, tt.segments)
+		}
+	}
+}
+
+func TestAnalyzeSplitsFormats(t *testing.T) {
+	response := analyzeSplits(7, kilometerSplits(300000), validMs(610000), validMs(2000))
+	want := []SplitResponse{
+		{DistanceMeters: 1000, Time: "5:00", ElapsedMs: 300000, Segment: "5:00", SegmentMs: 300000, SegmentPace: "8:02.803", SegmentPaceMs: 482803},
+		{DistanceMeters: 2000, Time: "10:10", ElapsedMs: 610000, Segment: "5:10", SegmentMs: 310000, SegmentPace: "8:18.897", SegmentPaceMs: 498897, Finish: true},
+	}
+	if !reflect.DeepEqual(response.Splits, want) {
+		t.Errorf("got %+v, want %+v", response.Splits, want)
+	}
+}
@@ -0,0 +1,158 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	table := Table{
+		Header: []string{"Bib", "Name", "School", "Time", "Gap"},
+		Rows: [][]any{
+			{101, "Jane Smith", "Jones County", "19:45.20", 0},
+			{102, "=HYPERLINK(\"http://example.com\",\"Mary Doe\")", "+Mary Persons", "@SUM(A1)", -3},
+			{103, "-Sarah", "\tTab", nil, 1.5},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Bib,Name,School,Time,Gap\n" +
+		"101,Jane Smith,Jones County,19:45.20,0\n" +
+		"102,\"'=HYPERLINK(\"\"http://example.com\"\",\"\"Mary Doe\"\")\",'+Mary Persons,'@SUM(A1),-3\n" +
+		"103,'-Sarah,'\tTab,,1.5\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// xlsxSheet is the part of a worksheet the writer fills in
+type xlsxSheet struct {
+	Pane struct {
+		YSplit string `xml:"ySplit,attr"`
+		State  string `xml:"state,attr"`
+	} `xml:"sheetViews>sheetView>pane"`
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R      string `xml:"r,attr"`
+			T      string `xml:"t,attr"`
+			S      string `xml:"s,attr"`
+			V      string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readPart reads a part of a workbook
+func readPart(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("workbook has no %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWriteXLSX(t *testing.T) {
+	table := Table{
+		Sheet:  "Results: 9/14 [Varsity] Jones County Invitational",
+		Header: []string{"Place", "Name", "School", "Time", "Points"},
+		Rows: [][]any{
+			{1, "Jane Smith", "Jones County", "19:45.20", 1.5},
+			{2, `Tom & "Jerry" <Lee>`, nil, "  20:01 ", int32(-3)},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	wantNames := []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("parts are %v, want %v", names, wantNames)
+	}
+
+	// The sheet name loses the characters Excel forbids and is cut to 31
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(readPart(t, zr, "xl/workbook.xml"), &wb); err != nil {
+		t.Fatal(err)
+	}
+	if len(wb.Sheets) != 1 || wb.Sheets[0].Name != "Results  9 14  Varsity  Jones C" {
+		t.Errorf("sheets are %+v", wb.Sheets)
+	}
+
+	data := readPart(t, zr, "xl/worksheets/sheet1.xml")
+	// Text is escaped rather than breaking the XML
+	if !bytes.Contains(data, []byte("Tom &amp; &#34;Jerry&#34; &lt;Lee&gt;")) {
+		t.Errorf("text is not escaped:\n%s", data)
+	}
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Pane.YSplit != "1" || sheet.Pane.State != "frozen" {
+		t.Errorf("header row is not frozen: %+v", sheet.Pane)
+	}
+
+	// Each cell as reference, type, style and value; text is stored inline
+	// in the sheet rather than in a shared strings part
+	type cell struct{ r, t, s, value string }
+	want := [][]cell{
+		{{"A1", "inlineStr", "1", "Place"}, {"B1", "inlineStr", "1", "Name"}, {"C1", "inlineStr", "1", "School"}, {"D1", "inlineStr", "1", "Time"}, {"E1", "inlineStr", "1", "Points"}},
+		{{"A2", "", "", "1"}, {"B2", "inlineStr", "", "Jane Smith"}, {"C2", "inlineStr", "", "Jones County"}, {"D2", "inlineStr", "", "19:45.20"}, {"E2", "", "", "1.5"}},
+		// The empty school is left out, and spaces in text are kept
+		{{"A3", "", "", "2"}, {"B3", "inlineStr", "", `Tom & "Jerry" <Lee>`}, {"D3", "inlineStr", "", "  20:01 "}, {"E3", "", "", "-3"}},
+	}
+	if len(sheet.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(sheet.Rows), len(want))
+	}
+	for i, row := range sheet.Rows {
+		if row.R != strconv.Itoa(i+1) {
+			t.Errorf("row %d is numbered %s", i+1, row.R)
+		}
+		var got []cell
+		for _, c := range row.Cells {
+			value := c.V
+			if c.T == "inlineStr" {
+				value = c.Inline
+			}
+			got = append(got, cell{c.R, c.T, c.S, value})
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("row %d is %q, want %q", i+1, got, want[i])
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
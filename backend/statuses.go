@@ -0,0 +1,75 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"jones-county-xc/backend/racetime"
+)
+
+// Statuses a result can have. Finishers, including unattached runners,
+// have a time and a place; the others are listed with their race but left
+// out of places, rankings, team scores and personal records.
+const (
+	StatusFinished   = "finished"
+	StatusUnattached = "unattached"
+	StatusDNF        = "dnf"
+	StatusDNS        = "dns"
+	StatusDQ         = "dq"
+)
+
+// validStatus reports whether status is one of the known result statuses
+func validStatus(status string) bool {
+	switch status {
+	case StatusFinished, StatusUnattached, StatusDNF, StatusDNS, StatusDQ:
+		return true
+	}
+	return false
+}
+
+// isFinisher reports whether a result with status counts as a finish
+func isFinisher(status string) bool {
+	return status == StatusFinished || status == StatusUnattached
+}
+
+// resultTime checks a result's status and time and converts the time for
+// the database. Finishers need a time, runners who did not start or finish
+// cannot have one, and a disqualified runner's time is optional. An empty
+// status means finished, unless the time is itself a status such as "DNF",
+// as older clients sent it.
+func resultTime(status, time string) (string, sql.NullInt32, error) {
+	if status == "" {
+		status = StatusFinished
+		if s := strings.ToLower(strings.TrimSpace(time)); s == StatusDNF || s == StatusDNS || s == StatusDQ {
+			status, time = s, ""
+		}
+	}
+	if !validStatus(status) {
+		return "", sql.NullInt32{}, errors.New("Status must be finished, unattached, dnf, dns or dq")
+	}
+
+	if time == "" {
+		if isFinisher(status) {
+			return "", sql.NullInt32{}, errors.New("A time is required for finishers")
+		}
+		return status, sql.NullInt32{}, nil
+	}
+	if status == StatusDNF || status == StatusDNS {
+		return "", sql.NullInt32{}, errors.New("Runners who did not start or finish have no time")
+	}
+	ms, err := racetime.Parse(time)
+	if err != nil {
+		return "", sql.NullInt32{}, err
+	}
+	return status, sql.NullInt32{Int32: ms, Valid: true}, nil
+}
+
+// formatResultTime formats a result's time, or its status in capitals
+// when it has no time, such as "DNF"
+func formatResultTime(status string, timeMs sql.NullInt32) string {
+	if !timeMs.Valid {
+		return strings.ToUpper(status)
+	}
+	return racetime.Format(timeMs.Int32)
+}
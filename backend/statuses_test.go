@@ -0,0 +1,81 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+
+	"jones-county-xc/backend/db"
+)
+
+func TestResultTime(t *testing.T) {
+	tests := []struct {
+		status, time string
+		wantStatus   string
+		wantMs       sql.NullInt32
+		err          string
+	}{
+		{"", "18:20", StatusFinished, validMs(1100000), ""},
+		{StatusUnattached, "18:20", StatusUnattached, validMs(1100000), ""},
+		// Older clients sent the status as the time
+		{"", "DNF", StatusDNF, sql.NullInt32{}, ""},
+		{"", " dq ", StatusDQ, sql.NullInt32{}, ""},
+		{StatusDNS, "", StatusDNS, sql.NullInt32{}, ""},
+		// A disqualified runner's time may be kept
+		{StatusDQ, "18:20", StatusDQ, validMs(1100000), ""},
+		{StatusDQ, "", StatusDQ, sql.NullInt32{}, ""},
+		{StatusFinished, "", "", sql.NullInt32{}, "A time is required for finishers"},
+		{StatusUnattached, "", "", sql.NullInt32{}, "A time is required for finishers"},
+		{StatusDNF, "18:20", "", sql.NullInt32{}, "Runners who did not start or finish have no time"},
+		{StatusDNS, "18:20", "", sql.NullInt32{}, "Runners who did not start or finish have no time"},
+		{"DNF", "", "", sql.NullInt32{}, "Status must be finished, unattached, dnf, dns or dq"},
+		{"scratched", "", "", sql.NullInt32{}, "Status must be finished, unattached, dnf, dns or dq"},
+	}
+	for _, tt := range tests {
+		status, ms, err := resultTime(tt.status, tt.time)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%q %q: got %v, want %q", tt.status, tt.time, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || status != tt.wantStatus || ms != tt.wantMs {
+			t.Errorf("%q %q: got %s %v %v, want %s %v", tt.status, tt.time, status, ms, err, tt.wantStatus, tt.wantMs)
+		}
+	}
+}
+
+func TestResultStatusFormatting(t *testing.T) {
+	tests := []struct {
+		status string
+		timeMs sql.NullInt32
+		time   string
+		pace   string
+	}{
+		{StatusFinished, validMs(1100000), "18:20", "5:54.056"},
+		{StatusUnattached, validMs(1100000), "18:20", "5:54.056"},
+		{StatusDNF, sql.NullInt32{}, "DNF", ""},
+		{StatusDNS, sql.NullInt32{}, "DNS", ""},
+		{StatusDQ, sql.NullInt32{}, "DQ", ""},
+		// A disqualified runner's kept time is shown rather than the status
+		{StatusDQ, validMs(1100000), "18:20", "5:54.056"},
+	}
+	for _, tt := range tests {
+		if got := formatResultTime(tt.status, tt.timeMs); got != tt.time {
+			t.Errorf("%s: formatted as %q, want %q", tt.status, got, tt.time)
+		}
+
+		r := newResultResponse(db.GetResultsForMeetRow{
+			AthleteID:      sql.NullInt32{Int32: 4, Valid: true},
+			Status:         tt.status,
+			TimeMs:         tt.timeMs,
+			DistanceMeters: validMs(5000),
+		})
+		if r.Status != tt.status || r.Time != tt.time || r.Pace != tt.pace || r.Place != 0 {
+			t.Errorf("%s: got %+v", tt.status, r)
+		}
+		// Unattached runners race for no team
+		if team := tt.status != StatusUnattached; (r.Team != "") != team {
+			t.Errorf("%s: got team %q", tt.status, r.Team)
+		}
+	}
+}
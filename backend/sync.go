@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sync operation types
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+// Outcomes of a sync operation. A conflict is an update or delete of a
+// result that changed on the server since the client's base version; a
+// rejected operation is invalid, such as a result for a runner not found.
+const (
+	SyncApplied  = "applied"
+	SyncConflict = "conflict"
+	SyncRejected = "rejected"
+)
+
+// maxSyncOperations limits how many operations one sync request may carry
+const maxSyncOperations = 500
+
+// syncOperationRequest is a result change made offline. ID is a UUID the
+// client generates for the operation, so that sending it again does not
+// apply it twice. A created result is named by its own client UUID, which
+// later operations may use before the client learns its server ID.
+type syncOperationRequest struct {
+	ID       string `json:"id" binding:"required,uuid"`
+	Type     string `json:"type" binding:"required,oneof=create update delete"`
+	ResultID int32  `json:"resultId"`
+	ClientID string `json:"clientId" binding:"omitempty,uuid"`
+	// BaseVersion is the version of the result the client changed, for
+	// updates and deletes
+	BaseVersion int32 `json:"baseVersion"`
+	// Result is the new result, for creates and updates
+	Result *resultRequest `json:"result"`
+}
+
+// SyncResultResponse is the server's copy of a result, sent back with a
+// conflict so the client can resolve it
+type SyncResultResponse struct {
+	ID           int32  `json:"id"`
+	ClientID     string `json:"clientId,omitempty"`
+	Version      int32  `json:"version"`
+	RaceID       int32  `json:"raceId"`
+	AthleteID    int32  `json:"athleteId,omitempty"`
+	CompetitorID int32  `json:"competitorId,omitempty"`
+	Status       string `json:"status"`
+	Time         string `json:"time"`
+	TimeMs       int32  `json:"timeMs"`
+	Place        int32  `json:"place"`
+}
+
+func newSyncResultResponse(r db.Result) *SyncResultResponse {
+	return &SyncResultResponse{
+		ID:           r.ID,
+		ClientID:     r.ClientID.String,
+		Version:      r.Version,
+		RaceID:       r.RaceID,
+		AthleteID:    r.AthleteID.Int32,
+		CompetitorID: r.CompetitorID.Int32,
+		Status:       r.Status,
+		Time:         formatResultTime(r.Status, r.TimeMs),
+		TimeMs:       r.TimeMs.Int32,
+		Place:        r.Place.Int32,
+	}
+}
+
+// SyncOperationResponse is the outcome of one sync operation. ResultID and
+// Version are the result's after an applied operation; Replayed is true
+// when the operation had already been applied by an earlier request.
+// Server is the server's copy of the result with a conflict, and is
+// omitted when the result was deleted.
+type SyncOperationResponse struct {
+	ID       string              `json:"id"`
+	Status   string              `json:"status"`
+	Replayed bool                `json:"replayed,omitempty"`
+	ResultID int32               `json:"resultId,omitempty"`
+	Version  int32               `json:"version,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Server   *SyncResultResponse `json:"server,omitempty"`
+}
+
+// syncChanges tracks the meets an operation changes so their live
+// results can be published once it is committed
+type syncChanges struct {
+	q     *db.Queries
+	meets map[int32]*meetSnapshot
+}
+
+// snapshot records a meet's results before the operation first changes it
+func (s *syncChanges) snapshot(meetID int32) {
+	if _, ok := s.meets[meetID]; !ok {
+		s.meets[meetID] = snapshotMeet(s.q, meetID)
+	}
+}
+
+// snapshotResult records the results of the meet a result belongs to
+func (s *syncChanges) snapshotResult(r db.Result) error {
+	race, err := s.q.GetRaceByID(context.Background(), r.RaceID)
+	if err != nil {
+		return err
+	}
+	s.snapshot(race.MeetID)
+	return nil
+}
+
+func (s *syncChanges) publish() {
+	for _, changes := range s.meets {
+		changes.publish()
+	}
+}
+
+// syncResult finds the result an operation names using q, by its server ID
+// if it has one and otherwise its client UUID; found is false when there is
+// no such result
+func (op syncOperationRequest) syncResult(q *db.Queries) (result db.Result, found bool, err error) {
+	if op.ResultID != 0 {
+		result, err = q.GetResultByID(context.Background(), op.ResultID)
+	} else {
+		result, err = q.GetResultByClientID(context.Background(), sql.NullString{String: op.ClientID, Valid: true})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Result{}, false, nil
+	}
+	return result, err == nil, err
+}
+
+// syncConflict reports that the result changed on the server, with the
+// server's current copy if it still has one
+func syncConflict(q *db.Queries, op syncOperationRequest, resultID int32) (SyncOperationResponse, error) {
+	resp := SyncOperationResponse{ID: op.ID, Status: SyncConflict, Error: "Result was deleted"}
+	result, err := q.GetResultByID(context.Background(), resultID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return resp, nil
+	}
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	resp.Error = fmt.Sprintf("Result is at version %d, not %d", result.Version, op.BaseVersion)
+	resp.Server = newSyncResultResponse(result)
+	return resp, nil
+}
+
+// syncApplied reports an operation that created or updated a result, with
+// the result's version once its race has been re-ranked, since a change
+// of place bumps it again
+func syncApplied(q *db.Queries, op syncOperationRequest, resultID int32) (SyncOperationResponse, error) {
+	result, err := q.GetResultByID(context.Background(), resultID)
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	return SyncOperationResponse{ID: op.ID, Status: SyncApplied, ResultID: result.ID, Version: result.Version}, nil
+}
+
+// syncRejected reports an invalid operation
+func syncRejected(op syncOperationRequest, err error) (SyncOperationResponse, error) {
+	return SyncOperationResponse{ID: op.ID, Status: SyncRejected, Error: err.Error()}, nil
+}
+
+// applySyncOperation applies an operation in its own transaction and
+// records it, unless it was applied before. Only errors from the database
+// are returned; problems with the operation are reported in its response.
+func applySyncOperation(userID int32, op syncOperationRequest) (SyncOperationResponse, error) {
+	tx, err := database.Begin()
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	defer tx.Rollback()
+	q := queries.WithTx(tx)
+
+	applied, err := q.GetSyncOperation(context.Background(), op.ID)
+	if err == nil {
+		return SyncOperationResponse{ID: op.ID, Status: SyncApplied, Replayed: true, ResultID: applied.ResultID, Version: applied.Version}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return SyncOperationResponse{}, err
+	}
+
+	if op.Type != SyncCreate && op.ResultID == 0 && op.ClientID == "" {
+		return syncRejected(op, errors.New("A result ID or client ID is required"))
+	}
+
+	changes := &syncChanges{q: q, meets: make(map[int32]*meetSnapshot)}
+	var resp SyncOperationResponse
+	switch op.Type {
+	case SyncCreate:
+		resp, err = syncCreateResult(q, changes, op)
+	case SyncUpdate:
+		resp, err = syncUpdateResult(q, changes, op)
+	default:
+		resp, err = syncDeleteResult(q, changes, op)
+	}
+	if err != nil || resp.Status != SyncApplied {
+		return resp, err
+	}
+
+	err = q.CreateSyncOperation(context.Background(), db.CreateSyncOperationParams{
+		ID:        op.ID,
+		UserID:    sql.NullInt32{Int32: userID, Valid: userID != 0},
+		Operation: op.Type,
+		ResultID:  resp.ResultID,
+		Version:   resp.Version,
+	})
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return SyncOperationResponse{}, err
+	}
+	changes.publish()
+	return resp, nil
+}
+
+// syncCreateResult adds a result created offline. A result whose client
+// UUID is already on the server was created by an earlier sync and is
+// left as it is.
+func syncCreateResult(q *db.Queries, changes *syncChanges, op syncOperationRequest) (SyncOperationResponse, error) {
+	if op.ClientID == "" {
+		return syncRejected(op, errors.New("A created result needs its client ID"))
+	}
+	if op.Result == nil {
+		return syncRejected(op, errors.New("A result is required"))
+	}
+
+	existing, err := q.GetResultByClientID(context.Background(), sql.NullString{String: op.ClientID, Valid: true})
+	if err == nil {
+		return SyncOperationResponse{ID: op.ID, Status: SyncApplied, ResultID: existing.ID, Version: existing.Version}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return SyncOperationResponse{}, err
+	}
+
+	row, err := op.Result.validate(q)
+	if isResultError(err) {
+		return syncRejected(op, err)
+	}
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	row.params.ClientID = sql.NullString{String: op.ClientID, Valid: true}
+
+	e, err := entriesForRace(q, make(map[int32]*raceEntries), row.params.RaceID)
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	if err := e.check(row.params.AthleteID, row.params.CompetitorID, row.params.Place); err != nil {
+		return syncRejected(op, err)
+	}
+	changes.snapshot(row.meetID)
+
+	id, err := createResult(q, row)
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	athleteIDs := make(map[int32]bool)
+	if row.params.AthleteID.Valid {
+		athleteIDs[row.params.AthleteID.Int32] = true
+	}
+	if err := finishResults(q, map[int32]bool{row.params.RaceID: true}, athleteIDs); err != nil {
+		return SyncOperationResponse{}, err
+	}
+	return syncApplied(q, op, id)
+}
+
+// syncUpdateResult replaces a result and its splits, if it is still at
+// the client's base version
+func syncUpdateResult(q *db.Queries, changes *syncChanges, op syncOperationRequest) (SyncOperationResponse, error) {
+	current, found, err := op.syncResult(q)
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	if !found || current.Version != op.BaseVersion {
+		return syncConflict(q, op, current.ID)
+	}
+	if op.Result == nil {
+		return syncRejected(op, errors.New("A result is required"))
+	}
+
+	row, err := op.Result.validate(q)
+	if isResultError(err) {
+		return syncRejected(op, err)
+	}
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	e, err := entriesForRace(q, make(map[int32]*raceEntries), row.params.RaceID)
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	if row.params.RaceID == current.RaceID {
+		e.remove(current.AthleteID, current.CompetitorID, current.Place)
+	}
+	if err := e.check(row.params.AthleteID, row.params.CompetitorID, row.params.Place); err != nil {
+		return syncRejected(op, err)
+	}
+	if err := changes.snapshotResult(current); err != nil {
+		return SyncOperationResponse{}, err
+	}
+	changes.snapshot(row.meetID)
+
+	n, err := q.UpdateResultVersion(context.Background(), db.UpdateResultVersionParams{
+		AthleteID:    row.params.AthleteID,
+		CompetitorID: row.params.CompetitorID,
+		RaceID:       row.params.RaceID,
+		Status:       row.params.Status,
+		TimeMs:       row.params.TimeMs,
+		Place:        row.params.Place,
+		ID:           current.ID,
+		Version:      op.BaseVersion,
+	})
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	if n == 0 {
+		return syncConflict(q, op, current.ID)
+	}
+
+	if err := q.DeleteSplitsForResult(context.Background(), current.ID); err != nil {
+		return SyncOperationResponse{}, err
+	}
+	if err := createSplits(q, current.ID, row.splits); err != nil {
+		return SyncOperationResponse{}, err
+	}
+
+	raceIDs := map[int32]bool{current.RaceID: true, row.params.RaceID: true}
+	athleteIDs := make(map[int32]bool)
+	for _, athleteID := range []sql.NullInt32{current.AthleteID, row.params.AthleteID} {
+		if athleteID.Valid {
+			athleteIDs[athleteID.Int32] = true
+		}
+	}
+	if err := finishResults(q, raceIDs, athleteIDs); err != nil {
+		return SyncOperationResponse{}, err
+	}
+	return syncApplied(q, op, current.ID)
+}
+
+// syncDeleteResult deletes a result, if it is still at the client's base
+// version. A result already gone counts as deleted.
+func syncDeleteResult(q *db.Queries, changes *syncChanges, op syncOperationRequest) (SyncOperationResponse, error) {
+	current, found, err := op.syncResult(q)
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	if !found {
+		return SyncOperationResponse{ID: op.ID, Status: SyncApplied, ResultID: op.ResultID, Version: op.BaseVersion}, nil
+	}
+	if current.Version != op.BaseVersion {
+		return syncConflict(q, op, current.ID)
+	}
+	if err := changes.snapshotResult(current); err != nil {
+		return SyncOperationResponse{}, err
+	}
+
+	n, err := q.DeleteResultVersion(context.Background(), db.DeleteResultVersionParams{ID: current.ID, Version: op.BaseVersion})
+	if err != nil {
+		return SyncOperationResponse{}, err
+	}
+	if n == 0 {
+		return syncConflict(q, op, current.ID)
+	}
+
+	athleteIDs := make(map[int32]bool)
+	if current.AthleteID.Valid {
+		athleteIDs[current.AthleteID.Int32] = true
+	}
+	if err := finishResults(q, map[int32]bool{current.RaceID: true}, athleteIDs); err != nil {
+		return SyncOperationResponse{}, err
+	}
+	return SyncOperationResponse{ID: op.ID, Status: SyncApplied, ResultID: current.ID, Version: current.Version}, nil
+}
+
+// registerSyncRoutes adds offline sync routes to the statistician group
+func registerSyncRoutes(statisticians *gin.RouterGroup) {
+	// Apply a batch of result changes made offline, in order. Each
+	// operation is applied on its own, so one conflict or invalid operation
+	// does not hold back the rest. If the server fails partway through, the
+	// whole batch can be sent again: operations already applied are
+	// reported as replayed rather than applied twice.
+	statisticians.POST("/results/sync", func(c *gin.Context) {
+		var req struct {
+			Operations []syncOperationRequest `json:"operations" binding:"required,dive"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if len(req.Operations) == 0 {
+			c.JSON(400, gin.H{"error": "At least one operation is required"})
+			return
+		}
+		if len(req.Operations) > maxSyncOperations {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("At most %d operations can be synced at once", maxSyncOperations)})
+			return
+		}
+
+		userID := currentSession(c).UserID
+		outcomes := make([]SyncOperationResponse, len(req.Operations))
+		counts := make(map[string]int)
+		for i, op := range req.Operations {
+			outcome, err := applySyncOperation(userID, op)
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			outcomes[i] = outcome
+			counts[outcome.Status]++
+		}
+
+		c.JSON(200, gin.H{
+			"operations": outcomes,
+			"applied":    counts[SyncApplied],
+			"conflicts":  counts[SyncConflict],
+			"rejected":   counts[SyncRejected],
+		})
+	})
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	syncOpID     = "6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b"
+	syncOpID2    = "7a2d3b0f-4c5e-4f60-9b8c-0d1e2f3a4b5c"
+	syncClientID = "0b9f8e7d-6c5b-4a39-8281-7f6e5d4c3b2a"
+)
+
+// syncResultRow is a finished result in race 2 for athlete 4
+func syncResultRow(id any, version int64, clientID any) []driver.Value {
+	return []driver.Value{id, int64(4), nil, int64(2), StatusFinished, int64(1185200), int64(1), clientID, version, time.Now()}
+}
+
+// syncRace answers the queries that check a new result for athlete 4 in
+// race 2, with competitors in the race when places are derived
+func syncRace(fake *fakeDB, derivedPlaces bool) {
+	fake.rows["GetAthleteByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, "Runner", int64(10), "girls", nil, time.Now()}}
+	}
+	fake.rows["GetRaceByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, int64(1), nil, "Varsity Girls", "girls", DivisionVarsity, nil, nil, time.Now()}}
+	}
+	fake.rows["GetRaceDistance"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{int64(5000)}}
+	}
+	fake.rows["CountCompetitorResultsForRace"] = func([]driver.NamedValue) [][]driver.Value {
+		if derivedPlaces {
+			return [][]driver.Value{{int64(3)}}
+		}
+		return [][]driver.Value{{int64(0)}}
+	}
+}
+
+type syncResponse struct {
+	Operations []SyncOperationResponse `json:"operations"`
+	Applied    int                     `json:"applied"`
+	Conflicts  int                     `json:"conflicts"`
+	Rejected   int                     `json:"rejected"`
+}
+
+// postSync sends a sync request and decodes its response
+func postSync(t *testing.T, fake *fakeDB, body string) syncResponse {
+	t.Helper()
+	w := serveJSON(newRouter(), "POST", "/api/results/sync", fake.login(RoleStatistician), body)
+	if w.Code != 200 {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	var resp syncResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestSyncReplayedOperation(t *testing.T) {
+	fake := useFakeDB(t)
+	fake.rows["GetSyncOperation"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{args[0].Value, int64(1), SyncCreate, int64(7), int64(2), time.Now()}}
+	}
+
+	resp := postSync(t, fake, `{"operations":[{"id":"`+syncOpID+`","type":"create","clientId":"`+syncClientID+`",
+		"result":{"athleteId":4,"raceId":2,"time":"19:45.2"}}]}`)
+
+	want := []SyncOperationResponse{{ID: syncOpID, Status: SyncApplied, Replayed: true, ResultID: 7, Version: 2}}
+	if !reflect.DeepEqual(resp.Operations, want) {
+		t.Errorf("got %+v, want %+v", resp.Operations, want)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("a replayed operation ran %v", fake.execs)
+	}
+}
+
+func TestSyncCreateExistingClientID(t *testing.T) {
+	fake := useFakeDB(t)
+	fake.rows["GetResultByClientID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{syncResultRow(int64(7), 3, args[0].Value)}
+	}
+
+	resp := postSync(t, fake, `{"operations":[{"id":"`+syncOpID+`","type":"create","clientId":"`+syncClientID+`",
+		"result":{"athleteId":4,"raceId":2,"time":"19:45.2"}}]}`)
+
+	// The result is left as it is, and the operation is recorded so it is
+	// replayed next time
+	want := []SyncOperationResponse{{ID: syncOpID, Status: SyncApplied, ResultID: 7, Version: 3}}
+	if !reflect.DeepEqual(resp.Operations, want) {
+		t.Errorf("got %+v, want %+v", resp.Operations, want)
+	}
+	if want := []string{"CreateSyncOperation", "COMMIT"}; !reflect.DeepEqual(fake.execs, want) {
+		t.Errorf("ran %v, want %v", fake.execs, want)
+	}
+}
+
+func TestSyncCreateReturnsVersionAfterRerank(t *testing.T) {
+	fake := useFakeDB(t)
+	syncRace(fake, true)
+	// Deriving places gave the new result a place, bumping its version
+	fake.rows["GetResultByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{syncResultRow(args[0].Value, 2, syncClientID)}
+	}
+
+	resp := postSync(t, fake, `{"operations":[{"id":"`+syncOpID+`","type":"create","clientId":"`+syncClientID+`",
+		"result":{"athleteId":4,"raceId":2,"time":"19:45.2"}}]}`)
+
+	// The fake gives the first insert ID 1
+	want := []SyncOperationResponse{{ID: syncOpID, Status: SyncApplied, ResultID: 1, Version: 2}}
+	if !reflect.DeepEqual(resp.Operations, want) {
+		t.Errorf("got %+v, want %+v", resp.Operations, want)
+	}
+	if got := fake.execArgs["CreateSyncOperation"][4].Value; got != int64(2) {
+		t.Errorf("recorded version %v, want 2", got)
+	}
+	wantExecs := []string{"CreateResult", "BumpRerankedResultVersions", "UpdateRacePlaces", "DeletePersonalRecordsForAthlete", "CreatePersonalRecordsForAthlete", "CreateSyncOperation", "COMMIT"}
+	if !reflect.DeepEqual(fake.execs, wantExecs) {
+		t.Errorf("ran %v, want %v", fake.execs, wantExecs)
+	}
+}
+
+func TestSyncUpdateConflict(t *testing.T) {
+	fake := useFakeDB(t)
+	fake.rows["GetResultByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{syncResultRow(args[0].Value, 3, nil)}
+	}
+
+	resp := postSync(t, fake, `{"operations":[{"id":"`+syncOpID+`","type":"update","resultId":7,"baseVersion":2,
+		"result":{"athleteId":4,"raceId":2,"time":"19:40"}}]}`)
+
+	if resp.Conflicts != 1 || len(resp.Operations) != 1 {
+		t.Fatalf("got %+v, want one conflict", resp)
+	}
+	op := resp.Operations[0]
+	if op.Status != SyncConflict || op.Error != "Result is at version 3, not 2" {
+		t.Errorf("got %s %q", op.Status, op.Error)
+	}
+	if op.Server == nil || op.Server.ID != 7 || op.Server.Version != 3 || op.Server.Time != "19:45.2" {
+		t.Errorf("got server copy %+v", op.Server)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("a conflict ran %v", fake.execs)
+	}
+}
+
+func TestSyncUpdateDeletedResult(t *testing.T) {
+	fake := useFakeDB(t)
+
+	resp := postSync(t, fake, `{"operations":[{"id":"`+syncOpID+`","type":"update","resultId":7,"baseVersion":2,
+		"result":{"athleteId":4,"raceId":2,"time":"19:40"}}]}`)
+
+	want := []SyncOperationResponse{{ID: syncOpID, Status: SyncConflict, Error: "Result was deleted"}}
+	if !reflect.DeepEqual(resp.Operations, want) {
+		t.Errorf("got %+v, want %+v", resp.Operations, want)
+	}
+	if len(fake.execs) != 0 {
+		t.Errorf("a conflict ran %v", fake.execs)
+	}
+}
+
+func TestSyncUpdateByClientID(t *testing.T) {
+	fake := useFakeDB(t)
+	syncRace(fake, false)
+	// The result exists once the first operation has created it
+	fake.rows["GetResultByClientID"] = func(args []driver.NamedValue) [][]driver.Value {
+		fake.mu.Lock()
+		created := slices.Contains(fake.execs, "CreateResult")
+		fake.mu.Unlock()
+		if !created {
+			return nil
+		}
+		return [][]driver.Value{syncResultRow(int64(1), 1, args[0].Value)}
+	}
+	fake.rows["GetResultByID"] = func(args []driver.NamedValue) [][]driver.Value {
+		fake.mu.Lock()
+		updated := slices.Contains(fake.execs, "UpdateResultVersion")
+		fake.mu.Unlock()
+		version := int64(1)
+		if updated {
+			version = 2
+		}
+		return [][]driver.Value{syncResultRow(args[0].Value, version, syncClientID)}
+	}
+
+	// The client has not heard the new result's server ID when it edits it
+	resp := postSync(t, fake, `{"operations":[
+		{"id":"`+syncOpID+`","type":"create","clientId":"`+syncClientID+`",
+			"result":{"athleteId":4,"raceId":2,"time":"19:45.2"}},
+		{"id":"`+syncOpID2+`","type":"update","clientId":"`+syncClientID+`","baseVersion":1,
+			"result":{"athleteId":4,"raceId":2,"time":"19:40"}}]}`)
+
+	want := []SyncOperationResponse{
+		{ID: syncOpID, Status: SyncApplied, ResultID: 1, Version: 1},
+		{ID: syncOpID2, Status: SyncApplied, ResultID: 1, Version: 2},
+	}
+	if !reflect.DeepEqual(resp.Operations, want) {
+		t.Errorf("got %+v, want %+v", resp.Operations, want)
+	}
+	args := fake.execArgs["UpdateResultVersion"]
+	if len(args) != 8 || args[6].Value != int64(1) || args[7].Value != int64(1) {
+		t.Errorf("updated with %v, want result 1 at version 1", args)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"jones-county-xc/backend/db"
+	"jones-county-xc/backend/racetime"
+	"jones-county-xc/backend/scoring"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeamScoresResponse struct {
+	RaceID          int32               `json:"raceId"`
+	Teams           []TeamScoreResponse `json:"teams"`
+	IncompleteTeams []string            `json:"incompleteTeams"`
+}
+
+type TeamScoreResponse struct {
+	Team    string               `json:"team"`
+	Place   int                  `json:"place"`
+	Score   int                  `json:"score"`
+	Runners []TeamRunnerResponse `json:"runners"`
+}
+
+// TeamRunnerResponse is one of a team's first seven runners
+type TeamRunnerResponse struct {
+	ResultID    int32  `json:"resultId"`
+	AthleteName string `json:"athleteName"`
+	Time        string `json:"time"`
+	Place       int    `json:"place"`
+	Points      int    `json:"points"`
+	Scoring     bool   `json:"scoring"`
+}
+
+// scoreRace scores a race's results, ordering finishers by time. Runners
+// who did not finish or were disqualified are left out.
+func scoreRace(results []db.GetResultsForRaceRow) TeamScoresResponse {
+	var finished []db.GetResultsForRaceRow
+	for _, r := range results {
+		if isFinisher(r.Status) && r.TimeMs.Valid {
+			finished = append(finished, r)
+		}
+	}
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].TimeMs.Int32 < finished[j].TimeMs.Int32
+	})
+
+	byID := make(map[int32]db.GetResultsForRaceRow)
+	finishers := make([]scoring.Finisher, len(finished))
+	for i, r := range finished {
+		finishers[i] = scoring.Finisher{ID: r.ID, Team: resultTeam(r.AthleteID, r.SchoolName, r.Status)}
+		byID[r.ID] = r
+	}
+
+	scored := scoring.Score(finishers)
+	response := TeamScoresResponse{
+		Teams:           make([]TeamScoreResponse, len(scored.Teams)),
+		IncompleteTeams: scored.IncompleteTeams,
+	}
+	if response.IncompleteTeams == nil {
+		response.IncompleteTeams = []string{}
+	}
+	for i, t := range scored.Teams {
+		team := TeamScoreResponse{
+			Team:    t.Team,
+			Place:   t.Place,
+			Score:   t.Score,
+			Runners: make([]TeamRunnerResponse, len(t.Runners)),
+		}
+		for j, p := range t.Runners {
+			r := byID[p.ID]
+			team.Runners[j] = TeamRunnerResponse{
+				ResultID:    r.ID,
+				AthleteName: r.AthleteName,
+				Time:        racetime.Format(r.TimeMs.Int32),
+				Place:       p.Place,
+				Points:      p.Points,
+				Scoring:     p.Scoring,
+			}
+		}
+		response.Teams[i] = team
+	}
+	return response
+}
+
+// registerTeamScoreRoutes adds team scoring routes to the public group
+func registerTeamScoreRoutes(api *gin.RouterGroup) {
+	// Get the team scores for a race
+	api.GET("/races/:id/team-scores", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid race ID"})
+			return
+		}
+
+		race, err := queries.GetRaceByID(context.Background(), int32(id))
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Race not found"})
+			return
+		}
+
+		results, err := queries.GetResultsForRace(context.Background(), race.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := scoreRace(results)
+		response.RaceID = race.ID
+		c.JSON(200, response)
+	})
+}
@@ -0,0 +1,71 @@
+package timing
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Simulation stands in for a reader at the finish line, for trying out
+// the ingest service without timing hardware
+type Simulation struct {
+	// Chips are the chips of the runners in the race
+	Chips []string
+	// Gun is when the race started
+	Gun time.Time
+	// Runners finish at random between Fastest and Slowest after the gun
+	Fastest, Slowest time.Duration
+	// Repeats is how many times each chip is read as it crosses the line
+	Repeats int
+	// Speed is how many times faster than real time reads are sent; reads
+	// are sent all at once when it is 0
+	Speed float64
+	Rand  *rand.Rand
+}
+
+// simulatedRead is a read and when, after the gun, the reader sends it
+type simulatedRead struct {
+	Read
+	at time.Duration
+}
+
+// reads makes every read of the race in the order they are sent
+func (s Simulation) reads() []simulatedRead {
+	rnd := s.Rand
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	repeats := max(s.Repeats, 1)
+	spread := max(s.Slowest-s.Fastest, 0)
+
+	var reads []simulatedRead
+	for _, chip := range s.Chips {
+		finish := s.Fastest + time.Duration(rnd.Int63n(int64(spread)+1))
+		for i := 0; i < repeats; i++ {
+			// a runner stays in range of the antenna for about a second
+			at := finish + time.Duration(i)*time.Second/time.Duration(repeats) + time.Duration(rnd.Intn(50))*time.Millisecond
+			reads = append(reads, simulatedRead{Read: Read{Chip: chip, Time: s.Gun.Add(at)}, at: at})
+		}
+	}
+	sort.SliceStable(reads, func(i, j int) bool { return reads[i].at < reads[j].at })
+	return reads
+}
+
+// Run writes the race's reads to w in the default line format, pacing
+// them by Speed, and returns how many it wrote
+func (s Simulation) Run(w io.Writer) (int, error) {
+	reads := s.reads()
+	var sent time.Duration
+	for i, r := range reads {
+		if s.Speed > 0 && r.at > sent {
+			time.Sleep(time.Duration(float64(r.at-sent) / s.Speed))
+			sent = r.at
+		}
+		if _, err := fmt.Fprintf(w, "%s,%s\r\n", r.Chip, r.Time.Format("15:04:05.000")); err != nil {
+			return i, err
+		}
+	}
+	return len(reads), nil
+}
@@ -0,0 +1,161 @@
+package timing
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSimulation() Simulation {
+	return Simulation{
+		Chips:   []string{"058001", "058002", "058003", "058004"},
+		Gun:     time.Date(2024, 10, 12, 9, 0, 0, 0, time.UTC),
+		Fastest: 16 * time.Minute,
+		Slowest: 22 * time.Minute,
+		Repeats: 3,
+		Rand:    rand.New(rand.NewSource(1)),
+	}
+}
+
+// checkSimulatedReads parses lines sent by testSimulation and checks that
+// each chip is read Repeats times in a second after finishing in range
+func checkSimulatedReads(t *testing.T, s Simulation, lines []string) {
+	t.Helper()
+	p, err := NewProtocol(DefaultPattern, DefaultTimeLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != len(s.Chips)*s.Repeats {
+		t.Fatalf("got %d reads, want %d", len(lines), len(s.Chips)*s.Repeats)
+	}
+
+	deduper := NewDeduper(5 * time.Second)
+	reads := make(map[string][]time.Time)
+	firsts := 0
+	for _, line := range lines {
+		r, err := p.Parse(line, s.Gun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reads[r.Chip] = append(reads[r.Chip], r.Time)
+		if deduper.First(r) {
+			firsts++
+		}
+	}
+	if firsts != len(s.Chips) {
+		t.Errorf("%d reads are first, want one per chip", firsts)
+	}
+
+	for _, chip := range s.Chips {
+		times := reads[chip]
+		if len(times) != s.Repeats {
+			t.Errorf("chip %s read %d times, want %d", chip, len(times), s.Repeats)
+			continue
+		}
+		finish := times[0].Sub(s.Gun)
+		if finish < s.Fastest || finish > s.Slowest+50*time.Millisecond {
+			t.Errorf("chip %s finished in %s", chip, finish)
+		}
+		if last := times[len(times)-1].Sub(times[0]); last >= 2*time.Second {
+			t.Errorf("chip %s read over %s", chip, last)
+		}
+	}
+}
+
+func TestSimulationRun(t *testing.T) {
+	s := testSimulation()
+	var buf bytes.Buffer
+	n, err := s.Run(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.HasSuffix(output, "\r\n") {
+		t.Errorf("output %q does not end its lines with CRLF", output)
+	}
+	lines := strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n")
+	if n != len(lines) {
+		t.Errorf("Run reported %d reads, wrote %d", n, len(lines))
+	}
+	checkSimulatedReads(t, s, lines)
+
+	// Reads are sent in the order they are taken
+	if !sort.StringsAreSorted(timesOf(lines)) {
+		t.Errorf("reads are out of order:\n%s", output)
+	}
+
+	// The same seed gives the same race
+	var again bytes.Buffer
+	s.Rand = rand.New(rand.NewSource(1))
+	if _, err := s.Run(&again); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != output {
+		t.Error("the same seed gave a different race")
+	}
+}
+
+// timesOf gives the time of each line in the default format
+func timesOf(lines []string) []string {
+	times := make([]string, len(lines))
+	for i, line := range lines {
+		_, times[i], _ = strings.Cut(line, ",")
+	}
+	return times
+}
+
+func TestServe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := make(chan string, 100)
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ln, func(line string) { lines <- line })
+	}()
+
+	s := testSimulation()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := bufio.NewWriter(conn)
+	// Blank lines are skipped
+	w.WriteString("\r\n")
+	n, err := s.Run(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	var received []string
+	for len(received) < n {
+		select {
+		case line := <-lines:
+			received = append(received, line)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d of %d reads", len(received), n)
+		}
+	}
+	checkSimulatedReads(t, s, received)
+
+	ln.Close()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve returned %v after the listener closed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Serve did not return after the listener closed")
+	}
+}
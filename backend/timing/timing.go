@@ -0,0 +1,123 @@
+// Package timing reads finish-line chip reads from timing systems that
+// send one read per line over TCP, and simulates such a reader for testing.
+//
+// Readers differ in how they lay out a line, so the line format is a
+// regular expression with named "chip" and "time" groups and a time layout
+// for the time group. Times without a date are taken to be on the day the
+// read arrives.
+package timing
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Line format of most readers' plain text output, a chip and the time of
+// day it was read, such as "058003,09:18:22.431", followed by anything else
+// the reader sends, such as the antenna
+const (
+	DefaultPattern    = `^\s*(?P<chip>[0-9A-Za-z]+)[\s,;]+(?P<time>\d{1,2}:\d{2}:\d{2}(?:\.\d+)?)`
+	DefaultTimeLayout = "15:04:05"
+)
+
+// Read is a chip read at the finish line
+type Read struct {
+	Chip string
+	Time time.Time
+}
+
+// Protocol parses the lines a reader sends
+type Protocol struct {
+	pattern *regexp.Regexp
+	layout  string
+	chip    int
+	time    int
+}
+
+// NewProtocol makes a protocol from a pattern with "chip" and "time"
+// groups and the layout of the time, as for time.Parse. Fractional seconds
+// are read whether or not the layout has them.
+func NewProtocol(pattern, layout string) (Protocol, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Protocol{}, err
+	}
+	p := Protocol{pattern: re, layout: layout, chip: re.SubexpIndex("chip"), time: re.SubexpIndex("time")}
+	if p.chip < 0 || p.time < 0 {
+		return Protocol{}, errors.New("the pattern needs chip and time groups, as (?P<chip>...) and (?P<time>...)")
+	}
+	return p, nil
+}
+
+// Parse reads a line. A time of day is put on day's date, in day's
+// location.
+func (p Protocol) Parse(line string, day time.Time) (Read, error) {
+	m := p.pattern.FindStringSubmatch(line)
+	if m == nil {
+		return Read{}, fmt.Errorf("unrecognized read %q", line)
+	}
+	t, err := time.ParseInLocation(p.layout, m[p.time], day.Location())
+	if err != nil {
+		return Read{}, fmt.Errorf("invalid time in read %q", line)
+	}
+	if t.Year() == 0 {
+		t = time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), day.Location())
+	}
+	return Read{Chip: strings.ToUpper(m[p.chip]), Time: t}, nil
+}
+
+// Deduper drops the repeat reads a reader sends while a chip is in range
+// of its antenna. It is safe for use by several connections at once.
+type Deduper struct {
+	window time.Duration
+	mu     sync.Mutex
+	last   map[string]time.Time
+}
+
+// NewDeduper drops reads of a chip within window of its previous read
+func NewDeduper(window time.Duration) *Deduper {
+	return &Deduper{window: window, last: make(map[string]time.Time)}
+}
+
+// First reports whether r is the first read of its chip within the window
+func (d *Deduper) First(r Read) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	last, seen := d.last[r.Chip]
+	if !r.Time.After(last) {
+		return !seen // an earlier read arriving late keeps the later one's window
+	}
+	d.last[r.Chip] = r.Time
+	return !seen || r.Time.Sub(last) > d.window
+}
+
+// Serve accepts connections from readers on ln until it is closed, calling
+// handle with each line received. Connections are served concurrently, so
+// handle must be safe to call from several goroutines.
+func Serve(ln net.Listener, handle func(line string)) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); line != "" {
+					handle(line)
+				}
+			}
+		}()
+	}
+}
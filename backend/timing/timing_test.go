@@ -0,0 +1,117 @@
+package timing
+
+import (
+	"testing"
+	"time"
+)
+
+// raceDay is the day reads arrive, on the wall clock
+var raceDay = time.Date(2024, 10, 12, 0, 0, 0, 0, time.UTC)
+
+func TestParseDefaultPattern(t *testing.T) {
+	p, err := NewProtocol(DefaultPattern, DefaultTimeLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line string
+		want Read
+	}{
+		{"058003,09:18:22.431", Read{"058003", time.Date(2024, 10, 12, 9, 18, 22, 431000000, time.UTC)}},
+		{"058003,09:18:22", Read{"058003", time.Date(2024, 10, 12, 9, 18, 22, 0, time.UTC)}},
+		// Chips are upper cased, and anything after the time is ignored
+		{"  a1b2 ; 9:18:22.4 ant=2", Read{"A1B2", time.Date(2024, 10, 12, 9, 18, 22, 400000000, time.UTC)}},
+	}
+	for _, tt := range tests {
+		got, err := p.Parse(tt.line, raceDay)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.line, err)
+			continue
+		}
+		if got.Chip != tt.want.Chip || !got.Time.Equal(tt.want.Time) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{"", "058003", "058003,late", "058003,25:18:22"} {
+		if got, err := p.Parse(line, raceDay); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", line, got)
+		}
+	}
+}
+
+func TestParseCustomPattern(t *testing.T) {
+	// A reader that sends the date and time first, then the chip
+	p, err := NewProtocol(`^(?P<time>\S+ \S+)\s+TAG=(?P<chip>\w+)`, "2006-01-02 15:04:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.Parse("2024-10-11 23:59:58.5 TAG=ff01", raceDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A time with a date keeps it
+	want := Read{"FF01", time.Date(2024, 10, 11, 23, 59, 58, 500000000, time.UTC)}
+	if got.Chip != want.Chip || !got.Time.Equal(want.Time) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNewProtocolNeedsGroups(t *testing.T) {
+	for _, pattern := range []string{
+		`^(\w+),(\d+:\d+:\d+)`,
+		`^(?P<chip>\w+),(\d+:\d+:\d+)`,
+		`^(\w+),(?P<time>\d+:\d+:\d+)`,
+		`^(?P<chip>\w+`,
+	} {
+		if _, err := NewProtocol(pattern, DefaultTimeLayout); err == nil {
+			t.Errorf("NewProtocol(%q) succeeded, want an error", pattern)
+		}
+	}
+}
+
+func TestDeduperFirst(t *testing.T) {
+	gun := time.Date(2024, 10, 12, 9, 0, 0, 0, time.UTC)
+	d := NewDeduper(5 * time.Second)
+
+	reads := []struct {
+		chip  string
+		after time.Duration
+		first bool
+	}{
+		{"A", 0, true},
+		{"A", 2 * time.Second, false},
+		// Another chip has a window of its own
+		{"B", 3 * time.Second, true},
+		// The window runs from the chip's last read, at 2s
+		{"A", 6 * time.Second, false},
+		// An earlier read arriving late is a repeat
+		{"A", time.Second, false},
+		{"A", 12 * time.Second, true},
+		{"B", 8*time.Second + time.Millisecond, true},
+	}
+	for i, r := range reads {
+		if got := d.First(Read{Chip: r.chip, Time: gun.Add(r.after)}); got != r.first {
+			t.Errorf("read %d of %s at %s: First = %v, want %v", i+1, r.chip, r.after, got, r.first)
+		}
+	}
+}
+
+func TestDeduperLateReadBeforeFirst(t *testing.T) {
+	gun := time.Date(2024, 10, 12, 9, 0, 0, 0, time.UTC)
+	d := NewDeduper(5 * time.Second)
+
+	if !d.First(Read{Chip: "A", Time: gun.Add(10 * time.Second)}) {
+		t.Fatal("the first read is not first")
+	}
+	// An earlier read of the same crossing, sent late, does not count again
+	if d.First(Read{Chip: "A", Time: gun.Add(9 * time.Second)}) {
+		t.Error("a late earlier read is first")
+	}
+	// and the window still runs from the later read
+	if d.First(Read{Chip: "A", Time: gun.Add(14 * time.Second)}) {
+		t.Error("a read 4s after the later read is first")
+	}
+}
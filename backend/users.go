@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"jones-county-xc/backend/db"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength is the shortest password accepted for an account
+const minPasswordLength = 8
+
+// dummyPasswordHash is compared against when a username does not exist so
+// failed logins take the same time whether or not the account is real
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+type UserResponse struct {
+	ID        int32  `json:"id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	Disabled  bool   `json:"disabled"`
+	CreatedAt string `json:"createdAt"`
+}
+
+func newUserResponse(u db.User) UserResponse {
+	var createdAt string
+	if u.CreatedAt.Valid {
+		createdAt = u.CreatedAt.Time.Format(time.RFC3339)
+	}
+	return UserResponse{
+		ID:        u.ID,
+		Username:  u.Username,
+		Role:      u.Role,
+		Disabled:  u.Disabled,
+		CreatedAt: createdAt,
+	}
+}
+
+// hashPassword returns the bcrypt hash stored for a password
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// authenticateUser checks a username and password against the users table.
+// bcrypt compares in constant time, and unknown usernames are checked against
+// a dummy hash so they cannot be distinguished by response time.
+func authenticateUser(username, password string) (db.User, bool) {
+	user, err := queries.GetUserByUsername(context.Background(), username)
+	if err != nil {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return db.User{}, false
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return db.User{}, false
+	}
+	if user.Disabled {
+		return db.User{}, false
+	}
+	return user, true
+}
+
+// bootstrapAdminUser creates the first admin account from environment
+// credentials when the users table is empty. It does nothing once any user exists,
+// and fails while none does and the password is too short, since no one could
+// sign in to make an account.
+func bootstrapAdminUser(username, password string) error {
+	count, err := queries.CountUsers(context.Background())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("no users exist; set ADMIN_USERNAME and ADMIN_PASSWORD (at least %d characters) to create the first account", minPasswordLength)
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	_, err = queries.CreateUser(context.Background(), db.CreateUserParams{
+		Username:     username,
+		PasswordHash: hash,
+		Role:         RoleAdmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Created initial user %q", username)
+	return nil
+}
+
+// registerUserRoutes adds account management routes to the admin-only group
+func registerUserRoutes(admin *gin.RouterGroup) {
+	// Get all users
+	admin.GET("/users", func(c *gin.Context) {
+		users, err := queries.GetAllUsers(context.Background())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]UserResponse, len(users))
+		for i, u := range users {
+			response[i] = newUserResponse(u)
+		}
+		c.JSON(200, response)
+	})
+
+	// Create a new user
+	admin.POST("/users", func(c *gin.Context) {
+		var req struct {
+			Username string `json:"username" binding:"required"`
+			Password string `json:"password" binding:"required"`
+			Role     string `json:"role" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !validRole(req.Role) {
+			c.JSON(400, gin.H{"error": "Invalid role"})
+			return
+		}
+
+		if len(req.Password) < minPasswordLength {
+			c.JSON(400, gin.H{"error": "Password must be at least " + strconv.Itoa(minPasswordLength) + " characters"})
+			return
+		}
+
+		if _, err := queries.GetUserByUsername(context.Background(), req.Username); err == nil {
+			c.JSON(409, gin.H{"error": "Username already exists"})
+			return
+		}
+
+		hash, err := hashPassword(req.Password)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to hash password"})
+			return
+		}
+
+		result, err := queries.CreateUser(context.Background(), db.CreateUserParams{
+			Username:     req.Username,
+			PasswordHash: hash,
+			Role:         req.Role,
+		})
+		if isDuplicateKey(err) {
+			c.JSON(409, gin.H{"error": "Username already exists"})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		id, _ := result.LastInsertId()
+		c.JSON(201, gin.H{"id": id, "message": "User created"})
+	})
+
+	// Change a user's role
+	admin.PUT("/users/:id/role", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		var req struct {
+			Role string `json:"role" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !validRole(req.Role) {
+			c.JSON(400, gin.H{"error": "Invalid role"})
+			return
+		}
+
+		if int32(id) == currentSession(c).UserID && req.Role != RoleAdmin {
+			c.JSON(400, gin.H{"error": "You cannot remove your own admin role"})
+			return
+		}
+
+		if _, err := queries.GetUserByID(context.Background(), int32(id)); err != nil {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		err = queries.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{
+			Role: req.Role,
+			ID:   int32(id),
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Role updated"})
+	})
+
+	// Disable a user and log out all of their sessions
+	admin.POST("/users/:id/disable", func(c *gin.Context) {
+		setUserDisabled(c, true)
+	})
+
+	// Re-enable a disabled user
+	admin.POST("/users/:id/enable", func(c *gin.Context) {
+		setUserDisabled(c, false)
+	})
+
+	// Reset a user's password and log out all of their sessions
+	admin.POST("/users/:id/reset-password", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		var req struct {
+			Password string `json:"password" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if len(req.Password) < minPasswordLength {
+			c.JSON(400, gin.H{"error": "Password must be at least " + strconv.Itoa(minPasswordLength) + " characters"})
+			return
+		}
+
+		if _, err := queries.GetUserByID(context.Background(), int32(id)); err != nil {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		hash, err := hashPassword(req.Password)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to hash password"})
+			return
+		}
+
+		err = queries.UpdateUserPassword(context.Background(), db.UpdateUserPasswordParams{
+			PasswordHash: hash,
+			ID:           int32(id),
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := queries.DeleteSessionsForUser(context.Background(), int32(id)); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Password reset"})
+	})
+}
+
+// setUserDisabled handles the disable and enable routes
+func setUserDisabled(c *gin.Context, disabled bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if disabled && int32(id) == currentSession(c).UserID {
+		c.JSON(400, gin.H{"error": "You cannot disable your own account"})
+		return
+	}
+
+	if _, err := queries.GetUserByID(context.Background(), int32(id)); err != nil {
+		c.JSON(404, gin.H{"error": "User not found"})
+		return
+	}
+
+	err = queries.SetUserDisabled(context.Background(), db.SetUserDisabledParams{
+		Disabled: disabled,
+		ID:       int32(id),
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if disabled {
+		if err := queries.DeleteSessionsForUser(context.Background(), int32(id)); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"message": "User disabled"})
+		return
+	}
+	c.JSON(200, gin.H{"message": "User enabled"})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"database/sql/driver"
+	"slices"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestCreateUserDuplicateUsername(t *testing.T) {
+	fake := useFakeDB(t)
+	r := newRouter()
+	token := fake.login(RoleAdmin)
+
+	// another request created the user between the check and the insert
+	fake.execErrs["CreateUser"] = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'coach' for key 'unique_username'"}
+
+	w := serveJSON(r, "POST", "/api/users", token, `{"username":"coach","password":"long enough","role":"coach"}`)
+	if w.Code != 409 {
+		t.Errorf("got %d, want 409: %s", w.Code, w.Body)
+	}
+}
+
+func TestBootstrapAdminUser(t *testing.T) {
+	fake := useFakeDB(t)
+	users := int64(0)
+	fake.rows["CountUsers"] = func([]driver.NamedValue) [][]driver.Value {
+		return [][]driver.Value{{users}}
+	}
+
+	// With no one to sign in, the server does not start without a password
+	for _, password := range []string{"", "short"} {
+		if err := bootstrapAdminUser("admin", password); err == nil {
+			t.Errorf("started with no users and password %q", password)
+		}
+	}
+	if len(fake.execs) > 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+
+	if err := bootstrapAdminUser("admin", "long enough"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(fake.execs, []string{"CreateUser"}) {
+		t.Errorf("ran %v, want the admin created", fake.execs)
+	}
+
+	// Once there are users the password is not needed
+	fake.execs = nil
+	users = 1
+	if err := bootstrapAdminUser("admin", ""); err != nil {
+		t.Errorf("with users got %v", err)
+	}
+	if len(fake.execs) > 0 {
+		t.Errorf("ran %v", fake.execs)
+	}
+}
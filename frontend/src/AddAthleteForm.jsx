@@ -5,7 +5,6 @@ import { Button } from '@/components/ui/button'
 function AddAthleteForm() {
   const [name, setName] = useState('')
   const [grade, setGrade] = useState('')
-  const [personalRecord, setPersonalRecord] = useState('')
   const [errors, setErrors] = useState({})
 
   const queryClient = useQueryClient()
@@ -26,7 +25,6 @@ function AddAthleteForm() {
       // Reset form
       setName('')
       setGrade('')
-      setPersonalRecord('')
       setErrors({})
     },
   })
@@ -39,11 +37,6 @@ function AddAthleteForm() {
     } else if (isNaN(grade) || grade < 9 || grade > 12) {
       newErrors.grade = 'Grade must be 9-12'
     }
-    if (!personalRecord.trim()) {
-      newErrors.personalRecord = 'Personal record is required'
-    } else if (!/^\d{1,2}:\d{2}$/.test(personalRecord)) {
-      newErrors.personalRecord = 'Format: MM:SS (e.g., 18:45)'
-    }
     setErrors(newErrors)
     return Object.keys(newErrors).length === 0
   }
@@ -54,7 +47,6 @@ function AddAthleteForm() {
       mutation.mutate({
         name: name.trim(),
         grade: parseInt(grade),
-        personalRecord: personalRecord.trim(),
       })
     }
   }
@@ -124,28 +116,6 @@ function AddAthleteForm() {
               <p id="grade-error" className="mt-1 text-sm text-red-600">{errors.grade}</p>
             )}
           </div>
-
-          <div>
-            <label htmlFor="athlete-pr" className="block text-sm font-semibold text-gray-700 mb-1">
-              Personal Record (5K)
-            </label>
-            <input
-              type="text"
-              id="athlete-pr"
-              value={personalRecord}
-              onChange={(e) => setPersonalRecord(e.target.value)}
-              placeholder="18:45"
-              className={`w-full px-3 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-green-600 ${
-                errors.personalRecord ? 'border-red-500' : 'border-gray-300'
-              }`}
-              aria-describedby={errors.personalRecord ? 'pr-error' : 'pr-hint'}
-              aria-invalid={errors.personalRecord ? 'true' : 'false'}
-            />
-            <p id="pr-hint" className="mt-1 text-xs text-gray-500">Format: MM:SS</p>
-            {errors.personalRecord && (
-              <p id="pr-error" className="mt-1 text-sm text-red-600">{errors.personalRecord}</p>
-            )}
-          </div>
         </div>
 
         <Button
@@ -4,17 +4,21 @@
  */
 
 const API_BASE = '/api'
+const TOKEN_KEY = 'jcxc_auth_token'
 
 /**
- * Generic fetch wrapper with error handling
+ * Generic fetch wrapper with error handling.
+ * Sends the stored login token so admin routes are authorized.
  */
 async function fetchAPI(endpoint, options = {}) {
+  const token = localStorage.getItem(TOKEN_KEY)
   const response = await fetch(`${API_BASE}${endpoint}`, {
+    ...options,
     headers: {
       'Content-Type': 'application/json',
+      ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
       ...options.headers,
     },
-    ...options,
   })
 
   if (!response.ok) {
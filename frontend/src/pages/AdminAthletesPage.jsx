@@ -61,7 +61,7 @@ function AthleteForm({ athlete, onSubmit, onCancel, isSubmitting }) {
   const [formData, setFormData] = useState({
     name: athlete?.name || '',
     grade: athlete?.grade?.toString() || '9',
-    personalRecord: athlete?.personalRecord || '',
+    gender: athlete?.gender || '',
     events: athlete?.events || '',
   })
   const [errors, setErrors] = useState({})
@@ -126,17 +126,20 @@ function AthleteForm({ athlete, onSubmit, onCancel, isSubmitting }) {
       </div>
 
       <div className="space-y-2">
-        <Label htmlFor="personalRecord">Personal Record</Label>
-        <Input
-          id="personalRecord"
-          value={formData.personalRecord}
-          onChange={(e) => setFormData({ ...formData, personalRecord: e.target.value })}
-          placeholder="e.g., 16:42"
+        <Label htmlFor="gender">Team</Label>
+        <Select
+          value={formData.gender}
+          onValueChange={(value) => setFormData({ ...formData, gender: value })}
           disabled={isSubmitting}
-        />
-        <p className="text-sm text-muted-foreground">
-          Best time for 5K race
-        </p>
+        >
+          <SelectTrigger id="gender">
+            <SelectValue placeholder="Select team" />
+          </SelectTrigger>
+          <SelectContent>
+            <SelectItem value="boys">Boys</SelectItem>
+            <SelectItem value="girls">Girls</SelectItem>
+          </SelectContent>
+        </Select>
       </div>
 
       <div className="space-y-2">